@@ -0,0 +1,476 @@
+package addons
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/operations/modrinth"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+)
+
+var Folders = []string{"plugins", "mods"}
+
+// Addon is a plugin or mod jar of a server
+type Addon struct {
+	File        string    `json:"file"`
+	Size        int64     `json:"size"`
+	Metadata    *Metadata `json:"metadata,omitempty"`
+	Source      *Source   `json:"source,omitempty"`
+	CanRollback bool      `json:"canRollback"`
+} //@name Addon
+
+// AvailableUpdate is a newer version of an addon that its source has
+type AvailableUpdate struct {
+	File           string `json:"file"`
+	Name           string `json:"name"`
+	Source         string `json:"source"`
+	CurrentVersion string `json:"currentVersion"`
+	LatestVersion  string `json:"latestVersion"`
+} //@name AddonUpdate
+
+// List gets the jars in the plugins and mods folders, with what they say about themselves and where they came from
+func List(fs files.FileServer, serverId string) ([]Addon, error) {
+	sources, err := GetSources(serverId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Addon, 0)
+	for _, folder := range Folders {
+		entries, err := fs.ReadDir(folder)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".jar") {
+				continue
+			}
+			file := path.Join(folder, entry.Name())
+			addon, err := readAddon(fs, file)
+			if err != nil {
+				continue
+			}
+			if source, ok := sources[addon.File]; ok {
+				addon.Source = &source
+				addon.CanRollback = source.Rollback != nil
+			}
+			result = append(result, addon)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].File < result[j].File
+	})
+	return result, nil
+}
+
+// CheckUpdates looks for newer versions of every addon that has a known source
+// Addons without one are looked up on Modrinth by their hash first, and remembered if found
+func CheckUpdates(fs files.FileServer, serverId, loader, gameVersion string) ([]AvailableUpdate, error) {
+	installed, err := List(fs, serverId)
+	if err != nil {
+		return nil, err
+	}
+	if err = identify(fs, serverId, installed); err != nil {
+		logging.Error.Printf("Error looking up addons on Modrinth: %s", err)
+	}
+
+	result := make([]AvailableUpdate, 0)
+	for _, addon := range installed {
+		if addon.Source == nil {
+			continue
+		}
+
+		latest, err := latestRelease(*addon.Source, loaderFor(addon, loader), gameVersion)
+		if err != nil {
+			logging.Debug.Printf("Error checking updates for %s: %s", addon.File, err)
+			continue
+		}
+		if upToDate(fs, addon, latest) {
+			continue
+		}
+
+		result = append(result, AvailableUpdate{
+			File:           addon.File,
+			Name:           addon.name(),
+			Source:         addon.Source.Type,
+			CurrentVersion: addon.version(),
+			LatestVersion:  latest.Source.Version,
+		})
+	}
+	return result, nil
+}
+
+// Update replaces an addon with the newest version from its source, it returns false if it was already the newest
+// The new jar is moved into place in one step, and the old one is kept so the update can be rolled back
+func Update(fs files.FileServer, serverId, file, loader, gameVersion string) (Addon, bool, error) {
+	addon, err := getAddon(fs, serverId, file)
+	if err != nil {
+		return Addon{}, false, err
+	}
+	if addon.Source == nil {
+		if err = identify(fs, serverId, []Addon{addon}); err != nil {
+			return Addon{}, false, err
+		}
+		if addon, err = getAddon(fs, serverId, file); err != nil {
+			return Addon{}, false, err
+		}
+		if addon.Source == nil {
+			return Addon{}, false, SkyPanel.ErrAddonNotTracked(file)
+		}
+	}
+
+	latest, err := latestRelease(*addon.Source, loaderFor(addon, loader), gameVersion)
+	if err != nil {
+		return Addon{}, false, err
+	}
+	if upToDate(fs, addon, latest) {
+		return addon, false, nil
+	}
+
+	//the name comes from the source, so it has to pass the same checks as the jar it replaces
+	target := path.Join(path.Dir(file), path.Base(latest.FileName))
+	if err = checkFile(target); err != nil {
+		return Addon{}, false, err
+	}
+
+	downloaded, err := download(latest)
+	if err != nil {
+		return Addon{}, false, err
+	}
+	defer os.Remove(downloaded)
+
+	previous := *addon.Source
+	previous.Rollback = nil
+
+	sourcesLocker.Lock()
+	defer sourcesLocker.Unlock()
+
+	sources, err := readSources(serverId)
+	if err != nil {
+		return Addon{}, false, err
+	}
+	prune(fs, serverId, sources)
+
+	//keep the jar being replaced, only the last one is kept
+	if old := sources[file].Rollback; old != nil {
+		_ = os.Remove(rollbackPath(serverId, old.File))
+	}
+	if err = copyFromServer(fs, file, rollbackPath(serverId, file)); err != nil {
+		return Addon{}, false, err
+	}
+
+	if err = swap(fs, downloaded, file, target); err != nil {
+		return Addon{}, false, err
+	}
+
+	source := latest.Source
+	source.UpdatedAt = time.Now()
+	source.Rollback = &Replaced{File: file, Source: previous}
+	delete(sources, file)
+	sources[target] = source
+	if err = writeSources(serverId, sources); err != nil {
+		return Addon{}, false, err
+	}
+
+	updated, err := readAddon(fs, target)
+	if err != nil {
+		return Addon{}, false, err
+	}
+	updated.Source = &source
+	updated.CanRollback = true
+	return updated, true, nil
+}
+
+// Rollback puts back the jar that the last update of an addon replaced
+func Rollback(fs files.FileServer, serverId, file string) (Addon, error) {
+	if err := checkFile(file); err != nil {
+		return Addon{}, err
+	}
+
+	sourcesLocker.Lock()
+	defer sourcesLocker.Unlock()
+
+	sources, err := readSources(serverId)
+	if err != nil {
+		return Addon{}, err
+	}
+	prune(fs, serverId, sources)
+	source, ok := sources[file]
+	if !ok || source.Rollback == nil {
+		return Addon{}, SkyPanel.ErrAddonNoRollback(file)
+	}
+
+	backup := rollbackPath(serverId, source.Rollback.File)
+	if _, err = os.Stat(backup); err != nil {
+		return Addon{}, SkyPanel.ErrAddonNoRollback(file)
+	}
+	if err = swap(fs, backup, file, source.Rollback.File); err != nil {
+		return Addon{}, err
+	}
+	_ = os.Remove(backup)
+
+	previous := source.Rollback.Source
+	delete(sources, file)
+	sources[source.Rollback.File] = previous
+	if err = writeSources(serverId, sources); err != nil {
+		return Addon{}, err
+	}
+
+	restored, err := readAddon(fs, source.Rollback.File)
+	if err != nil {
+		return Addon{}, err
+	}
+	restored.Source = &previous
+	return restored, nil
+}
+
+// Track records where an addon came from, for jars that were uploaded by hand
+func Track(fs files.FileServer, serverId, file string, source Source) (Addon, error) {
+	addon, err := getAddon(fs, serverId, file)
+	if err != nil {
+		return Addon{}, err
+	}
+
+	sourcesLocker.Lock()
+	defer sourcesLocker.Unlock()
+
+	sources, err := readSources(serverId)
+	if err != nil {
+		return Addon{}, err
+	}
+	prune(fs, serverId, sources)
+
+	source.UpdatedAt = time.Now()
+	if old, ok := sources[file]; ok {
+		source.Rollback = old.Rollback
+	}
+	sources[file] = source
+	if err = writeSources(serverId, sources); err != nil {
+		return Addon{}, err
+	}
+	addon.Source = &source
+	addon.CanRollback = source.Rollback != nil
+	return addon, nil
+}
+
+func getAddon(fs files.FileServer, serverId, file string) (Addon, error) {
+	if err := checkFile(file); err != nil {
+		return Addon{}, err
+	}
+	addon, err := readAddon(fs, file)
+	if os.IsNotExist(err) {
+		return Addon{}, SkyPanel.ErrFileNotFound
+	}
+	if err != nil {
+		return Addon{}, err
+	}
+
+	sources, err := GetSources(serverId)
+	if err != nil {
+		return Addon{}, err
+	}
+	if source, ok := sources[file]; ok {
+		addon.Source = &source
+		addon.CanRollback = source.Rollback != nil
+	}
+	return addon, nil
+}
+
+func readAddon(fs files.FileServer, file string) (Addon, error) {
+	f, err := fs.OpenFile(file, os.O_RDONLY, 0)
+	if err != nil {
+		return Addon{}, err
+	}
+	defer utils.Close(f)
+
+	info, err := f.Stat()
+	if err != nil {
+		return Addon{}, err
+	}
+
+	addon := Addon{File: file, Size: info.Size()}
+	metadata, ok, err := ReadMetadata(f, info.Size())
+	if err != nil {
+		logging.Debug.Printf("Error reading metadata of %s: %s", file, err)
+	} else if ok {
+		addon.Metadata = &metadata
+	}
+	return addon, nil
+}
+
+// identify finds on Modrinth the addons that have no source yet, by their hashes
+func identify(fs files.FileServer, serverId string, addons []Addon) error {
+	byHash := make(map[string]string)
+	for _, v := range addons {
+		if v.Source != nil {
+			continue
+		}
+		hash, err := hashFile(fs, v.File)
+		if err != nil {
+			return err
+		}
+		byHash[hash] = v.File
+	}
+	if len(byHash) == 0 {
+		return nil
+	}
+
+	hashes := make([]string, 0, len(byHash))
+	for k := range byHash {
+		hashes = append(hashes, k)
+	}
+	versions, err := modrinth.GetVersionsByHash(hashes)
+	if err != nil {
+		return err
+	}
+
+	for hash, version := range versions {
+		file, ok := byHash[hash]
+		if !ok {
+			continue
+		}
+		source := Source{Type: SourceModrinth, ProjectId: version.ProjectId, VersionId: version.Id, Version: version.VersionNumber}
+		if err = SetSource(serverId, file, source); err != nil {
+			return err
+		}
+		for i := range addons {
+			if addons[i].File == file {
+				addons[i].Source = &source
+			}
+		}
+	}
+	return nil
+}
+
+// upToDate tells if the installed jar already is the release, by version or, when the source gives one, by hash
+func upToDate(fs files.FileServer, addon Addon, latest release) bool {
+	if addon.Source.VersionId != "" && addon.Source.VersionId == latest.Source.VersionId {
+		return true
+	}
+	if latest.Sha1 != "" {
+		hash, err := hashFile(fs, addon.File)
+		return err == nil && hash == latest.Sha1
+	}
+	return false
+}
+
+// loaderFor is the loader to look for versions of, the one of the server if given, else the one the jar says
+func loaderFor(addon Addon, loader string) string {
+	if loader != "" {
+		return loader
+	}
+	if addon.Metadata != nil {
+		return addon.Metadata.Loader
+	}
+	return ""
+}
+
+// checkFile only allows jars directly in the plugins or mods folder
+func checkFile(file string) error {
+	cleaned := path.Clean(filepath.ToSlash(file))
+	if cleaned != file || !filepath.IsLocal(file) || !strings.HasSuffix(strings.ToLower(file), ".jar") {
+		return SkyPanel.ErrUnsafePath(file)
+	}
+	for _, v := range Folders {
+		if path.Dir(file) == v {
+			return nil
+		}
+	}
+	return SkyPanel.ErrUnsafePath(file)
+}
+
+// swap writes a new jar next to the old one and renames it into place, so the server never sees half a jar
+func swap(fs files.FileServer, source, file, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(in)
+
+	tmpFile := path.Join(path.Dir(target), "."+path.Base(target)+".part")
+	out, err := fs.OpenFile(tmpFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	utils.Close(out)
+	if err != nil {
+		_ = fs.Remove(tmpFile)
+		return err
+	}
+
+	if err = fs.Rename(tmpFile, target); err != nil {
+		_ = fs.Remove(tmpFile)
+		return err
+	}
+	if target != file {
+		return fs.Remove(file)
+	}
+	return nil
+}
+
+func copyFromServer(fs files.FileServer, file, target string) error {
+	in, err := fs.OpenFile(file, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(in)
+
+	if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(out)
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func hashFile(fs files.FileServer, file string) (string, error) {
+	f, err := fs.OpenFile(file, os.O_RDONLY, 0)
+	if err != nil {
+		return "", err
+	}
+	defer utils.Close(f)
+
+	h := sha1.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (a Addon) name() string {
+	if a.Metadata != nil && a.Metadata.Name != "" {
+		return a.Metadata.Name
+	}
+	return strings.TrimSuffix(path.Base(a.File), ".jar")
+}
+
+func (a Addon) version() string {
+	if a.Source != nil && a.Source.Version != "" {
+		return a.Source.Version
+	}
+	if a.Metadata != nil {
+		return a.Metadata.Version
+	}
+	return ""
+}
@@ -0,0 +1,217 @@
+package addons
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/operations/modrinth"
+	"github.com/klauspost/compress/zip"
+	"github.com/stretchr/testify/assert"
+)
+
+func createJar(entries map[string]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range entries {
+		f, _ := w.Create(name)
+		_, _ = f.Write([]byte(content))
+	}
+	_ = w.Close()
+	return buf.Bytes()
+}
+
+func readJar(t *testing.T, data []byte) Metadata {
+	metadata, ok, err := ReadMetadata(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	return metadata
+}
+
+func TestReadMetadata(t *testing.T) {
+	t.Run("PluginYml", func(t *testing.T) {
+		metadata := readJar(t, createJar(map[string]string{
+			"plugin.yml": "name: LuckPerms\nversion: 5.4.102\nauthor: Luck\nauthors: [Turbo]\nmain: me.lucko.Plugin\n",
+		}))
+		assert.Equal(t, "LuckPerms", metadata.Name)
+		assert.Equal(t, "5.4.102", metadata.Version)
+		assert.Equal(t, []string{"Luck", "Turbo"}, metadata.Authors)
+		assert.Equal(t, "bukkit", metadata.Loader)
+	})
+
+	t.Run("PaperPluginWins", func(t *testing.T) {
+		metadata := readJar(t, createJar(map[string]string{
+			"plugin.yml":       "name: Example\nversion: 1.0\n",
+			"paper-plugin.yml": "name: Example\nversion: 1.0\n",
+		}))
+		assert.Equal(t, "paper", metadata.Loader)
+		assert.Equal(t, "1.0", metadata.Version)
+	})
+
+	t.Run("FabricModJson", func(t *testing.T) {
+		metadata := readJar(t, createJar(map[string]string{
+			"fabric.mod.json": `{"schemaVersion":1,"id":"sodium","version":"0.5.8","name":"Sodium","authors":["JellySquid",{"name":"IMS"}]}`,
+		}))
+		assert.Equal(t, "sodium", metadata.Id)
+		assert.Equal(t, "0.5.8", metadata.Version)
+		assert.Equal(t, []string{"JellySquid", "IMS"}, metadata.Authors)
+		assert.Equal(t, "fabric", metadata.Loader)
+	})
+
+	t.Run("ModsTomlWithJarVersion", func(t *testing.T) {
+		metadata := readJar(t, createJar(map[string]string{
+			"META-INF/mods.toml":   "modLoader=\"javafml\"\n[[mods]]\nmodId=\"jei\"\nversion=\"${file.jarVersion}\"\ndisplayName=\"Just Enough Items\"\nauthors=\"mezz\"\n",
+			"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\nImplementation-Version: 15.2.0.27\n",
+		}))
+		assert.Equal(t, "jei", metadata.Id)
+		assert.Equal(t, "Just Enough Items", metadata.Name)
+		assert.Equal(t, "15.2.0.27", metadata.Version)
+		assert.Equal(t, []string{"mezz"}, metadata.Authors)
+		assert.Equal(t, "forge", metadata.Loader)
+	})
+
+	t.Run("DescriptorTooLarge", func(t *testing.T) {
+		data := createJar(map[string]string{"plugin.yml": "name: Bomb\n" + strings.Repeat("#", maxDescriptorSize)})
+		_, _, err := ReadMetadata(bytes.NewReader(data), int64(len(data)))
+		assert.Equal(t, "ErrDescriptorTooLarge", SkyPanel.FromError(err).GetCode())
+	})
+
+	t.Run("NoDescriptor", func(t *testing.T) {
+		data := createJar(map[string]string{"a.class": ""})
+		_, ok, err := ReadMetadata(bytes.NewReader(data), int64(len(data)))
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestCheckFile(t *testing.T) {
+	assert.NoError(t, checkFile("plugins/a.jar"))
+	assert.NoError(t, checkFile("mods/a.jar"))
+	assert.Error(t, checkFile("a.jar"))
+	assert.Error(t, checkFile("mods/sub/a.jar"))
+	assert.Error(t, checkFile("mods/../a.jar"))
+	assert.Error(t, checkFile("mods/a.txt"))
+}
+
+func TestUpdateAndRollback(t *testing.T) {
+	_ = config.AddonsFolder.Set(t.TempDir(), false)
+	_ = config.CacheFolder.Set(t.TempDir(), false)
+
+	root := t.TempDir()
+	fs, err := files.NewFileServer(root, os.Getuid(), os.Getgid())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer fs.Close()
+
+	oldJar := createJar(map[string]string{"fabric.mod.json": `{"id":"example","version":"1.0"}`})
+	newJar := createJar(map[string]string{"fabric.mod.json": `{"id":"example","version":"1.1"}`})
+	sum := sha1.Sum(newJar)
+	fileName := "example-1.1.jar"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/file" {
+			_, _ = w.Write(newJar)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]modrinth.Version{{
+			Id:            "v2",
+			ProjectId:     "example",
+			VersionNumber: "1.1",
+			VersionType:   "release",
+			Files: []modrinth.VersionFile{{
+				Url:      "http://" + r.Host + "/file",
+				Filename: fileName,
+				Primary:  true,
+				Hashes:   modrinth.Hashes{Sha1: hex.EncodeToString(sum[:])},
+			}},
+		}})
+	}))
+	defer server.Close()
+	modrinth.ApiUrl = server.URL
+
+	_ = os.MkdirAll(filepath.Join(root, "mods"), 0755)
+	_ = os.WriteFile(filepath.Join(root, "mods", "example-1.0.jar"), oldJar, 0644)
+	_ = SetSource("test", "mods/example-1.0.jar", Source{Type: SourceModrinth, ProjectId: "example", VersionId: "v1", Version: "1.0"})
+
+	t.Run("RejectsFileName", func(t *testing.T) {
+		fileName = "example-1.2.sh"
+		defer func() { fileName = "example-1.1.jar" }()
+
+		_, _, err := Update(fs, "test", "mods/example-1.0.jar", "", "")
+		assert.Equal(t, "ErrUnsafePath", SkyPanel.FromError(err).GetCode())
+		assert.FileExists(t, filepath.Join(root, "mods", "example-1.0.jar"))
+		assert.NoFileExists(t, filepath.Join(root, "mods", "example-1.2.sh"))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		updated, changed, err := Update(fs, "test", "mods/example-1.0.jar", "", "")
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, changed)
+		assert.Equal(t, "mods/example-1.1.jar", updated.File)
+		assert.Equal(t, "1.1", updated.Metadata.Version)
+		assert.True(t, updated.CanRollback)
+		assert.NoFileExists(t, filepath.Join(root, "mods", "example-1.0.jar"))
+		assert.NoFileExists(t, filepath.Join(root, "mods", ".example-1.1.jar.part"))
+
+		_, changed, err = Update(fs, "test", "mods/example-1.1.jar", "", "")
+		assert.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		restored, err := Rollback(fs, "test", "mods/example-1.1.jar")
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "mods/example-1.0.jar", restored.File)
+		assert.Equal(t, "v1", restored.Source.VersionId)
+		assert.NoFileExists(t, filepath.Join(root, "mods", "example-1.1.jar"))
+
+		content, _ := os.ReadFile(filepath.Join(root, "mods", "example-1.0.jar"))
+		assert.Equal(t, oldJar, content)
+
+		_, err = Rollback(fs, "test", "mods/example-1.0.jar")
+		assert.Error(t, err)
+	})
+}
+
+func TestPrune(t *testing.T) {
+	_ = config.AddonsFolder.Set(t.TempDir(), false)
+
+	root := t.TempDir()
+	fs, err := files.NewFileServer(root, os.Getuid(), os.Getgid())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer fs.Close()
+
+	jar := createJar(map[string]string{"plugin.yml": "name: Example\nversion: 1.0\n"})
+	_ = os.MkdirAll(filepath.Join(root, "plugins"), 0755)
+	_ = os.WriteFile(filepath.Join(root, "plugins", "example.jar"), jar, 0644)
+	_ = SetSource("test", "plugins/gone.jar", Source{Type: SourceSpiget, ProjectId: "1"})
+
+	//listing never changes what is tracked
+	_, err = List(fs, "test")
+	assert.NoError(t, err)
+	sources, _ := GetSources("test")
+	assert.Contains(t, sources, "plugins/gone.jar")
+
+	_, err = Track(fs, "test", "plugins/example.jar", Source{Type: SourceSpiget, ProjectId: "2"})
+	assert.NoError(t, err)
+	sources, _ = GetSources("test")
+	assert.NotContains(t, sources, "plugins/gone.jar")
+	assert.Contains(t, sources, "plugins/example.jar")
+}
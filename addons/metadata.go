@@ -0,0 +1,213 @@
+package addons
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/klauspost/compress/zip"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+// Metadata is what a plugin or mod says about itself in its jar
+type Metadata struct {
+	Id          string   `json:"id,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Version     string   `json:"version,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Authors     []string `json:"authors,omitempty"`
+	Loader      string   `json:"loader,omitempty"`
+} //@name AddonMetadata
+
+// maxDescriptorSize is the largest descriptor read from a jar, real ones are a few KB
+const maxDescriptorSize = 1024 * 1024
+
+// descriptors in the order they are looked for, paper plugins usually ship a plugin.yml too
+var descriptors = []struct {
+	file   string
+	loader string
+	parse  func(data []byte) (Metadata, error)
+}{
+	{"paper-plugin.yml", "paper", parsePluginYml},
+	{"plugin.yml", "bukkit", parsePluginYml},
+	{"fabric.mod.json", "fabric", parseFabricModJson},
+	{"META-INF/neoforge.mods.toml", "neoforge", parseModsToml},
+	{"META-INF/mods.toml", "forge", parseModsToml},
+}
+
+// ReadMetadata reads the descriptor of a jar, it returns false if the jar has none
+func ReadMetadata(r io.ReaderAt, size int64) (Metadata, bool, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return Metadata{}, false, err
+	}
+
+	for _, d := range descriptors {
+		data, err := readEntry(archive, d.file)
+		if err != nil {
+			return Metadata{}, false, err
+		}
+		if data == nil {
+			continue
+		}
+
+		metadata, err := d.parse(data)
+		if err != nil {
+			return Metadata{}, false, err
+		}
+		metadata.Loader = d.loader
+
+		//forge fills this in from the manifest when building the jar
+		if strings.Contains(metadata.Version, "${file.jarVersion}") {
+			metadata.Version = manifestVersion(archive)
+		}
+		return metadata, true, nil
+	}
+	return Metadata{}, false, nil
+}
+
+func readEntry(archive *zip.Reader, name string) ([]byte, error) {
+	for _, f := range archive.File {
+		if f.Name != name {
+			continue
+		}
+		if f.UncompressedSize64 > maxDescriptorSize {
+			return nil, SkyPanel.ErrDescriptorTooLarge(name)
+		}
+		reader, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		//the size in the header can not be trusted, so the read is limited too
+		data, err := io.ReadAll(io.LimitReader(reader, maxDescriptorSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxDescriptorSize {
+			return nil, SkyPanel.ErrDescriptorTooLarge(name)
+		}
+		return data, nil
+	}
+	return nil, nil
+}
+
+func parsePluginYml(data []byte) (Metadata, error) {
+	//strings keep versions like 1.0 as they are written, instead of the float 1
+	var descriptor struct {
+		Name        string      `yaml:"name"`
+		Version     string      `yaml:"version"`
+		Description string      `yaml:"description"`
+		Author      string      `yaml:"author"`
+		Authors     interface{} `yaml:"authors"`
+	}
+	if err := yaml.Unmarshal(data, &descriptor); err != nil {
+		return Metadata{}, err
+	}
+
+	metadata := Metadata{
+		Id:          strings.ToLower(descriptor.Name),
+		Name:        descriptor.Name,
+		Version:     descriptor.Version,
+		Description: descriptor.Description,
+	}
+	if descriptor.Author != "" {
+		metadata.Authors = append(metadata.Authors, descriptor.Author)
+	}
+	metadata.Authors = append(metadata.Authors, cast.ToStringSlice(descriptor.Authors)...)
+	return metadata, nil
+}
+
+func parseFabricModJson(data []byte) (Metadata, error) {
+	var descriptor struct {
+		Id          string        `json:"id"`
+		Name        string        `json:"name"`
+		Version     string        `json:"version"`
+		Description string        `json:"description"`
+		Authors     []interface{} `json:"authors"`
+	}
+	if err := json.Unmarshal(data, &descriptor); err != nil {
+		return Metadata{}, err
+	}
+
+	metadata := Metadata{
+		Id:          descriptor.Id,
+		Name:        descriptor.Name,
+		Version:     descriptor.Version,
+		Description: descriptor.Description,
+	}
+	//authors can be plain names or objects with a name and contact info
+	for _, v := range descriptor.Authors {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = m["name"]
+		}
+		if name := cast.ToString(v); name != "" {
+			metadata.Authors = append(metadata.Authors, name)
+		}
+	}
+	if metadata.Name == "" {
+		metadata.Name = metadata.Id
+	}
+	return metadata, nil
+}
+
+func parseModsToml(data []byte) (Metadata, error) {
+	var descriptor struct {
+		Authors string `toml:"authors"`
+		Mods    []struct {
+			ModId       string      `toml:"modId"`
+			Version     string      `toml:"version"`
+			DisplayName string      `toml:"displayName"`
+			Description string      `toml:"description"`
+			Authors     interface{} `toml:"authors"`
+		} `toml:"mods"`
+	}
+	if err := toml.Unmarshal(data, &descriptor); err != nil {
+		return Metadata{}, err
+	}
+	if len(descriptor.Mods) == 0 {
+		return Metadata{}, nil
+	}
+
+	//a jar can hold several mods, the first one is the main one
+	mod := descriptor.Mods[0]
+	metadata := Metadata{
+		Id:          mod.ModId,
+		Name:        mod.DisplayName,
+		Version:     mod.Version,
+		Description: strings.TrimSpace(mod.Description),
+	}
+	authors := cast.ToString(mod.Authors)
+	if list, ok := mod.Authors.([]interface{}); ok {
+		metadata.Authors = cast.ToStringSlice(list)
+	} else if authors != "" {
+		metadata.Authors = []string{authors}
+	} else if descriptor.Authors != "" {
+		metadata.Authors = []string{descriptor.Authors}
+	}
+	if metadata.Name == "" {
+		metadata.Name = metadata.Id
+	}
+	return metadata, nil
+}
+
+func manifestVersion(archive *zip.Reader) string {
+	data, err := readEntry(archive, "META-INF/MANIFEST.MF")
+	if err != nil || data == nil {
+		return ""
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if found && strings.TrimSpace(key) == "Implementation-Version" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
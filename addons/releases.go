@@ -0,0 +1,234 @@
+package addons
+
+import (
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/operations/curseforge"
+	"github.com/SkyPanel/SkyPanel/v3/operations/modrinth"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+)
+
+var SpigetUrl = "https://api.spiget.org/v2"
+
+// release is the newest version of a jar that a source has for the server
+type release struct {
+	Source   Source
+	FileName string
+	Url      string
+	Sha1     string
+	Sha512   string
+}
+
+func latestRelease(source Source, loader, gameVersion string) (release, error) {
+	switch source.Type {
+	case SourceSpiget:
+		return latestSpigetRelease(source.ProjectId)
+	case SourceModrinth:
+		return latestModrinthRelease(source.ProjectId, loader, gameVersion)
+	case SourceCurseForge:
+		return latestCurseForgeRelease(source.ProjectId, gameVersion)
+	default:
+		return release{}, fmt.Errorf("unknown source %s", source.Type)
+	}
+}
+
+// latestSpigetRelease gets the current version of a resource, Spigot only hosts one per resource
+func latestSpigetRelease(resourceId string) (release, error) {
+	var resource struct {
+		Name     string `json:"name"`
+		External bool   `json:"external"`
+	}
+	if err := callSpiget("/resources/"+url.PathEscape(resourceId), &resource); err != nil {
+		return release{}, err
+	}
+	if resource.External {
+		return release{}, SkyPanel.ErrSpigetExternal(resourceId)
+	}
+
+	var version struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := callSpiget("/resources/"+url.PathEscape(resourceId)+"/versions/latest", &version); err != nil {
+		return release{}, err
+	}
+
+	return release{
+		Source: Source{
+			Type:      SourceSpiget,
+			ProjectId: resourceId,
+			VersionId: strconv.Itoa(version.Id),
+			Version:   version.Name,
+		},
+		FileName: SpigetFileName(resource.Name, version.Name),
+		Url:      SpigetUrl + "/resources/" + url.PathEscape(resourceId) + "/download",
+	}, nil
+}
+
+func latestModrinthRelease(projectId, loader, gameVersion string) (release, error) {
+	version, err := modrinth.LatestVersion(projectId, loader, gameVersion)
+	if err != nil {
+		return release{}, err
+	}
+	file, ok := version.PrimaryFile()
+	if !ok {
+		return release{}, SkyPanel.ErrModrinthNoVersion(projectId)
+	}
+
+	return release{
+		Source: Source{
+			Type:      SourceModrinth,
+			ProjectId: version.ProjectId,
+			VersionId: version.Id,
+			Version:   version.VersionNumber,
+		},
+		FileName: file.Filename,
+		Url:      file.Url,
+		Sha1:     file.Hashes.Sha1,
+		Sha512:   file.Hashes.Sha512,
+	}, nil
+}
+
+// latestCurseForgeRelease gets the newest release file for the game version, or the newest beta or alpha if there are no releases
+func latestCurseForgeRelease(projectId, gameVersion string) (release, error) {
+	id, err := strconv.ParseUint(projectId, 10, 32)
+	if err != nil {
+		return release{}, err
+	}
+	projectFiles, err := curseforge.GetFiles(uint(id), gameVersion)
+	if err != nil {
+		return release{}, err
+	}
+
+	var latest *curseforge.File
+	for i, v := range projectFiles {
+		if !v.IsAvailable || v.IsServerPack {
+			continue
+		}
+		if latest == nil {
+			latest = &projectFiles[i]
+			continue
+		}
+		isRelease := v.ReleaseType == curseforge.ReleaseFileType
+		latestIsRelease := latest.ReleaseType == curseforge.ReleaseFileType
+		if (isRelease && !latestIsRelease) || (isRelease == latestIsRelease && v.FileDate.After(latest.FileDate)) {
+			latest = &projectFiles[i]
+		}
+	}
+	if latest == nil {
+		return release{}, SkyPanel.ErrCurseForgeFile(uint(id), 0)
+	}
+	if latest.DownloadUrl == "" {
+		return release{}, SkyPanel.ErrCurseForgeDistribution(uint(id))
+	}
+
+	return release{
+		Source: Source{
+			Type:      SourceCurseForge,
+			ProjectId: projectId,
+			VersionId: strconv.FormatUint(uint64(latest.Id), 10),
+			Version:   latest.DisplayName,
+		},
+		FileName: latest.FileName,
+		Url:      latest.DownloadUrl,
+		Sha1:     latest.Sha1(),
+	}, nil
+}
+
+// SpigetFileName is the name a Spigot resource is saved as in the plugins folder
+func SpigetFileName(name, version string) string {
+	replacer := strings.NewReplacer(" ", "_", "/", "_", "\\", "_")
+	return replacer.Replace(name) + "-" + replacer.Replace(version) + ".jar"
+}
+
+func callSpiget(path string, result interface{}) error {
+	request, err := http.NewRequest("GET", SpigetUrl+path, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("User-Agent", modrinth.UserAgent)
+
+	logging.Debug.Printf("Calling %s\n", request.URL.String())
+	response, err := SkyPanel.Http().Do(request)
+	defer utils.CloseResponse(response)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return SkyPanel.ErrSpigetStatus(response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// download saves a release into a temp file, checking the hashes the source gave for it
+// Spigot gives no hashes, so the file must at least be a valid jar
+func download(r release) (string, error) {
+	folder := filepath.Join(config.CacheFolder.Value(), "addons")
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return "", err
+	}
+	tmpFile, err := os.CreateTemp(folder, "tmp-*.jar")
+	if err != nil {
+		return "", err
+	}
+	defer utils.Close(tmpFile)
+
+	err = func() error {
+		request, err := http.NewRequest("GET", r.Url, nil)
+		if err != nil {
+			return err
+		}
+		request.Header.Add("User-Agent", modrinth.UserAgent)
+
+		logging.Info.Printf("Downloading: %s\n", r.Url)
+		response, err := SkyPanel.Http().Do(request)
+		defer utils.CloseResponse(response)
+		if err != nil {
+			return err
+		}
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("failed to download %s: %s", r.FileName, response.Status)
+		}
+
+		sha1Hash, sha512Hash := sha1.New(), sha512.New()
+		size, err := io.Copy(io.MultiWriter(tmpFile, sha1Hash, sha512Hash), response.Body)
+		if err != nil {
+			return err
+		}
+
+		if sum := hex.EncodeToString(sha512Hash.Sum(nil)); r.Sha512 != "" && r.Sha512 != sum {
+			return SkyPanel.ErrHashMismatch(r.FileName, r.Sha512, sum)
+		}
+		if sum := hex.EncodeToString(sha1Hash.Sum(nil)); r.Sha1 != "" && r.Sha1 != sum {
+			return SkyPanel.ErrHashMismatch(r.FileName, r.Sha1, sum)
+		}
+
+		if r.Sha1 == "" && r.Sha512 == "" {
+			if _, _, err = ReadMetadata(tmpFile, size); err != nil {
+				return SkyPanel.ErrInvalidAddon(r.FileName)
+			}
+		}
+		return nil
+	}()
+
+	if err != nil {
+		utils.Close(tmpFile)
+		_ = os.Remove(tmpFile.Name())
+		return "", err
+	}
+	return tmpFile.Name(), nil
+}
@@ -0,0 +1,146 @@
+package addons
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+)
+
+const (
+	SourceSpiget     = "spiget"
+	SourceModrinth   = "modrinth"
+	SourceCurseForge = "curseforge"
+)
+
+// Source is where a jar was downloaded from, so newer versions can be looked up
+type Source struct {
+	Type      string    `json:"type"`
+	ProjectId string    `json:"projectId"`
+	VersionId string    `json:"versionId,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Rollback  *Replaced `json:"rollback,omitempty"`
+} //@name AddonSource
+
+// Replaced is the jar that was replaced by the last update, kept outside the server folder
+type Replaced struct {
+	File   string `json:"file"`
+	Source Source `json:"source"`
+} //@name AddonRollback
+
+var sourcesLocker sync.Mutex
+
+// GetSources gets the sources of the jars of a server, by their path
+func GetSources(serverId string) (map[string]Source, error) {
+	sourcesLocker.Lock()
+	defer sourcesLocker.Unlock()
+	return readSources(serverId)
+}
+
+// SetSource records where a jar came from, it forgets any rollback kept for it
+func SetSource(serverId, file string, source Source) error {
+	sourcesLocker.Lock()
+	defer sourcesLocker.Unlock()
+
+	sources, err := readSources(serverId)
+	if err != nil {
+		return err
+	}
+	if source.UpdatedAt.IsZero() {
+		source.UpdatedAt = time.Now()
+	}
+	if old, ok := sources[file]; ok && old.Rollback != nil && source.Rollback == nil {
+		_ = os.Remove(rollbackPath(serverId, old.Rollback.File))
+	}
+	sources[file] = source
+	return writeSources(serverId, sources)
+}
+
+// RemoveSource forgets a jar, along with its rollback
+func RemoveSource(serverId, file string) error {
+	sourcesLocker.Lock()
+	defer sourcesLocker.Unlock()
+
+	sources, err := readSources(serverId)
+	if err != nil {
+		return err
+	}
+	old, ok := sources[file]
+	if !ok {
+		return nil
+	}
+	if old.Rollback != nil {
+		_ = os.Remove(rollbackPath(serverId, old.Rollback.File))
+	}
+	delete(sources, file)
+	return writeSources(serverId, sources)
+}
+
+// prune forgets jars which were deleted or renamed by hand, the caller has to hold sourcesLocker
+func prune(fs files.FileServer, serverId string, sources map[string]Source) {
+	for file, source := range sources {
+		if _, err := fs.Stat(file); !os.IsNotExist(err) {
+			continue
+		}
+		if source.Rollback != nil {
+			_ = os.Remove(rollbackPath(serverId, source.Rollback.File))
+		}
+		delete(sources, file)
+	}
+}
+
+// DeleteServer removes everything kept for a server
+func DeleteServer(serverId string) {
+	sourcesLocker.Lock()
+	defer sourcesLocker.Unlock()
+	_ = os.RemoveAll(filepath.Join(config.AddonsFolder.Value(), serverId))
+}
+
+func readSources(serverId string) (map[string]Source, error) {
+	sources := make(map[string]Source)
+	data, err := os.ReadFile(filepath.Join(config.AddonsFolder.Value(), serverId, "sources.json"))
+	if os.IsNotExist(err) {
+		return sources, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &sources)
+	return sources, err
+}
+
+func writeSources(serverId string, sources map[string]Source) error {
+	folder := filepath.Join(config.AddonsFolder.Value(), serverId)
+	if len(sources) == 0 {
+		err := os.Remove(filepath.Join(folder, "sources.json"))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(sources, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	//write to a temp file first so a crash never leaves half a file
+	tmpFile := filepath.Join(folder, "sources.json.tmp")
+	if err = os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, filepath.Join(folder, "sources.json"))
+}
+
+// rollbackPath is where the replaced jar is kept, mods and plugins get their own folder
+func rollbackPath(serverId, file string) string {
+	return filepath.Join(config.AddonsFolder.Value(), serverId, "rollback", filepath.FromSlash(file))
+}
@@ -0,0 +1,111 @@
+package alerts
+
+import (
+	"errors"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+// Severities a rule can have
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Variables are what a rule expression can use, every number is a double
+var Variables = map[string]*cel.Type{
+	"serverId":      cel.StringType,
+	"running":       cel.BoolType,
+	"installing":    cel.BoolType,
+	"cpu":           cel.DoubleType,
+	"memory":        cel.DoubleType,
+	"memoryLimit":   cel.DoubleType,
+	"memoryPercent": cel.DoubleType,
+	"heapUsed":      cel.DoubleType,
+	"heapTotal":     cel.DoubleType,
+	"heapPercent":   cel.DoubleType,
+	"diskUsed":      cel.DoubleType,
+	"diskQuota":     cel.DoubleType,
+	"diskPercent":   cel.DoubleType,
+	"rxRate":        cel.DoubleType,
+	"txRate":        cel.DoubleType,
+	"readRate":      cel.DoubleType,
+	"writeRate":     cel.DoubleType,
+	"threads":       cel.DoubleType,
+	"openFiles":     cel.DoubleType,
+	"crashes":       cel.DoubleType,
+	"duration":      cel.DurationType,
+}
+
+var ErrNotBoolean = errors.New("alert expression must return a boolean")
+
+// farFuture stands in for duration when checking whether the rest of an expression holds
+const farFuture = 100 * 365 * 24 * time.Hour
+
+// durationLiteral matches shorthands like 2m or 90s, which are turned into duration("2m") before compiling
+var durationLiteral = regexp.MustCompile(`\b(\d+(?:ms|s|m|h))\b`)
+
+var env *cel.Env
+var envOnce sync.Once
+var envErr error
+
+var programs = make(map[string]cel.Program)
+var programsLock sync.Mutex
+
+func getEnv() (*cel.Env, error) {
+	envOnce.Do(func() {
+		options := []cel.EnvOption{cel.CrossTypeNumericComparisons(true)}
+		for k, v := range Variables {
+			options = append(options, cel.Variable(k, v))
+		}
+		env, envErr = cel.NewEnv(options...)
+	})
+	return env, envErr
+}
+
+// Compile checks an expression and prepares it for evaluation, compiled expressions are kept for reuse
+func Compile(expression string) (cel.Program, error) {
+	programsLock.Lock()
+	defer programsLock.Unlock()
+
+	if prg, ok := programs[expression]; ok {
+		return prg, nil
+	}
+
+	e, err := getEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := e.Compile(durationLiteral.ReplaceAllString(expression, `duration("$1")`))
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, ErrNotBoolean
+	}
+
+	prg, err := e.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+	programs[expression] = prg
+	return prg, nil
+}
+
+func evaluate(prg cel.Program, vars map[string]interface{}, duration time.Duration) (bool, error) {
+	vars["duration"] = duration
+	out, _, err := prg.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, ErrNotBoolean
+	}
+	return result, nil
+}
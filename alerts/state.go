@@ -0,0 +1,117 @@
+package alerts
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is what happened to a rule after an evaluation
+type Event int
+
+const (
+	EventNone Event = iota
+	EventFiring
+	EventRecovered
+)
+
+// Rule is what the tracker needs to know about an alert rule
+type Rule struct {
+	Id         uint
+	Expression string
+	Cooldown   time.Duration
+}
+
+type ruleState struct {
+	pendingSince time.Time
+	firing       bool
+	lastSent     time.Time
+}
+
+type stateKey struct {
+	rule   uint
+	server string
+}
+
+// Tracker remembers, for every rule and server, since when the rule matches and when it last notified
+type Tracker struct {
+	locker sync.Mutex
+	states map[stateKey]*ruleState
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{states: make(map[stateKey]*ruleState)}
+}
+
+// Evaluate runs a rule against the variables of a server.
+// duration is how long the rest of the expression has held, so "cpu > 95 && duration > 2m" fires once the cpu stayed high for two minutes.
+// EventFiring is returned when the rule starts firing and again every cooldown while it keeps firing.
+func (t *Tracker) Evaluate(rule Rule, serverId string, vars map[string]interface{}, now time.Time) (Event, error) {
+	prg, err := Compile(rule.Expression)
+	if err != nil {
+		return EventNone, err
+	}
+
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	key := stateKey{rule: rule.Id, server: serverId}
+	state, exists := t.states[key]
+	if !exists {
+		state = &ruleState{}
+		t.states[key] = state
+	}
+
+	matching, err := evaluate(prg, vars, farFuture)
+	if err != nil {
+		return EventNone, err
+	}
+
+	var result bool
+	if matching {
+		if state.pendingSince.IsZero() {
+			state.pendingSince = now
+		}
+		result, err = evaluate(prg, vars, now.Sub(state.pendingSince))
+		if err != nil {
+			return EventNone, err
+		}
+	} else {
+		state.pendingSince = time.Time{}
+	}
+
+	switch {
+	case result && (!state.firing || now.Sub(state.lastSent) >= rule.Cooldown):
+		state.firing = true
+		state.lastSent = now
+		return EventFiring, nil
+	case !result && state.firing:
+		state.firing = false
+		return EventRecovered, nil
+	default:
+		return EventNone, nil
+	}
+}
+
+// Forget drops the state of rules which no longer exist
+func (t *Tracker) Forget(keep func(ruleId uint) bool) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	for k := range t.states {
+		if !keep(k.rule) {
+			delete(t.states, k)
+		}
+	}
+}
+
+// ForgetServer drops the state kept for a server
+func (t *Tracker) ForgetServer(serverId string) {
+	t.locker.Lock()
+	defer t.locker.Unlock()
+
+	for k := range t.states {
+		if k.server == serverId {
+			delete(t.states, k)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		expression string
+		wantErr    bool
+	}{
+		{expression: "cpu > 95 && duration > 2m"},
+		{expression: "running && memoryPercent >= 90.5"},
+		{expression: `serverId == "abc" || diskPercent > 80`},
+		{expression: "cpu", wantErr: true},
+		{expression: "unknown > 1", wantErr: true},
+		{expression: "cpu >", wantErr: true},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(tt.expression); (err != nil) != tt.wantErr {
+			t.Errorf("Compile(%q) error = %v, wantErr %v", tt.expression, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTrackerEvaluate(t *testing.T) {
+	tracker := NewTracker()
+	rule := Rule{Id: 1, Expression: "cpu > 95 && duration > 2m", Cooldown: 5 * time.Minute}
+	start := time.Now()
+
+	steps := []struct {
+		after time.Duration
+		cpu   float64
+		want  Event
+	}{
+		{after: 0, cpu: 99, want: EventNone},
+		{after: time.Minute, cpu: 99, want: EventNone},
+		{after: 3 * time.Minute, cpu: 99, want: EventFiring},
+		{after: 4 * time.Minute, cpu: 99, want: EventNone},
+		{after: 8 * time.Minute, cpu: 99, want: EventFiring},
+		{after: 9 * time.Minute, cpu: 10, want: EventRecovered},
+		{after: 10 * time.Minute, cpu: 99, want: EventNone},
+		{after: 11 * time.Minute, cpu: 10, want: EventNone},
+	}
+	for i, step := range steps {
+		vars := VariablesFor(Status{ServerId: "server", Running: true}, &SkyPanel.ServerStats{Cpu: step.cpu})
+
+		got, err := tracker.Evaluate(rule, "server", vars, start.Add(step.after))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != step.want {
+			t.Errorf("step %d: got event %d, want %d", i, got, step.want)
+		}
+	}
+}
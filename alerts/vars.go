@@ -0,0 +1,62 @@
+package alerts
+
+import (
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/google/cel-go/cel"
+)
+
+// Status is the state of a server which is not part of its stats
+type Status struct {
+	ServerId   string
+	Running    bool
+	Installing bool
+	Crashes    int64
+}
+
+// VariablesFor builds the variables a rule is evaluated with, stats may be nil when they could not be read
+func VariablesFor(status Status, stats *SkyPanel.ServerStats) map[string]interface{} {
+	vars := make(map[string]interface{}, len(Variables))
+	for k, v := range Variables {
+		if v == cel.DoubleType {
+			vars[k] = 0.0
+		}
+	}
+	vars["serverId"] = status.ServerId
+	vars["running"] = status.Running
+	vars["installing"] = status.Installing
+	vars["crashes"] = float64(status.Crashes)
+
+	if stats == nil {
+		return vars
+	}
+
+	vars["cpu"] = stats.Cpu
+	vars["memory"] = stats.Memory
+	if stats.Limits != nil && stats.Limits.MemoryMax > 0 {
+		vars["memoryLimit"] = float64(stats.Limits.MemoryMax)
+		vars["memoryPercent"] = stats.Memory / float64(stats.Limits.MemoryMax) * 100
+	}
+	if stats.Jvm != nil {
+		vars["heapUsed"] = float64(stats.Jvm.HeapUsed)
+		vars["heapTotal"] = float64(stats.Jvm.HeapTotal)
+		if stats.Jvm.HeapTotal > 0 {
+			vars["heapPercent"] = float64(stats.Jvm.HeapUsed) / float64(stats.Jvm.HeapTotal) * 100
+		}
+	}
+	if stats.Disk != nil {
+		vars["diskUsed"] = float64(stats.Disk.Used)
+		vars["diskQuota"] = float64(stats.Disk.Quota)
+		vars["diskPercent"] = stats.Disk.Percent
+	}
+	if stats.Network != nil {
+		vars["rxRate"] = stats.Network.RxRate
+		vars["txRate"] = stats.Network.TxRate
+	}
+	if stats.DiskIO != nil {
+		vars["readRate"] = stats.DiskIO.ReadRate
+		vars["writeRate"] = stats.DiskIO.WriteRate
+	}
+	vars["threads"] = float64(stats.Threads)
+	vars["openFiles"] = float64(stats.OpenFiles)
+	return vars
+}
@@ -71,4 +71,70 @@ export class SelfApi {
     await this._api.delete(`/api/self/oauth2/${clientId}`)
     return true
   }
+
+  async getNotifications(page = 1, limit = 20, unread = false) {
+    const res = await this._api.get('/api/self/notifications', { page, limit, unread })
+    return res.data
+  }
+
+  async getUnreadNotifications() {
+    const res = await this._api.get('/api/self/notifications/unread')
+    return res.data.unread
+  }
+
+  async markNotificationRead(id) {
+    await this._api.post(`/api/self/notifications/${id}/read`)
+    return true
+  }
+
+  async markAllNotificationsRead() {
+    await this._api.post('/api/self/notifications/read')
+    return true
+  }
+
+  async deleteNotification(id) {
+    await this._api.delete(`/api/self/notifications/${id}`)
+    return true
+  }
+
+  async getNotificationPreferences() {
+    const res = await this._api.get('/api/self/notifications/preferences')
+    return res.data
+  }
+
+  async updateNotificationPreferences(preferences) {
+    await this._api.put('/api/self/notifications/preferences', preferences)
+    return true
+  }
+
+  // calls onNotification for every new inbox notification, returns a function which closes the socket
+  openNotificationSocket(onNotification) {
+    let host = this._api._host
+    if (!host && typeof window !== 'undefined') {
+      host = window.location.host
+    }
+    if (!host) throw new Error('cannot determine host to connect to')
+    const protocol = host.indexOf('https://') === 0 ? 'wss' : 'ws'
+    if (host.indexOf('http://') === 0) host = host.substr(7)
+    if (host.indexOf('https://') === 0) host = host.substr(8)
+
+    let closed = false
+    let socket = null
+    const open = () => {
+      socket = new WebSocket(`${protocol}://${host}/api/self/notifications/socket`)
+      socket.addEventListener('message', e => {
+        const event = JSON.parse(e.data)
+        if (event.type === 'notification') onNotification(event.data)
+      })
+      socket.addEventListener('close', () => {
+        if (!closed) setTimeout(open, 5000)
+      })
+    }
+    open()
+
+    return () => {
+      closed = true
+      socket.close()
+    }
+  }
 }
@@ -45,9 +45,15 @@ export class ServerApi {
     const res = await this._api.get(`/api/servers/${id}/status`)
     if (res.data.installing) return 'installing'
     if (res.data.running) return 'online'
+    if (res.data.crashLooping) return 'crashlooping'
     return 'offline'
   }
 
+  async getInstallProgress(id) {
+    const res = await this._api.get(`/api/servers/${id}/status`)
+    return { installing: res.data.installing, progress: res.data.progress || null }
+  }
+
   async getStats(id) {
     const res = await this._api.get(`/api/servers/${id}/stats`)
     return res.data
@@ -88,11 +94,26 @@ export class ServerApi {
     return await this.action(id, 'install', wait)
   }
 
+  async cancelInstall(id) {
+    await this._api.post(`/api/servers/${id}/install/cancel`)
+    return true
+  }
+
+  async planInstall(id) {
+    const res = await this._api.post(`/api/servers/${id}/install?dryRun`)
+    return res.data
+  }
+
   async reload(id) {
     await this._api.post(`/api/servers/${id}/reload`)
     return true
   }
 
+  async resetCrashes(id) {
+    await this._api.post(`/api/servers/${id}/crashes/reset`)
+    return true
+  }
+
   async sendCommand(id, command) {
     await this._api.post(`/api/servers/${id}/console`, command)
     return true
@@ -118,6 +139,11 @@ export class ServerApi {
     return true
   }
 
+  async setMonitoring(id, enabled) {
+    await this._api.put(`/api/servers/${id}/monitoring`, { enabled })
+    return true
+  }
+
   async getDefinition(id) {
     const res = await this._api.get(`/api/servers/${id}/definition`)
     return res.data
@@ -236,6 +262,42 @@ export class ServerApi {
     return true
   }
 
+  getConfigUrl(id, path) {
+    if (path.indexOf('/') === 0) path = path.substring(1)
+    path = encodeURIComponent(path).replace(/%2F/g, '/')
+    return `/api/servers/${id}/config/${path}`
+  }
+
+  async getConfig(id, path, format) {
+    const res = await this._api.get(this.getConfigUrl(id, path), format ? { format } : {})
+    return res.data
+  }
+
+  async editConfig(id, path, edit, format) {
+    const res = await this._api.patch(this.getConfigUrl(id, path), edit, format ? { format } : {})
+    return res.data
+  }
+
+  async deploy(id, restart = false) {
+    const res = await this._api.post(`/api/servers/${id}/deploy`, undefined, restart ? { restart: true } : {})
+    return res.data
+  }
+
+  async getDeployKey(id) {
+    const res = await this._api.get(`/api/servers/${id}/deploy/key`)
+    return res.data.publicKey
+  }
+
+  async generateDeployKey(id) {
+    const res = await this._api.post(`/api/servers/${id}/deploy/key`)
+    return res.data.publicKey
+  }
+
+  async deleteDeployKey(id) {
+    await this._api.delete(`/api/servers/${id}/deploy/key`)
+    return true
+  }
+
   async archiveFile(id, destination, files) {
     if (destination.startsWith('/')) destination = destination.substring(1)
     if (!Array.isArray(files)) files = [files]
@@ -285,6 +347,61 @@ export class ServerApi {
     return true
   }
 
+  async getModrinthProjects(id) {
+    const res = await this._api.get(`/api/servers/${id}/modrinth`)
+    return res.data
+  }
+
+  async searchModrinth(id, query, type = 'mod', loader = '', gameVersion = '') {
+    const res = await this._api.get(`/api/servers/${id}/modrinth/search`, { q: query, type, loader, gameVersion })
+    return res.data
+  }
+
+  async installModrinthProject(id, projectId, loader = '', gameVersion = '', versionId = '') {
+    const res = await this._api.post(`/api/servers/${id}/modrinth/${encodeURIComponent(projectId)}`, undefined, { loader, gameVersion, versionId })
+    return res.data
+  }
+
+  async updateModrinthProject(id, projectId, loader = '', gameVersion = '') {
+    const res = await this._api.put(`/api/servers/${id}/modrinth/${encodeURIComponent(projectId)}`, undefined, { loader, gameVersion })
+    return res.data
+  }
+
+  async removeModrinthProject(id, projectId) {
+    await this._api.delete(`/api/servers/${id}/modrinth/${encodeURIComponent(projectId)}`)
+    return true
+  }
+
+  async getAddons(id) {
+    const res = await this._api.get(`/api/servers/${id}/addons`)
+    return res.data
+  }
+
+  async checkAddonUpdates(id, loader = '', gameVersion = '') {
+    const res = await this._api.get(`/api/servers/${id}/addons/updates`, { loader, gameVersion })
+    return res.data
+  }
+
+  async updateAddon(id, file, loader = '', gameVersion = '') {
+    const res = await this._api.post(`/api/servers/${id}/addons/update`, undefined, { file, loader, gameVersion })
+    return res.data
+  }
+
+  async rollbackAddon(id, file) {
+    const res = await this._api.post(`/api/servers/${id}/addons/rollback`, undefined, { file })
+    return res.data
+  }
+
+  async setAddonSource(id, file, type, projectId) {
+    const res = await this._api.put(`/api/servers/${id}/addons/source`, { type, projectId }, { file })
+    return res.data
+  }
+
+  async removeAddonSource(id, file) {
+    await this._api.delete(`/api/servers/${id}/addons/source`, { file })
+    return true
+  }
+
   async restoreBackup(id, backupId) {
     await this._api.post(`/api/servers/${id}/backup/restore/${backupId}`)
     return true
@@ -341,6 +458,7 @@ class Server {
     this.node = serverData.server.node
     this.port = serverData.server.port
     this.type = serverData.server.type
+    this.monitored = serverData.server.monitored || false
     this._scopes = serverData.permissions.scopes
     this._api = api
     this._openSocket()
@@ -447,6 +565,10 @@ class Server {
     return await this._api.server.getStatus(this.id)
   }
 
+  async getInstallProgress() {
+    return await this._api.server.getInstallProgress(this.id)
+  }
+
   async getStats() {
     return await this._api.server.getStats(this.id)
   }
@@ -479,10 +601,22 @@ class Server {
     return await this._api.server.install(this.id)
   }
 
+  async cancelInstall() {
+    return await this._api.server.cancelInstall(this.id)
+  }
+
+  async planInstall() {
+    return await this._api.server.planInstall(this.id)
+  }
+
   async reload() {
     return await this._api.server.reload(this.id)
   }
 
+  async resetCrashes() {
+    return await this._api.server.resetCrashes(this.id)
+  }
+
   async sendCommand(command) {
     return await this._api.server.sendCommand(this.id, command)
   }
@@ -505,6 +639,12 @@ class Server {
     return await this._api.server.setFlags(this.id, flags)
   }
 
+  async setMonitoring(enabled) {
+    const r = await this._api.server.setMonitoring(this.id, enabled)
+    this.monitored = enabled
+    return r
+  }
+
   async getDefinition() {
     return await this._api.server.getDefinition(this.id)
   }
@@ -561,6 +701,30 @@ class Server {
     return await this._api.server.createFolder(this.id, path)
   }
 
+  async getConfig(path, format) {
+    return await this._api.server.getConfig(this.id, path, format)
+  }
+
+  async editConfig(path, edit, format) {
+    return await this._api.server.editConfig(this.id, path, edit, format)
+  }
+
+  async deploy(restart) {
+    return await this._api.server.deploy(this.id, restart)
+  }
+
+  async getDeployKey() {
+    return await this._api.server.getDeployKey(this.id)
+  }
+
+  async generateDeployKey() {
+    return await this._api.server.generateDeployKey(this.id)
+  }
+
+  async deleteDeployKey() {
+    return await this._api.server.deleteDeployKey(this.id)
+  }
+
   async archiveFile(destination, files) {
     return await this._api.server.archiveFile(this.id, destination, files)
   }
@@ -605,6 +769,50 @@ class Server {
     return await this._api.server.deletePlugin(this.id, pluginName)
   }
 
+  async getModrinthProjects() {
+    return await this._api.server.getModrinthProjects(this.id)
+  }
+
+  async searchModrinth(query, type, loader, gameVersion) {
+    return await this._api.server.searchModrinth(this.id, query, type, loader, gameVersion)
+  }
+
+  async installModrinthProject(projectId, loader, gameVersion, versionId) {
+    return await this._api.server.installModrinthProject(this.id, projectId, loader, gameVersion, versionId)
+  }
+
+  async updateModrinthProject(projectId, loader, gameVersion) {
+    return await this._api.server.updateModrinthProject(this.id, projectId, loader, gameVersion)
+  }
+
+  async removeModrinthProject(projectId) {
+    return await this._api.server.removeModrinthProject(this.id, projectId)
+  }
+
+  async getAddons() {
+    return await this._api.server.getAddons(this.id)
+  }
+
+  async checkAddonUpdates(loader, gameVersion) {
+    return await this._api.server.checkAddonUpdates(this.id, loader, gameVersion)
+  }
+
+  async updateAddon(file, loader, gameVersion) {
+    return await this._api.server.updateAddon(this.id, file, loader, gameVersion)
+  }
+
+  async rollbackAddon(file) {
+    return await this._api.server.rollbackAddon(this.id, file)
+  }
+
+  async setAddonSource(file, type, projectId) {
+    return await this._api.server.setAddonSource(this.id, file, type, projectId)
+  }
+
+  async removeAddonSource(file) {
+    return await this._api.server.removeAddonSource(this.id, file)
+  }
+
   async deleteFile(path) {
     return await this._api.server.deleteFile(this.id, path)
   }
@@ -14,5 +14,39 @@ export class UptimeApi {
     const res = await this._api.get(`/api/uptime/${serverId}`, { days, limit })
     return res.data
   }
-}
 
+  async getReport(period = 'month', date = undefined, nodeId = undefined) {
+    const res = await this._api.get('/api/uptime/report', { period, date, nodeId })
+    return res.data
+  }
+
+  async getServerReport(serverId, period = 'month', date = undefined) {
+    const res = await this._api.get(`/api/uptime/${serverId}/report`, { period, date })
+    return res.data
+  }
+
+  async getReportCsv(period = 'month', date = undefined, nodeId = undefined) {
+    const res = await this._api.get('/api/uptime/report', { period, date, nodeId, format: 'csv' })
+    return res.data
+  }
+
+  async getMaintenanceWindows(from = undefined, to = undefined) {
+    const res = await this._api.get('/api/maintenance', { from, to })
+    return res.data
+  }
+
+  async createMaintenanceWindow(window) {
+    const res = await this._api.post('/api/maintenance', window)
+    return res.data
+  }
+
+  async updateMaintenanceWindow(id, window) {
+    const res = await this._api.put(`/api/maintenance/${id}`, window)
+    return res.data
+  }
+
+  async deleteMaintenanceWindow(id) {
+    await this._api.delete(`/api/maintenance/${id}`)
+    return true
+  }
+}
@@ -40,7 +40,7 @@ export default async () => {
 }
 
 const rtl = ['ar_SA', 'he_IL']
-const files = ['common', 'env', 'errors', 'files', 'hotkeys', 'nodes', 'oauth', 'operators', 'scopes', 'servers', 'settings', 'templates', 'users', 'backup', 'plugins', 'uptime', 'admin', 'roles']
+const files = ['common', 'env', 'errors', 'files', 'hotkeys', 'nodes', 'oauth', 'operators', 'scopes', 'servers', 'settings', 'templates', 'users', 'backup', 'plugins', 'uptime', 'admin', 'roles', 'notifications', 'mods', 'addons']
 export async function updateLocale(locale, save = true) {
   if (save) {
     try {
@@ -5,6 +5,7 @@ export function generateOperatorLabel(t, o) {
     case "download":
       c = Array.isArray(o.files) ? o.files.length : 1
       if (c === 1) params.file = Array.isArray(o.files) ? o.files[0] : o.files
+      if (params.file && typeof params.file === 'object') params.file = params.file.target || params.file.url
       return t(`operators.${o.type}.formatted`, params, c)
     case "command":
       c = Array.isArray(o.commands) ? o.commands.length : 1
@@ -53,6 +54,56 @@ export const operators = {
       default: ''
     }
   ],
+  editconfig: [
+    {
+      name: 'file',
+      type: 'text',
+      label: 'templates.Filename',
+      default: ''
+    },
+    {
+      name: 'format',
+      type: 'text',
+      default: ''
+    },
+    {
+      name: 'set',
+      type: 'map',
+      default: {}
+    },
+    {
+      name: 'delete',
+      type: 'list',
+      default: []
+    }
+  ],
+  gitdeploy: [
+    {
+      name: 'repo',
+      type: 'text',
+      default: ''
+    },
+    {
+      name: 'branch',
+      type: 'text',
+      default: ''
+    },
+    {
+      name: 'target',
+      type: 'text',
+      default: ''
+    },
+    {
+      name: 'include',
+      type: 'list',
+      default: []
+    },
+    {
+      name: 'exclude',
+      type: 'list',
+      default: []
+    }
+  ],
   writefile: [
     {
       name: 'target',
@@ -145,6 +196,34 @@ export const operators = {
       default: ''
     }
   ],
+  resolveversion: [
+    {
+      name: 'provider',
+      type: 'text',
+      default: 'paper'
+    },
+    {
+      name: 'version',
+      type: 'text',
+      label: 'templates.Version',
+      default: 'latest'
+    },
+    {
+      name: 'build',
+      type: 'text',
+      default: ''
+    },
+    {
+      name: 'outputVariable',
+      type: 'text',
+      default: 'opVersion'
+    },
+    {
+      name: 'buildVariable',
+      type: 'text',
+      default: 'opBuild'
+    }
+  ],
   nodejsdl: [
     {
       name: 'version',
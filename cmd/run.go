@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -12,11 +13,13 @@ import (
 	"github.com/SkyPanel/SkyPanel/v3"
 	"github.com/SkyPanel/SkyPanel/v3/config"
 	"github.com/SkyPanel/SkyPanel/v3/database"
+	"github.com/SkyPanel/SkyPanel/v3/history"
 	"github.com/SkyPanel/SkyPanel/v3/logging"
 	"github.com/SkyPanel/SkyPanel/v3/servers"
 	"github.com/SkyPanel/SkyPanel/v3/servers/docker"
 	"github.com/SkyPanel/SkyPanel/v3/services"
 	"github.com/SkyPanel/SkyPanel/v3/sftp"
+	"github.com/SkyPanel/SkyPanel/v3/tunnel"
 	"github.com/SkyPanel/SkyPanel/v3/utils"
 	"github.com/SkyPanel/SkyPanel/v3/web"
 	"github.com/spf13/cobra"
@@ -38,6 +41,7 @@ var runCmd = &cobra.Command{
 }
 
 var webService *manners.GracefulServer
+var stopTunnel context.CancelFunc
 
 func executeRun(cmd *cobra.Command, args []string) {
 	term, _ := internalRun()
@@ -150,6 +154,9 @@ func internalRun() (terminate chan bool, success bool) {
 		if config.DaemonEnabled.Value() {
 			services.SyncNodeToConfig()
 		}
+
+		services.StartNodeMonitor()
+		services.StartUptimeReports()
 	}
 
 	if config.DaemonEnabled.Value() {
@@ -163,6 +170,15 @@ func internalRun() (terminate chan bool, success bool) {
 
 	web.RegisterRoutes(router)
 
+	if config.DaemonEnabled.Value() && config.TunnelEnabled.Value() {
+		err := startTunnel(router)
+		if err != nil {
+			logging.Error.Printf("error starting tunnel to panel: %s", err.Error())
+			terminate <- true
+			return
+		}
+	}
+
 	l, err := net.Listen("tcp", config.WebHost.Value())
 	if err != nil {
 		logging.Error.Printf("error starting http server: %s", err.Error())
@@ -194,12 +210,21 @@ func closePanel() {
 		webService.Close()
 	}
 
+	if stopTunnel != nil {
+		logging.Debug.Printf("stopping tunnel")
+		stopTunnel()
+	}
+
 	logging.Debug.Printf("stopping sftp server")
 	sftp.Stop()
 
 	logging.Debug.Printf("stopping Gatus service")
 	services.StopGatus()
 
+	logging.Debug.Printf("stopping node monitor")
+	services.StopNodeMonitor()
+	services.StopUptimeReports()
+
 	logging.Debug.Printf("stopping servers")
 	servers.ShutdownService()
 	for _, p := range servers.GetAll() {
@@ -210,6 +235,9 @@ func closePanel() {
 		}
 	}
 
+	logging.Debug.Printf("stopping stats history")
+	history.Close()
+
 	logging.Debug.Printf("stopping database connections")
 	database.Close()
 }
@@ -221,6 +249,23 @@ func panel() {
 	sftp.SetAuthorization(&services.DatabaseSFTPAuthorization{})
 }
 
+func startTunnel(handler http.Handler) error {
+	source := config.TunnelUrl.Value()
+	if source == "" {
+		source = config.AuthUrl.Value()
+	}
+
+	panelUrl, err := tunnel.PanelUrl(source)
+	if err != nil {
+		return err
+	}
+
+	var ctx context.Context
+	ctx, stopTunnel = context.WithCancel(context.Background())
+	go tunnel.Serve(ctx, panelUrl, config.ClientSecret.Value(), handler)
+	return nil
+}
+
 func daemon() error {
 	utils.DetermineKernelSupport()
 
@@ -259,6 +304,13 @@ func daemon() error {
 	}
 	logging.Debug.Printf("Daemon PATH variable: %s", os.Getenv("PATH"))
 
+	if config.StatsHistoryEnabled.Value() {
+		err = history.Init(config.StatsHistoryFile.Value())
+		if err != nil {
+			logging.Error.Printf("Error opening stats history: %s", err.Error())
+		}
+	}
+
 	servers.LoadFromFolder()
 
 	servers.InitService()
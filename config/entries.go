@@ -42,6 +42,9 @@ var MasterUrl = asString("panel.settings.masterUrl", "http://localhost:8080")
 var DiscordWebhook = asString("panel.notifications.discordWebhook", "")
 var DiscordWebhookSystem = asString("panel.notifications.discordWebhookSystem", "")
 var DiscordWebhookNode = asString("panel.notifications.discordWebhookNode", "")
+var UptimeReportSchedule = asString("panel.uptime.report.schedule", "") //weekly, monthly or empty to not send reports
+var UptimeReportEmails = asString("panel.uptime.report.emails", "")     //comma separated
+var UptimeReportLastSent = asString("panel.uptime.report.lastSent", "")
 var LicenseKey = asString("panel.license.key", "")
 var LicenseStatus = asString("panel.license.status", "free")
 var LicenseServerId = asString("panel.license.serverId", "")
@@ -64,10 +67,24 @@ var ServersFolder = asDataFolder("daemon.data.servers", "servers")
 var BackupsFolder = asDataFolder("daemon.data.backups.folder", "backups")
 var BinariesFolder = asDataFolder("daemon.data.binaries", "binaries")
 var CrashLimit = asInt("daemon.data.crashLimit", 3)
+var CrashWindow = asInt("daemon.crash.window", 3600)
+var CrashBackoff = asInt("daemon.crash.backoff", 5)
+var CrashBackoffMax = asInt("daemon.crash.backoffMax", 300)
 var CurseForgeKey = asString("daemon.curseforge.key", curseforgeKey)
 var DataRootFolder = asString("daemon.data.root", "")
 var DepotDownloaderVersion = asString("daemon.depotDownloader.version", "latest")
 var DepotDownloaderDisableLancache = asBool("daemon.depotDownloader.disableLancache", false)
+var TunnelEnabled = asBool("daemon.tunnel.enable", false)
+var TunnelUrl = asString("daemon.tunnel.url", "")
+var CgroupRoot = asString("daemon.cgroup.root", "")
+var StatsHistoryEnabled = asBool("daemon.statsHistory.enable", true)
+var StatsHistoryFile = asDataFolder("daemon.data.statsHistory", "stats.db")
+var MetricsEnabled = asBool("daemon.metrics.enable", true)
+var MetricsToken = asString("daemon.metrics.token", "")
+var CrashReportsFolder = asDataFolder("daemon.data.crashReports", "crashes")
+var CrashReportsMax = asInt("daemon.crashReports.max", 20)
+var AddonsFolder = asDataFolder("daemon.data.addons", "addons")
+var DeployFolder = asDataFolder("daemon.data.deploy", "deploy")
 
 var TokenPublicUrl = asString("token.public", "")
 
@@ -78,6 +95,7 @@ var SecurityDisableUnshare = asBool("security.disableUnshare", false)
 
 var DockerRootPath = asString("docker.root", "")
 var DockerDisallowHost = asBool("docker.disallowHost", false)
+var DockerVolumeAllowlist = asStringArray("docker.volumeAllowlist", []string{})
 
 type entry[T ValueType] struct {
 	key string
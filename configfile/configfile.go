@@ -0,0 +1,337 @@
+package configfile
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+	"github.com/spf13/cast"
+)
+
+const (
+	FormatProperties = "properties"
+	FormatYaml       = "yaml"
+	FormatJson       = "json"
+	FormatToml       = "toml"
+	FormatIni        = "ini"
+)
+
+// Document is a parsed config file which can be changed and written back without losing its layout
+// Paths are dot separated, a literal dot in a key is written as \.
+type Document interface {
+	Set(path string, value interface{}) error
+	Delete(path string) error
+	Values() (map[string]interface{}, error)
+	Bytes() ([]byte, error)
+}
+
+// Edit is a set of changes to make to a config file
+type Edit struct {
+	//keys to set, nested maps are merged key by key
+	Set map[string]interface{} `json:"set,omitempty"`
+	//keys to remove
+	Delete []string `json:"delete,omitempty"`
+	//nested values to merge into the file, keys here are never split on dots
+	Merge map[string]interface{} `json:"merge,omitempty"`
+} //@name ConfigEdit
+
+// Apply makes the changes to the document, merges first, then sets, then deletes
+func (e Edit) Apply(doc Document) error {
+	if err := merge(doc, "", e.Merge); err != nil {
+		return err
+	}
+	for _, k := range sortedKeys(e.Set) {
+		var err error
+		if m, ok := toMap(e.Set[k]); ok {
+			err = merge(doc, k, m)
+		} else {
+			err = doc.Set(k, e.Set[k])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, k := range e.Delete {
+		if err := doc.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DetectFormat works out the format of a file from its name, returning an empty string if it is not known
+func DetectFormat(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".properties":
+		return FormatProperties
+	case ".yml", ".yaml":
+		return FormatYaml
+	case ".json":
+		return FormatJson
+	case ".toml":
+		return FormatToml
+	case ".ini", ".cfg", ".conf":
+		return FormatIni
+	}
+	return ""
+}
+
+// Parse reads the data as the given format
+func Parse(format string, data []byte) (Document, error) {
+	switch format {
+	case FormatProperties:
+		return parseProperties(data), nil
+	case FormatIni:
+		return parseIni(data), nil
+	case FormatYaml:
+		return parseYaml(data)
+	case FormatJson:
+		return parseJson(data)
+	case FormatToml:
+		return parseToml(data)
+	}
+	return nil, SkyPanel.ErrUnknownConfigFormat(format)
+}
+
+// Read loads a config file from the server, a file which does not exist yet is treated as empty
+// If format is empty, it is worked out from the file name
+func Read(fs files.FileServer, file, format string) (Document, string, error) {
+	if format == "" {
+		format = DetectFormat(file)
+		if format == "" {
+			return nil, "", SkyPanel.ErrUnknownConfigFormat(file)
+		}
+	}
+
+	var data []byte
+	f, err := fs.OpenFile(file, os.O_RDONLY, 0644)
+	if err == nil {
+		defer utils.Close(f)
+		data, err = io.ReadAll(f)
+		if err != nil {
+			return nil, format, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, format, err
+	}
+
+	doc, err := Parse(format, data)
+	return doc, format, err
+}
+
+// Write saves the document to the server, creating any missing folders
+func Write(fs files.FileServer, file string, doc Document) error {
+	data, err := doc.Bytes()
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(file); dir != "." {
+		if err = fs.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	f, err := fs.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(f)
+	_, err = f.Write(data)
+	return err
+}
+
+func merge(doc Document, prefix string, values map[string]interface{}) error {
+	for _, k := range sortedKeys(values) {
+		path := JoinPath(prefix, k)
+		if m, ok := toMap(values[k]); ok {
+			if err := merge(doc, path, m); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := doc.Set(path, values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JoinPath adds a key to a path, escaping any dots in the key
+func JoinPath(prefix, key string) string {
+	key = strings.ReplaceAll(key, ".", `\.`)
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func splitPath(path string) []string {
+	var segments []string
+	var current strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+1 < len(path) && path[i+1] == '.' {
+			current.WriteByte('.')
+			i++
+			continue
+		}
+		if path[i] == '.' {
+			segments = append(segments, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteByte(path[i])
+	}
+	return append(segments, current.String())
+}
+
+func validPath(path string) ([]string, error) {
+	segments := splitPath(path)
+	for _, v := range segments {
+		if v == "" {
+			return nil, SkyPanel.ErrInvalidConfigPath(path)
+		}
+	}
+	return segments, nil
+}
+
+func lookup(values interface{}, segments []string) (interface{}, bool) {
+	current := values
+	for _, v := range segments {
+		switch c := current.(type) {
+		case map[string]interface{}:
+			next, ok := c[v]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(v)
+			if err != nil || i < 0 || i >= len(c) {
+				return nil, false
+			}
+			current = c[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// coerce converts the value to the type of the value it replaces, so "25565" from a variable stays a number
+// New keys only become booleans or numbers when the text is exactly one
+func coerce(existing, value interface{}) interface{} {
+	if _, ok := toMap(value); ok {
+		return value
+	}
+	if _, ok := value.([]interface{}); ok {
+		return value
+	}
+
+	switch existing.(type) {
+	case bool:
+		if v, err := cast.ToBoolE(value); err == nil {
+			return v
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		if v, err := cast.ToInt64E(value); err == nil {
+			return v
+		}
+	case float32, float64:
+		if v, err := cast.ToFloat64E(value); err == nil {
+			return v
+		}
+	case string:
+		if v, err := cast.ToStringE(value); err == nil {
+			return v
+		}
+	}
+	return literal(value)
+}
+
+func literal(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == "true" || v == "false" {
+			return v == "true"
+		}
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil && strconv.FormatInt(i, 10) == v {
+			return i
+		}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	}
+	return value
+}
+
+func toMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		return normalize(v).(map[string]interface{}), true
+	}
+	return nil, false
+}
+
+// normalize turns any map[interface{}]interface{} into map[string]interface{} so it can be sent as JSON
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[cast.ToString(k)] = normalize(e)
+		}
+		return m
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+		return v
+	}
+	return value
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitLines breaks the file into lines, returning the line ending it uses so it can be written back the same way
+func splitLines(data []byte) ([]string, string) {
+	text := string(data)
+	newline := "\n"
+	if strings.Contains(text, "\r\n") {
+		newline = "\r\n"
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+	if text == "" {
+		return nil, newline
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n"), newline
+}
+
+func joinLines(lines []string, newline string) []byte {
+	if len(lines) == 0 {
+		return []byte{}
+	}
+	return []byte(strings.Join(lines, newline) + newline)
+}
@@ -0,0 +1,234 @@
+package configfile
+
+import (
+	"testing"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func edit(t *testing.T, format, input string, e Edit) string {
+	doc, err := Parse(format, []byte(input))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, e.Apply(doc)) {
+		t.FailNow()
+	}
+	data, err := doc.Bytes()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return string(data)
+}
+
+func TestDetectFormat(t *testing.T) {
+	assert.Equal(t, FormatProperties, DetectFormat("server.properties"))
+	assert.Equal(t, FormatYaml, DetectFormat("plugins/Essentials/config.YML"))
+	assert.Equal(t, FormatToml, DetectFormat("velocity.toml"))
+	assert.Equal(t, FormatIni, DetectFormat("Game.ini"))
+	assert.Equal(t, "", DetectFormat("eula.txt"))
+}
+
+func TestSplitPath(t *testing.T) {
+	assert.Equal(t, []string{"a", "b.c", "d"}, splitPath(`a.b\.c.d`))
+	assert.Equal(t, `a.b\.c`, JoinPath("a", "b.c"))
+
+	_, err := validPath("a..b")
+	assert.Equal(t, "ErrInvalidConfigPath", SkyPanel.FromError(err).GetCode())
+}
+
+func TestProperties(t *testing.T) {
+	input := "#Minecraft server properties\n" +
+		"server-port=25565\n" +
+		"motd=A Minecraft Server\n" +
+		"resource-pack=https\\://example.com/pack.zip\n" +
+		"rcon.port = 25575\n" +
+		"long=first \\\n    second\n"
+
+	out := edit(t, FormatProperties, input, Edit{
+		Set:    map[string]interface{}{"server-port": 25566, "rcon.port": "25580", "long": "joined", "level-name": "my world"},
+		Delete: []string{"motd"},
+	})
+	assert.Equal(t, "#Minecraft server properties\n"+
+		"server-port=25566\n"+
+		"resource-pack=https\\://example.com/pack.zip\n"+
+		"rcon.port = 25580\n"+
+		"long=joined\n"+
+		"level-name=my world\n", out)
+
+	doc, _ := Parse(FormatProperties, []byte(input))
+	values, err := doc.Values()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/pack.zip", values["resource-pack"])
+	assert.Equal(t, "first second", values["long"])
+	assert.Equal(t, "25575", values["rcon.port"])
+}
+
+func TestIni(t *testing.T) {
+	input := "; global settings\r\n" +
+		"name=test\r\n" +
+		"\r\n" +
+		"[/Script/Engine.GameSession]\r\n" +
+		"MaxPlayers=10\r\n" +
+		"\r\n" +
+		"[Remove]\r\n" +
+		"Key=1\r\n"
+
+	out := edit(t, FormatIni, input, Edit{
+		Set: map[string]interface{}{
+			`/Script/Engine\.GameSession.MaxPlayers`: 20,
+			`/Script/Engine\.GameSession.Password`:   "secret",
+			"port":                                   7777,
+			"New.Enabled":                            true,
+		},
+		Delete: []string{"Remove"},
+	})
+	assert.Equal(t, "; global settings\r\n"+
+		"name=test\r\n"+
+		"port=7777\r\n"+
+		"\r\n"+
+		"[/Script/Engine.GameSession]\r\n"+
+		"MaxPlayers=20\r\n"+
+		"Password=secret\r\n"+
+		"\r\n"+
+		"[New]\r\n"+
+		"Enabled=true\r\n", out)
+}
+
+func TestYaml(t *testing.T) {
+	input := "# main config\n" +
+		"settings:\n" +
+		"  # how many players can join\n" +
+		"  max-players: 20 # keep low\n" +
+		"  motd: 'hello'\n" +
+		"  online: true\n" +
+		"worlds:\n" +
+		"  - world\n" +
+		"  - world_nether\n"
+
+	out := edit(t, FormatYaml, input, Edit{
+		Set:    map[string]interface{}{"settings.max-players": "50", "settings.online": "false", "worlds.2": "world_the_end"},
+		Merge:  map[string]interface{}{"settings": map[string]interface{}{"motd": "welcome", "new.key": "1"}},
+		Delete: []string{"worlds.0"},
+	})
+	assert.Equal(t, "# main config\n"+
+		"settings:\n"+
+		"  # how many players can join\n"+
+		"  max-players: 50 # keep low\n"+
+		"  motd: 'welcome'\n"+
+		"  online: false\n"+
+		"  new.key: 1\n"+
+		"worlds:\n"+
+		"  - world_nether\n"+
+		"  - world_the_end\n", out)
+
+	doc, _ := Parse(FormatYaml, []byte(input))
+	err := doc.Set("worlds.0.name", "x")
+	assert.Equal(t, "ErrInvalidConfigPath", SkyPanel.FromError(err).GetCode())
+}
+
+func TestJson(t *testing.T) {
+	input := "{\n" +
+		"    \"zeta\": 1,\n" +
+		"    \"alpha\": {\n" +
+		"        \"enabled\": false,\n" +
+		"        \"ratio\": 1.50\n" +
+		"    },\n" +
+		"    \"list\": []\n" +
+		"}\n"
+
+	out := edit(t, FormatJson, input, Edit{
+		Set: map[string]interface{}{"zeta": "2", "alpha.enabled": "true", "alpha.name": "<a & b>", "list.0": 3},
+	})
+	assert.Equal(t, "{\n"+
+		"    \"zeta\": 2,\n"+
+		"    \"alpha\": {\n"+
+		"        \"enabled\": true,\n"+
+		"        \"ratio\": 1.50,\n"+
+		"        \"name\": \"<a & b>\"\n"+
+		"    },\n"+
+		"    \"list\": [\n"+
+		"        3\n"+
+		"    ]\n"+
+		"}\n", out)
+
+	out = edit(t, FormatJson, "", Edit{Set: map[string]interface{}{"a.b": "c"}})
+	assert.Equal(t, "{\n  \"a\": {\n    \"b\": \"c\"\n  }\n}\n", out)
+
+	_, err := Parse(FormatJson, []byte("{nope"))
+	assert.Error(t, err)
+}
+
+func TestToml(t *testing.T) {
+	input := "# velocity config\n" +
+		"bind = \"0.0.0.0:25577\" # where to listen\n" +
+		"show-max-players = 500\n" +
+		"motd = \"\"\"\n" +
+		"multi\n" +
+		"line\"\"\"\n" +
+		"\n" +
+		"[servers]\n" +
+		"lobby = \"127.0.0.1:30066\"\n" +
+		"try = [\n" +
+		"  \"lobby\", # first\n" +
+		"]\n" +
+		"\n" +
+		"[advanced]\n" +
+		"compression-level = -1\n" +
+		"\n" +
+		"[[players]]\n" +
+		"name = \"a\"\n" +
+		"\n" +
+		"[[players]]\n" +
+		"name = \"b\"\n"
+
+	out := edit(t, FormatToml, input, Edit{
+		Set: map[string]interface{}{
+			"bind":                       "0.0.0.0:25565",
+			"show-max-players":           "100",
+			"motd":                       "one line",
+			"servers.try":                []interface{}{"lobby", "hub"},
+			"servers.hub":                "127.0.0.1:30067",
+			"players.1.name":             "c",
+			"query.enabled":              "true",
+			"advanced.compression-level": 3,
+		},
+		Delete: []string{"players.0"},
+	})
+	assert.Equal(t, "# velocity config\n"+
+		"bind = \"0.0.0.0:25565\" # where to listen\n"+
+		"show-max-players = 100\n"+
+		"motd = \"one line\"\n"+
+		"\n"+
+		"[servers]\n"+
+		"lobby = \"127.0.0.1:30066\"\n"+
+		"try = ['lobby', 'hub']\n"+
+		"hub = \"127.0.0.1:30067\"\n"+
+		"\n"+
+		"[advanced]\n"+
+		"compression-level = 3\n"+
+		"\n"+
+		"[[players]]\n"+
+		"name = \"c\"\n"+
+		"\n"+
+		"[query]\n"+
+		"enabled = true\n", out)
+
+	doc, _ := Parse(FormatToml, []byte("a = {b = 1}\n"))
+	err := doc.Set("a.c", 2)
+	assert.Equal(t, "ErrInvalidConfigPath", SkyPanel.FromError(err).GetCode())
+
+	_, err = Parse(FormatToml, []byte("a = \n"))
+	assert.Error(t, err)
+}
+
+func TestCoerce(t *testing.T) {
+	assert.Equal(t, int64(25565), coerce(20, "25565"))
+	assert.Equal(t, "25565", coerce("x", 25565))
+	assert.Equal(t, true, coerce(false, "true"))
+	assert.Equal(t, "007", coerce(nil, "007"))
+	assert.Equal(t, int64(7), coerce(nil, "7"))
+	assert.Equal(t, int64(7), coerce(nil, float64(7)))
+	assert.Equal(t, "yes", coerce(nil, "yes"))
+}
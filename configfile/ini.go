@@ -0,0 +1,190 @@
+package configfile
+
+import (
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// iniDocument is an INI file, the path of a key is section.key and keys before the first section have no section
+type iniDocument struct {
+	lines   []string
+	newline string
+}
+
+type iniEntry struct {
+	section    string
+	key        string
+	value      string
+	line       int
+	valueStart int
+}
+
+type iniSection struct {
+	name string
+	//line of the header, -1 for keys before the first section
+	header int
+	//last line which is part of the section, ignoring trailing blank lines
+	last int
+}
+
+func parseIni(data []byte) *iniDocument {
+	lines, newline := splitLines(data)
+	return &iniDocument{lines: lines, newline: newline}
+}
+
+func (d *iniDocument) parse() ([]iniEntry, []iniSection) {
+	var entries []iniEntry
+	sections := []iniSection{{header: -1, last: -1}}
+	current := &sections[0]
+
+	for i, line := range d.lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			sections = append(sections, iniSection{name: strings.TrimSpace(trimmed[1 : len(trimmed)-1]), header: i, last: i})
+			current = &sections[len(sections)-1]
+			continue
+		}
+		current.last = i
+		if trimmed[0] == ';' || trimmed[0] == '#' {
+			continue
+		}
+
+		sep := strings.IndexAny(line, "=:")
+		if sep == -1 {
+			continue
+		}
+		start := sep + 1
+		for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+			start++
+		}
+		entries = append(entries, iniEntry{
+			section:    current.name,
+			key:        strings.TrimSpace(line[:sep]),
+			value:      strings.TrimSpace(line[start:]),
+			line:       i,
+			valueStart: start,
+		})
+	}
+	return entries, sections
+}
+
+// split turns a path into its section and key, the key being the last part
+func (d *iniDocument) split(path string) (string, string, error) {
+	segments, err := validPath(path)
+	if err != nil {
+		return "", "", err
+	}
+	return strings.Join(segments[:len(segments)-1], "."), segments[len(segments)-1], nil
+}
+
+func (d *iniDocument) Set(path string, value interface{}) error {
+	section, key, err := d.split(path)
+	if err != nil {
+		return err
+	}
+	str, err := cast.ToStringE(value)
+	if err != nil {
+		return err
+	}
+
+	entries, sections := d.parse()
+	for _, e := range entries {
+		if e.section == section && e.key == key {
+			d.lines[e.line] = d.lines[e.line][:e.valueStart] + str
+			return nil
+		}
+	}
+
+	line := key + d.separator(entries) + str
+	for _, s := range sections {
+		if s.name == section && (s.header != -1 || section == "") {
+			d.insert(s.last+1, line)
+			return nil
+		}
+	}
+
+	if len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) != "" {
+		d.lines = append(d.lines, "")
+	}
+	d.lines = append(d.lines, "["+section+"]", line)
+	return nil
+}
+
+func (d *iniDocument) Delete(path string) error {
+	section, key, err := d.split(path)
+	if err != nil {
+		return err
+	}
+
+	entries, sections := d.parse()
+	for _, e := range entries {
+		if e.section == section && e.key == key {
+			d.lines = append(d.lines[:e.line], d.lines[e.line+1:]...)
+			return nil
+		}
+	}
+
+	//a path with no matching key may be a whole section
+	name := section
+	if name != "" {
+		name += "."
+	}
+	name += key
+	for i := len(sections) - 1; i > 0; i-- {
+		s := sections[i]
+		if s.name != name {
+			continue
+		}
+		end := len(d.lines)
+		if i+1 < len(sections) {
+			end = sections[i+1].header
+		}
+		d.lines = append(d.lines[:s.header], d.lines[end:]...)
+	}
+	return nil
+}
+
+func (d *iniDocument) Values() (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	entries, _ := d.parse()
+	for _, e := range entries {
+		if e.section == "" {
+			result[e.key] = e.value
+			continue
+		}
+		section, ok := result[e.section].(map[string]interface{})
+		if !ok {
+			section = map[string]interface{}{}
+			result[e.section] = section
+		}
+		section[e.key] = e.value
+	}
+	return result, nil
+}
+
+func (d *iniDocument) Bytes() ([]byte, error) {
+	return joinLines(d.lines, d.newline), nil
+}
+
+// separator matches how the file already writes its keys, with or without spaces around the =
+func (d *iniDocument) separator(entries []iniEntry) string {
+	if len(entries) == 0 {
+		return " = "
+	}
+	line := d.lines[entries[0].line]
+	sep := strings.IndexAny(line, "=:")
+	if sep > 0 && line[sep-1] == ' ' {
+		return " " + string(line[sep]) + " "
+	}
+	return string(line[sep])
+}
+
+func (d *iniDocument) insert(index int, line string) {
+	lines := append([]string{}, d.lines[:index]...)
+	lines = append(lines, line)
+	d.lines = append(lines, d.lines[index:]...)
+}
@@ -0,0 +1,227 @@
+package configfile
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// propertiesDocument is a Java properties file such as server.properties
+// Keys are never split, so rcon.port is the key "rcon.port"
+type propertiesDocument struct {
+	lines   []string
+	newline string
+}
+
+type propertiesEntry struct {
+	key   string
+	value string
+	//lines the entry covers, more than one if it used line continuations
+	start, end int
+	//everything before the value on the first line, so the key keeps its spacing
+	prefix string
+}
+
+func parseProperties(data []byte) *propertiesDocument {
+	lines, newline := splitLines(data)
+	return &propertiesDocument{lines: lines, newline: newline}
+}
+
+func (d *propertiesDocument) entries() []propertiesEntry {
+	var result []propertiesEntry
+	for i := 0; i < len(d.lines); i++ {
+		line := d.lines[i]
+		trimmed := strings.TrimLeft(line, " \t\f")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '!' {
+			continue
+		}
+
+		start := i
+		logical := trimmed
+		for continues(logical) {
+			logical = logical[:len(logical)-1]
+			if i+1 >= len(d.lines) {
+				break
+			}
+			i++
+			logical += strings.TrimLeft(d.lines[i], " \t\f")
+		}
+
+		key, value, valueStart := splitProperty(logical)
+		prefix := line[:len(line)-len(trimmed)]
+		if valueStart <= len(trimmed) {
+			prefix += trimmed[:valueStart]
+		} else {
+			prefix += trimmed
+		}
+		result = append(result, propertiesEntry{key: key, value: value, start: start, end: i, prefix: prefix})
+	}
+	return result
+}
+
+func (d *propertiesDocument) Set(path string, value interface{}) error {
+	segments, err := validPath(path)
+	if err != nil {
+		return err
+	}
+	key := strings.Join(segments, ".")
+	str, err := cast.ToStringE(value)
+	if err != nil {
+		return err
+	}
+
+	entries := d.entries()
+	found := false
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.key != key {
+			continue
+		}
+		found = true
+		d.replace(e.start, e.end, e.prefix+escapeProperty(str, false))
+	}
+
+	if !found {
+		d.lines = append(d.lines, escapeProperty(key, true)+"="+escapeProperty(str, false))
+	}
+	return nil
+}
+
+func (d *propertiesDocument) Delete(path string) error {
+	segments, err := validPath(path)
+	if err != nil {
+		return err
+	}
+	key := strings.Join(segments, ".")
+
+	entries := d.entries()
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].key == key {
+			d.lines = append(d.lines[:entries[i].start], d.lines[entries[i].end+1:]...)
+		}
+	}
+	return nil
+}
+
+func (d *propertiesDocument) Values() (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	for _, e := range d.entries() {
+		result[e.key] = e.value
+	}
+	return result, nil
+}
+
+func (d *propertiesDocument) Bytes() ([]byte, error) {
+	return joinLines(d.lines, d.newline), nil
+}
+
+func (d *propertiesDocument) replace(start, end int, line string) {
+	lines := append([]string{}, d.lines[:start]...)
+	lines = append(lines, line)
+	d.lines = append(lines, d.lines[end+1:]...)
+}
+
+// continues checks if a line ends with an unescaped backslash
+func continues(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+// splitProperty splits a logical line into its key and value, also returning where the value starts
+func splitProperty(line string) (string, string, int) {
+	keyEnd := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) != -1 {
+			keyEnd = i
+			break
+		}
+	}
+
+	i := keyEnd
+	for i < len(line) && strings.IndexByte(" \t\f", line[i]) != -1 {
+		i++
+	}
+	if i < len(line) && (line[i] == '=' || line[i] == ':') {
+		i++
+		for i < len(line) && strings.IndexByte(" \t\f", line[i]) != -1 {
+			i++
+		}
+	}
+	return unescapeProperty(line[:keyEnd]), unescapeProperty(line[i:]), i
+}
+
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// escapeProperty escapes the text the same way Java does when it stores properties, apart from leaving unicode as is
+func escapeProperty(s string, key bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case ' ':
+			if key || i == 0 {
+				b.WriteString(`\ `)
+			} else {
+				b.WriteByte(' ')
+			}
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,433 @@
+package configfile
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// tomlDocument is a TOML file, edited line by line so comments and formatting are left alone
+// Keys inside an array of tables use the index of the table, like servers.0.name
+type tomlDocument struct {
+	lines   []string
+	newline string
+}
+
+type tomlEntry struct {
+	path []string
+	//if the key is in the root table rather than under a header
+	root bool
+	//line the key is on and the line the value ends on
+	line, endLine int
+	//where the value starts on its first line and ends on its last, comments are not part of the value
+	valueStart, valueEnd int
+}
+
+type tomlTable struct {
+	path []string
+	//line of the header, -1 for the root table
+	header int
+	//last line of the last key in the table
+	last   int
+	indent string
+}
+
+var bareTomlKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func parseToml(data []byte) (*tomlDocument, error) {
+	lines, newline := splitLines(data)
+	doc := &tomlDocument{lines: lines, newline: newline}
+	if _, _, err := doc.parse(); err != nil {
+		return nil, err
+	}
+	if _, err := doc.Values(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (d *tomlDocument) parse() ([]tomlEntry, []tomlTable, error) {
+	var entries []tomlEntry
+	tables := []tomlTable{{header: -1, last: -1}}
+	arrays := map[string]int{}
+
+	//resolve adds the current index after any array of tables in the path
+	resolve := func(keys []string, defining bool) []string {
+		var path []string
+		for i, k := range keys {
+			path = append(path, k)
+			if i == len(keys)-1 && defining {
+				break
+			}
+			if n, ok := arrays[joinKey(path)]; ok {
+				path = append(path, strconv.Itoa(n-1))
+			}
+		}
+		return path
+	}
+
+	for i := 0; i < len(d.lines); i++ {
+		line := d.lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+
+		if trimmed[0] == '[' {
+			array := strings.HasPrefix(trimmed, "[[")
+			inner := strings.TrimLeft(trimmed, "[")
+			keys, _, err := parseTomlKey(inner)
+			if err != nil {
+				return nil, nil, err
+			}
+			path := resolve(keys, array)
+			if array {
+				id := joinKey(path)
+				path = append(path, strconv.Itoa(arrays[id]))
+				arrays[id]++
+			}
+			tables = append(tables, tomlTable{path: path, header: i, last: i})
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		keys, n, err := parseTomlKey(line[indent:])
+		if err != nil {
+			return nil, nil, err
+		}
+		start := indent + n
+		if start >= len(line) || line[start] != '=' {
+			return nil, nil, errors.New("expected = after key on line " + strconv.Itoa(i+1))
+		}
+		start++
+		for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+			start++
+		}
+
+		endLine, endCol, err := scanTomlValue(d.lines, i, start)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		table := &tables[len(tables)-1]
+		path := append(append([]string{}, table.path...), keys...)
+		entries = append(entries, tomlEntry{path: path, root: table.header == -1, line: i, endLine: endLine, valueStart: start, valueEnd: endCol})
+		table.last = endLine
+		table.indent = line[:indent]
+		i = endLine
+	}
+	return entries, tables, nil
+}
+
+func (d *tomlDocument) Set(path string, value interface{}) error {
+	segments, err := validPath(path)
+	if err != nil {
+		return err
+	}
+	if m, ok := toMap(value); ok {
+		return merge(d, path, m)
+	}
+
+	entries, tables, err := d.parse()
+	if err != nil {
+		return err
+	}
+	values, _ := d.Values()
+	current, _ := lookup(values, segments)
+	encoded, err := encodeTomlValue(coerce(current, value))
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if equalPath(e.path, segments) {
+			line := d.lines[e.line][:e.valueStart] + encoded + d.lines[e.endLine][e.valueEnd:]
+			d.replace(e.line, e.endLine+1, line)
+			return nil
+		}
+		//the path goes inside a value which is not a table, such as an inline table
+		if hasPrefix(segments, e.path) {
+			return SkyPanel.ErrInvalidConfigPath(path)
+		}
+	}
+
+	best := tables[0]
+	for _, t := range tables[1:] {
+		if equalPath(t.path, segments) {
+			return SkyPanel.ErrInvalidConfigPath(path)
+		}
+		if hasPrefix(segments, t.path) && len(t.path) > len(best.path) {
+			best = t
+		}
+	}
+
+	key := segments[len(best.path):]
+	if best.header == -1 && len(key) > 1 && !d.dotted(entries, segments[:len(segments)-1]) {
+		if len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) != "" {
+			d.lines = append(d.lines, "")
+		}
+		d.lines = append(d.lines, "["+formatTomlKey(key[:len(key)-1])+"]", formatTomlKey(key[len(key)-1:])+" = "+encoded)
+		return nil
+	}
+
+	d.replace(best.last+1, best.last+1, best.indent+formatTomlKey(key)+" = "+encoded)
+	return nil
+}
+
+func (d *tomlDocument) Delete(path string) error {
+	segments, err := validPath(path)
+	if err != nil {
+		return err
+	}
+
+	entries, tables, err := d.parse()
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if equalPath(e.path, segments) {
+			d.replace(e.line, e.endLine+1)
+			return nil
+		}
+	}
+
+	//remove the table and any tables under it, from the bottom up so line numbers stay correct
+	for i := len(tables) - 1; i > 0; i-- {
+		if !hasPrefix(tables[i].path, segments) {
+			continue
+		}
+		end := len(d.lines)
+		if i+1 < len(tables) {
+			end = tables[i+1].header
+		}
+		d.replace(tables[i].header, end)
+	}
+	return nil
+}
+
+func (d *tomlDocument) Values() (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	if err := toml.Unmarshal(joinLines(d.lines, d.newline), &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (d *tomlDocument) Bytes() ([]byte, error) {
+	data := joinLines(d.lines, d.newline)
+	var check map[string]interface{}
+	if err := toml.Unmarshal(data, &check); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// dotted checks if the root table already defines the table using dotted keys, which a new header would clash with
+func (d *tomlDocument) dotted(entries []tomlEntry, table []string) bool {
+	for _, e := range entries {
+		if e.root && hasPrefix(e.path, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// replace swaps lines start up to end for the given lines
+func (d *tomlDocument) replace(start, end int, lines ...string) {
+	result := append([]string{}, d.lines[:start]...)
+	result = append(result, lines...)
+	d.lines = append(result, d.lines[end:]...)
+}
+
+// parseTomlKey reads a bare, quoted or dotted key, returning its parts and how much of the text it used
+func parseTomlKey(s string) ([]string, int, error) {
+	var keys []string
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i >= len(s) {
+			return nil, 0, errors.New("missing key in " + s)
+		}
+
+		switch s[i] {
+		case '"':
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, 0, errors.New("unterminated key in " + s)
+			}
+			key, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				key = s[i+1 : end]
+			}
+			keys = append(keys, key)
+			i = end + 1
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end == -1 {
+				return nil, 0, errors.New("unterminated key in " + s)
+			}
+			keys = append(keys, s[i+1:i+1+end])
+			i += end + 2
+		default:
+			start := i
+			for i < len(s) && (s[i] == '_' || s[i] == '-' || s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z' || s[i] >= '0' && s[i] <= '9') {
+				i++
+			}
+			if i == start {
+				return nil, 0, errors.New("invalid key in " + s)
+			}
+			keys = append(keys, s[start:i])
+		}
+
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+		if i < len(s) && s[i] == '.' {
+			i++
+			continue
+		}
+		return keys, i, nil
+	}
+}
+
+// scanTomlValue finds where a value ends, following strings and arrays over several lines
+func scanTomlValue(lines []string, line, col int) (int, int, error) {
+	depth := 0
+	for l := line; l < len(lines); l++ {
+		s := lines[l]
+		i := 0
+		if l == line {
+			i = col
+		}
+		end := -1
+		for i < len(s) && end == -1 {
+			switch c := s[i]; {
+			case strings.HasPrefix(s[i:], `"""`) || strings.HasPrefix(s[i:], `'''`):
+				closeLine, closeCol, ok := findTomlClose(lines, l, i+3, s[i:i+3])
+				if !ok {
+					return 0, 0, errors.New("unterminated string starting on line " + strconv.Itoa(l+1))
+				}
+				l, s, i = closeLine, lines[closeLine], closeCol
+				continue
+			case c == '"':
+				i++
+				for i < len(s) && s[i] != '"' {
+					if s[i] == '\\' {
+						i++
+					}
+					i++
+				}
+			case c == '\'':
+				if next := strings.IndexByte(s[i+1:], '\''); next != -1 {
+					i += next + 1
+				} else {
+					i = len(s)
+				}
+			case c == '[' || c == '{':
+				depth++
+			case c == ']' || c == '}':
+				depth--
+			case c == '#':
+				end = i
+				continue
+			}
+			i++
+		}
+		if end == -1 {
+			end = len(s)
+		}
+		if depth <= 0 {
+			return l, len(strings.TrimRight(s[:end], " \t")), nil
+		}
+	}
+	return 0, 0, errors.New("unterminated value starting on line " + strconv.Itoa(line+1))
+}
+
+// findTomlClose finds the end of a multi-line string, returning the position just after it
+func findTomlClose(lines []string, line, col int, delim string) (int, int, bool) {
+	for l := line; l < len(lines); l++ {
+		s := lines[l]
+		i := 0
+		if l == line {
+			i = col
+		}
+		for i < len(s) {
+			if delim[0] == '"' && s[i] == '\\' {
+				i += 2
+				continue
+			}
+			if strings.HasPrefix(s[i:], delim) {
+				i += 3
+				//up to two quotes right before the end belong to the string
+				for n := 0; n < 2 && i < len(s) && s[i] == delim[0]; n++ {
+					i++
+				}
+				return l, i, true
+			}
+			i++
+		}
+	}
+	return 0, 0, false
+}
+
+func encodeTomlValue(value interface{}) (string, error) {
+	//JSON strings are valid TOML basic strings
+	if str, ok := value.(string); ok {
+		return jsonString(str), nil
+	}
+
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	encoder.SetTablesInline(true)
+	encoder.SetArraysMultiline(false)
+	if err := encoder.Encode(map[string]interface{}{"v": value}); err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(strings.TrimSpace(buf.String()), "v = "), nil
+}
+
+func formatTomlKey(keys []string) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		if bareTomlKey.MatchString(k) {
+			parts[i] = k
+		} else {
+			parts[i] = strconv.Quote(k)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func joinKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+func equalPath(a, b []string) bool {
+	return len(a) == len(b) && hasPrefix(a, b)
+}
+
+// hasPrefix checks if path starts with all of prefix
+func hasPrefix(path, prefix []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,283 @@
+package configfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// treeDocument is a YAML or JSON file, both are held as a YAML node tree so comments and key order are kept
+type treeDocument struct {
+	root   *yaml.Node
+	json   bool
+	indent int
+}
+
+func parseYaml(data []byte) (*treeDocument, error) {
+	doc := &treeDocument{root: &yaml.Node{}, indent: detectIndent(data, 2)}
+	if err := yaml.Unmarshal(data, doc.root); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func parseJson(data []byte) (*treeDocument, error) {
+	doc := &treeDocument{root: &yaml.Node{}, json: true, indent: detectIndent(data, 2)}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return doc, nil
+	}
+	if !json.Valid(data) {
+		return nil, errors.New("file is not valid JSON")
+	}
+	//JSON is valid YAML, tabs used for indenting are not though
+	if err := yaml.Unmarshal(bytes.ReplaceAll(data, []byte("\t"), []byte(" ")), doc.root); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// body returns the top node of the document, creating an empty mapping for empty files
+func (d *treeDocument) body() *yaml.Node {
+	if d.root.Kind != yaml.DocumentNode {
+		d.root = &yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if len(d.root.Content) == 0 {
+		d.root.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	return d.root.Content[0]
+}
+
+func (d *treeDocument) Set(path string, value interface{}) error {
+	segments, err := validPath(path)
+	if err != nil {
+		return err
+	}
+
+	parent := d.body()
+	for i, v := range segments[:len(segments)-1] {
+		next := child(parent, v)
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if !addChild(parent, v, next) {
+				return SkyPanel.ErrInvalidConfigPath(strings.Join(segments[:i+1], "."))
+			}
+		}
+		parent = next
+	}
+
+	key := segments[len(segments)-1]
+	existing := child(parent, key)
+
+	var current interface{}
+	if existing != nil {
+		_ = existing.Decode(&current)
+	}
+	node := &yaml.Node{}
+	if err = node.Encode(coerce(current, value)); err != nil {
+		return err
+	}
+
+	if existing == nil {
+		if !addChild(parent, key, node) {
+			return SkyPanel.ErrInvalidConfigPath(path)
+		}
+		return nil
+	}
+
+	if existing.Kind == yaml.ScalarNode && node.Kind == yaml.ScalarNode {
+		if existing.Tag != node.Tag {
+			existing.Style = 0
+		}
+		existing.Tag = node.Tag
+		existing.Value = node.Value
+		return nil
+	}
+	node.HeadComment = existing.HeadComment
+	node.LineComment = existing.LineComment
+	node.FootComment = existing.FootComment
+	*existing = *node
+	return nil
+}
+
+func (d *treeDocument) Delete(path string) error {
+	segments, err := validPath(path)
+	if err != nil {
+		return err
+	}
+
+	parent := d.body()
+	for _, v := range segments[:len(segments)-1] {
+		parent = child(parent, v)
+		if parent == nil {
+			return nil
+		}
+	}
+
+	key := segments[len(segments)-1]
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == key {
+				parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+				return nil
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(parent.Content) {
+			parent.Content = append(parent.Content[:i], parent.Content[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func (d *treeDocument) Values() (map[string]interface{}, error) {
+	var result interface{}
+	if err := d.body().Decode(&result); err != nil {
+		return nil, err
+	}
+	if m, ok := toMap(normalize(result)); ok {
+		return m, nil
+	}
+	return nil, errors.New("config does not hold a set of keys")
+}
+
+func (d *treeDocument) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if d.json {
+		if err := writeJson(&buf, d.body(), strings.Repeat(" ", d.indent), 0); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(d.indent)
+	d.body()
+	if err := encoder.Encode(d.root); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// child finds the value under a key of a mapping or an index of a sequence
+func child(parent *yaml.Node, key string) *yaml.Node {
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == key {
+				return parent.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(parent.Content) {
+			return parent.Content[i]
+		}
+	}
+	return nil
+}
+
+// addChild adds a key to a mapping, or appends to a sequence when the key is the next index
+func addChild(parent *yaml.Node, key string, node *yaml.Node) bool {
+	switch parent.Kind {
+	case yaml.MappingNode:
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, node)
+		return true
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(key); err == nil && i == len(parent.Content) {
+			parent.Content = append(parent.Content, node)
+			return true
+		}
+	}
+	return false
+}
+
+// detectIndent finds the smallest indent used in the file
+func detectIndent(data []byte, fallback int) int {
+	indent := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == '-' {
+			continue
+		}
+		width := len(line) - len(trimmed)
+		if strings.HasPrefix(line, "\t") {
+			width = 4 * width
+		}
+		if width > 0 && (indent == 0 || width < indent) {
+			indent = width
+		}
+	}
+	if indent == 0 {
+		return fallback
+	}
+	return indent
+}
+
+// writeJson writes a node tree as JSON, keeping the key order of the file
+func writeJson(buf *bytes.Buffer, node *yaml.Node, indent string, depth int) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		return writeJson(buf, node.Content[0], indent, depth)
+	case yaml.MappingNode, yaml.SequenceNode:
+		open, end, step := "{", "}", 2
+		if node.Kind == yaml.SequenceNode {
+			open, end, step = "[", "]", 1
+		}
+		if len(node.Content) == 0 {
+			buf.WriteString(open + end)
+			return nil
+		}
+		buf.WriteString(open)
+		for i := 0; i < len(node.Content); i += step {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString("\n" + strings.Repeat(indent, depth+1))
+			if step == 2 {
+				buf.WriteString(jsonString(node.Content[i].Value) + ": ")
+			}
+			if err := writeJson(buf, node.Content[i+step-1], indent, depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + strings.Repeat(indent, depth) + end)
+		return nil
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!int", "!!float":
+			if !json.Valid([]byte(node.Value)) {
+				return errors.New(node.Value + " cannot be written as a JSON number")
+			}
+			buf.WriteString(node.Value)
+		case "!!bool":
+			buf.WriteString(strconv.FormatBool(strings.EqualFold(node.Value, "true")))
+		case "!!null":
+			buf.WriteString("null")
+		default:
+			buf.WriteString(jsonString(node.Value))
+		}
+		return nil
+	}
+	return errors.New("config cannot be written as JSON")
+}
+
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
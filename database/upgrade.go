@@ -30,6 +30,10 @@ var dbObjects = []interface{}{
 	&models.Backup{},
 	&models.RecoveryCode{},
 	&models.UptimeStatus{},
+	&models.MaintenanceWindow{},
+	&models.AlertRule{},
+	&models.NotificationChannel{},
+	&models.UserNotification{},
 }
 
 func Upgrade(dbConn *gorm.DB, prettyPrint bool) error {
@@ -407,4 +411,22 @@ var migrations = [][]*gormigrate.Migration{
 			},
 		},
 	},
+	{
+		{
+			ID: "alert-rules-defaults",
+			Migrate: func(db *gorm.DB) error {
+				//these replace the cpu and memory thresholds which used to be hardcoded
+				defaults := []*models.AlertRule{
+					{Name: "CPU alto", Expression: "running && cpu > 80", Severity: "warning", Cooldown: 300, Enabled: true},
+					{Name: "Memoria alta", Expression: "running && memoryPercent > 90", Severity: "warning", Cooldown: 300, Enabled: true},
+				}
+				for _, v := range defaults {
+					if err := db.Create(v).Error; err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	},
 }
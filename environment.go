@@ -47,8 +47,15 @@ type Environment struct {
 	Console         Console         `json:"-"`
 	Server          Server          `json:"-"`
 	Implementation  EnvironmentImpl `json:"-"`
+
+	progressLock sync.Mutex
+	progress     *OperationProgress
+	progressSent time.Time
 }
 
+// how often byte progress is sent, so a fast download does not flood the status socket
+const progressInterval = 250 * time.Millisecond
+
 type ExecutionData struct {
 	Command          string
 	Environment      map[string]string
@@ -235,6 +242,57 @@ func (e *Environment) SetInstalling(flag bool) {
 	})
 }
 
+// GetProgress returns the last progress which was sent, nil if there is none
+func (e *Environment) GetProgress() *OperationProgress {
+	e.progressLock.Lock()
+	defer e.progressLock.Unlock()
+	if e.progress == nil {
+		return nil
+	}
+	progress := *e.progress
+	return &progress
+}
+
+// SetProgress sends the state of a step, nil clears it without sending anything
+// A copy is kept, so the caller can keep changing its own value
+func (e *Environment) SetProgress(progress *OperationProgress) {
+	e.progressLock.Lock()
+	defer e.progressLock.Unlock()
+	if progress == nil {
+		e.progress = nil
+		return
+	}
+	copied := *progress
+	e.progress = &copied
+	e.sendProgress()
+}
+
+// UpdateProgress sets the bytes done by the running step, at most every progressInterval unless it is complete
+func (e *Environment) UpdateProgress(current, size int64) {
+	e.progressLock.Lock()
+	defer e.progressLock.Unlock()
+	if e.progress == nil || e.progress.State != ProgressRunning {
+		e.progress = &OperationProgress{State: ProgressRunning}
+	}
+	e.progress.Current = current
+	e.progress.Size = size
+	if (size == 0 || current < size) && time.Since(e.progressSent) < progressInterval {
+		return
+	}
+	e.sendProgress()
+}
+
+func (e *Environment) sendProgress() {
+	e.progressSent = time.Now()
+	if e.StatusTracker == nil {
+		return
+	}
+	_ = e.StatusTracker.WriteMessage(Transmission{
+		Message: *e.progress,
+		Type:    MessageTypeProgress,
+	})
+}
+
 func (e *Environment) ExecuteInMainProcess(cmd string) (err error) {
 	running, err := e.IsRunning()
 	if err != nil {
@@ -0,0 +1,22 @@
+package SkyPanel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetProgress(t *testing.T) {
+	env := &Environment{}
+	progress := OperationProgress{Step: 1, Total: 2, Operation: "download", State: ProgressRunning}
+	env.SetProgress(&progress)
+
+	//changing the value after it was set does not change what is kept
+	progress.State = ProgressFailed
+	env.UpdateProgress(10, 20)
+	assert.Equal(t, &OperationProgress{Step: 1, Total: 2, Operation: "download", State: ProgressRunning, Current: 10, Size: 20}, env.GetProgress())
+	assert.Zero(t, progress.Current)
+
+	env.SetProgress(nil)
+	assert.Nil(t, env.GetProgress())
+}
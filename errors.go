@@ -4,6 +4,7 @@ import (
 	"errors"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/SkyPanel/SkyPanel/v3/scopes"
 	"github.com/SkyPanel/SkyPanel/v3/utils"
@@ -45,6 +46,8 @@ var ErrNodeHasServers = CreateError("node has servers associated", "ErrNodeHasSe
 var ErrInvalidSession = CreateError("invalid session", "ErrInvalidSession")
 var ErrSessionExpired = CreateError("session expired", "ErrSessionExpired")
 var ErrTaskNotFound = CreateError("task not found", "ErrTaskNotFound")
+var ErrCrashReportNotFound = CreateError("crash report not found", "ErrCrashReportNotFound")
+var ErrInvalidReportPeriod = CreateError("invalid report period", "ErrInvalidReportPeriod")
 var ErrNotImplemented = CreateError("not implemented", "ErrNotImplemented")
 var ErrDockerNotSupported = CreateError("docker not supported", "ErrDockerNotSupported")
 var ErrServerRunning = CreateError("server running", "ErrServerRunning")
@@ -58,6 +61,7 @@ var ErrContainerNotUnique = CreateError("multiple containers found", "ErrContain
 var ErrNoContainerFound = CreateError("no container found", "ErrNoContainerFound")
 var ErrNoMountFound = CreateError("no mount found", "ErrNoMountFound")
 var ErrUnsupportedMountType = CreateError("unsupported mount type", "ErrUnsupportedMountType")
+var ErrDiskQuotaExceeded = CreateError("disk quota exceeded", "ErrDiskQuotaExceeded")
 
 func CreateErrMissingScope(scope scopes.Scope) *Error {
 	return CreateError(ErrMissingScope.Message, ErrMissingScope.Code).Metadata(map[string]interface{}{"scope": scope})
@@ -123,11 +127,16 @@ var ErrFieldLength = func(fieldName string, min int, max int) *Error {
 	return CreateError("${field} must be between ${min} and ${max} characters", "ErrFieldLength").Metadata(map[string]interface{}{"field": fieldName, "min": min, "max": max})
 }
 
+var ErrFieldNotValidOption = func(fieldName string, options ...string) *Error {
+	return CreateError("${field} must be one of ${options}", "ErrFieldNotValidOption").Metadata(map[string]interface{}{"field": fieldName, "options": strings.Join(options, ", ")})
+}
+
 var ErrFactoryError = func(operatorName string, err error) *Error {
 	return CreateError("factory `${operatorName}` encountered an error: `${err}`", "ErrFactoryError").Metadata(map[string]interface{}{"operatorName": operatorName, "err": err.Error()})
 }
 
 var ErrNodeInvalid = CreateError("node is invalid", "ErrNodeInvalid")
+var ErrNodeNotConnected = CreateError("node has no active tunnel to the panel", "ErrNodeNotConnected")
 
 var ErrUnsupportedOS = func(actual, expected string) *Error {
 	return CreateError("OS (${actual}) not supported. Supported OS: ${expected}", "ErrUnsupportedOS").Metadata(map[string]interface{}{"actual": actual, "expected": expected})
@@ -145,6 +154,22 @@ var ErrPathNotAbs = func(path string) *Error {
 	return CreateError("path not abs: ${path}", "ErrPathNotAbs").Metadata(map[string]interface{}{"path": path})
 }
 
+var ErrDockerHostAccess = func(setting string) *Error {
+	return CreateError("${setting} is not allowed on this node because it gives access to the host", "ErrDockerHostAccess").Metadata(map[string]interface{}{"setting": setting})
+}
+
+var ErrVolumeNotAllowed = func(source string) *Error {
+	return CreateError("volume ${source} is not in the allowed volume list", "ErrVolumeNotAllowed").Metadata(map[string]interface{}{"source": source})
+}
+
+var ErrInvalidAlertExpression = func(err error) *Error {
+	return CreateError("invalid alert expression: ${err}", "ErrInvalidAlertExpression").Metadata(map[string]interface{}{"err": err.Error()})
+}
+
+var ErrInvalidNotificationSettings = func(err error) *Error {
+	return CreateError("invalid notification settings: ${err}", "ErrInvalidNotificationSettings").Metadata(map[string]interface{}{"err": err.Error()})
+}
+
 var ErrCurseForgeDistribution = func(projectId uint) *Error {
 	return CreateError("CurseForge modpack with project ID ${projectId} does not allow third-party distribution", "ErrCurseForgeDistribution").Metadata(map[string]interface{}{"projectId": projectId})
 }
@@ -157,6 +182,89 @@ var ErrCurseForgeStatus = func(status string) *Error {
 	return CreateError("Invalid status code from CurseForge: ${status}", "ErrCurseForgeStatus").Metadata(map[string]interface{}{"status": status})
 }
 
+var ErrModrinthStatus = func(status string) *Error {
+	return CreateError("Invalid status code from Modrinth: ${status}", "ErrModrinthStatus").Metadata(map[string]interface{}{"status": status})
+}
+
+var ErrModrinthNoVersion = func(projectId string) *Error {
+	return CreateError("No compatible version found on Modrinth for project ${projectId}", "ErrModrinthNoVersion").Metadata(map[string]interface{}{"projectId": projectId})
+}
+
+var ErrModrinthNotInstalled = func(projectId string) *Error {
+	return CreateError("Modrinth project ${projectId} is not installed", "ErrModrinthNotInstalled").Metadata(map[string]interface{}{"projectId": projectId})
+}
+
+var ErrHashMismatch = func(file, expected, actual string) *Error {
+	return CreateError("${file} has hash ${actual} but ${expected} was expected", "ErrHashMismatch").Metadata(map[string]interface{}{"file": file, "expected": expected, "actual": actual})
+}
+
+var ErrDescriptorTooLarge = func(file string) *Error {
+	return CreateError("${file} is too large to read", "ErrDescriptorTooLarge").Metadata(map[string]interface{}{"file": file})
+}
+
+var ErrInvalidHash = func(file, hash string) *Error {
+	return CreateError("${file} has an invalid hash ${hash}", "ErrInvalidHash").Metadata(map[string]interface{}{"file": file, "hash": hash})
+}
+
+var ErrUnsafePath = func(path string) *Error {
+	return CreateError("path ${path} is outside of the server directory", "ErrUnsafePath").Metadata(map[string]interface{}{"path": path})
+}
+
+var ErrSpigetStatus = func(status string) *Error {
+	return CreateError("Invalid status code from Spiget: ${status}", "ErrSpigetStatus").Metadata(map[string]interface{}{"status": status})
+}
+
+var ErrSpigetExternal = func(resourceId string) *Error {
+	return CreateError("Spigot resource ${resourceId} is hosted externally and cannot be downloaded", "ErrSpigetExternal").Metadata(map[string]interface{}{"resourceId": resourceId})
+}
+
+var ErrAddonNotTracked = func(file string) *Error {
+	return CreateError("${file} was not installed from a known source", "ErrAddonNotTracked").Metadata(map[string]interface{}{"file": file})
+}
+
+var ErrAddonNoRollback = func(file string) *Error {
+	return CreateError("${file} has no previous version to roll back to", "ErrAddonNoRollback").Metadata(map[string]interface{}{"file": file})
+}
+
+var ErrInvalidAddon = func(file string) *Error {
+	return CreateError("${file} is not a plugin or mod jar", "ErrInvalidAddon").Metadata(map[string]interface{}{"file": file})
+}
+
+var ErrOperationTimeout = func(operation string, timeout time.Duration) *Error {
+	return CreateError("${operation} did not finish within ${timeout}", "ErrOperationTimeout").Metadata(map[string]interface{}{"operation": operation, "timeout": timeout.String()})
+}
+
+var ErrOperationCancelled = CreateError("operation cancelled", "ErrOperationCancelled")
+var ErrNotInstalling = CreateError("server is not installing", "ErrNotInstalling")
+
+var ErrUnknownConfigFormat = func(file string) *Error {
+	return CreateError("cannot tell what config format ${file} uses", "ErrUnknownConfigFormat").Metadata(map[string]interface{}{"file": file})
+}
+
+var ErrInvalidConfigPath = func(path string) *Error {
+	return CreateError("${path} does not point to a config value", "ErrInvalidConfigPath").Metadata(map[string]interface{}{"path": path})
+}
+
+var ErrDeployNotConfigured = CreateError("server has no git deployment", "ErrDeployNotConfigured")
+var ErrDeployKeyMissing = CreateError("server has no deploy key", "ErrDeployKeyMissing")
+var ErrInvalidWebhookSignature = CreateError("webhook signature does not match", "ErrInvalidWebhookSignature")
+
+var ErrUnknownVersionProvider = func(provider string) *Error {
+	return CreateError("unknown version provider ${provider}", "ErrUnknownVersionProvider").Metadata(map[string]interface{}{"provider": provider})
+}
+
+var ErrVersionProviderStatus = func(status, url string) *Error {
+	return CreateError("Invalid status code from ${url}: ${status}", "ErrVersionProviderStatus").Metadata(map[string]interface{}{"status": status, "url": url})
+}
+
+var ErrNoMatchingVersion = func(provider, version string) *Error {
+	return CreateError("no ${provider} version matches ${version}", "ErrNoMatchingVersion").Metadata(map[string]interface{}{"provider": provider, "version": version})
+}
+
+var ErrNoMatchingBuild = func(provider, version, build string) *Error {
+	return CreateError("no ${provider} ${version} build matches ${build}", "ErrNoMatchingBuild").Metadata(map[string]interface{}{"provider": provider, "version": version, "build": build})
+}
+
 func GenerateValidationMessage(err error) error {
 	var errs validator.ValidationErrors
 	if errors.As(err, &errs) {
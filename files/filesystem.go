@@ -81,12 +81,12 @@ func (sfp *fileServer) ReadDir(name string) ([]fs.DirEntry, error) {
 }
 
 func (sfp *fileServer) Glob(pattern string) ([]string, error) {
-	parent := filepath.Base(pattern)
-	if parent == pattern {
+	parent, name := filepath.Split(pattern)
+	if parent == "" {
 		parent = "."
 	}
 
-	files, err := sfp.ReadDir(parent)
+	files, err := sfp.ReadDir(filepath.Clean(parent))
 
 	if err != nil {
 		return nil, err
@@ -94,8 +94,8 @@ func (sfp *fileServer) Glob(pattern string) ([]string, error) {
 
 	results := make([]string, 0)
 	for _, v := range files {
-		if matches, _ := filepath.Match(pattern, v.Name()); matches {
-			results = append(results, v.Name())
+		if matches, _ := filepath.Match(name, v.Name()); matches {
+			results = append(results, filepath.Join(parent, v.Name()))
 		}
 	}
 	return results, nil
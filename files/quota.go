@@ -0,0 +1,239 @@
+package files
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mholt/archiver/v3"
+)
+
+var ErrQuotaExceeded = errors.New("disk quota exceeded")
+
+// QuotaWarningPercent is how full the quota has to be before a warning is raised
+const QuotaWarningPercent = 90
+
+// Quota tracks how much disk a server uses.
+// Usage is counted from a full scan of the folder, with writes made through the panel added in between scans.
+type Quota struct {
+	root  string
+	limit atomic.Int64
+	used  atomic.Int64
+
+	scanning atomic.Bool
+	lastScan atomic.Int64
+	warned   atomic.Bool
+
+	//OnWarning is called once when usage goes over QuotaWarningPercent, and again only after it dropped below it
+	OnWarning func(used, limit int64)
+
+	locker sync.Mutex
+}
+
+func NewQuota(root string, limit int64) *Quota {
+	q := &Quota{root: root}
+	q.limit.Store(limit)
+	return q
+}
+
+// Limit is the quota in bytes, 0 means there is no quota
+func (q *Quota) Limit() int64 {
+	if q == nil {
+		return 0
+	}
+	return q.limit.Load()
+}
+
+func (q *Quota) SetLimit(limit int64) {
+	q.limit.Store(limit)
+	q.checkWarning()
+}
+
+func (q *Quota) Used() int64 {
+	if q == nil {
+		return 0
+	}
+	return q.used.Load()
+}
+
+// Percent returns how much of the quota is used, or 0 when there is no quota
+func (q *Quota) Percent() float64 {
+	limit := q.Limit()
+	if limit <= 0 {
+		return 0
+	}
+	return float64(q.Used()) / float64(limit) * 100
+}
+
+// Check returns ErrQuotaExceeded if adding size bytes would go over the quota
+func (q *Quota) Check(size int64) error {
+	limit := q.Limit()
+	if limit > 0 && size > 0 && q.Used()+size > limit {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// Reserve counts size bytes as used, unless it would go over the quota
+func (q *Quota) Reserve(size int64) error {
+	q.locker.Lock()
+	defer q.locker.Unlock()
+
+	if err := q.Check(size); err != nil {
+		return err
+	}
+	q.Add(size)
+	return nil
+}
+
+// Add changes the usage by delta bytes, which may be negative when files are removed or truncated
+func (q *Quota) Add(delta int64) {
+	if q.used.Add(delta) < 0 {
+		q.used.Store(0)
+	}
+	q.checkWarning()
+}
+
+// Recalculate walks the whole folder to correct the usage, to pick up changes the server itself made
+func (q *Quota) Recalculate() error {
+	if !q.scanning.CompareAndSwap(false, true) {
+		return nil
+	}
+	defer q.scanning.Store(false)
+
+	var total int64
+	err := filepath.WalkDir(q.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			//files may disappear while the server is running
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	q.used.Store(total)
+	q.lastScan.Store(time.Now().Unix())
+	q.checkWarning()
+	return nil
+}
+
+// RecalculateIfOlder starts a scan in the background if the last one is older than maxAge
+func (q *Quota) RecalculateIfOlder(maxAge time.Duration, onError func(error)) {
+	if time.Since(time.Unix(q.lastScan.Load(), 0)) < maxAge || q.scanning.Load() {
+		return
+	}
+	go func() {
+		if err := q.Recalculate(); err != nil && onError != nil {
+			onError(err)
+		}
+	}()
+}
+
+// Writer wraps w so everything written through it is counted, failing once the quota is used up
+func (q *Quota) Writer(w io.Writer) io.Writer {
+	return &quotaWriter{quota: q, writer: w}
+}
+
+// WriterAt wraps a file so any growth of it is counted, failing once the quota is used up
+func (q *Quota) WriterAt(file *os.File) io.WriterAt {
+	var size int64
+	if info, err := file.Stat(); err == nil {
+		size = info.Size()
+	}
+	return &quotaWriterAt{quota: q, file: file, size: size}
+}
+
+func (q *Quota) checkWarning() {
+	if q.Percent() >= QuotaWarningPercent {
+		if q.warned.CompareAndSwap(false, true) && q.OnWarning != nil {
+			q.OnWarning(q.Used(), q.Limit())
+		}
+	} else {
+		q.warned.Store(false)
+	}
+}
+
+// UncompressedSize adds up the size of every file in an archive
+func UncompressedSize(sourceFile string, forcedType Walker) (int64, error) {
+	var total int64
+	walkFn := func(file archiver.File) error {
+		if file.Mode().IsRegular() {
+			total += file.Size()
+		}
+		return nil
+	}
+
+	var err error
+	if forcedType != nil {
+		err = forcedType.Walk(sourceFile, walkFn)
+	} else {
+		err = archiver.Walk(sourceFile, walkFn)
+	}
+	return total, err
+}
+
+type quotaWriter struct {
+	quota  *Quota
+	writer io.Writer
+}
+
+func (w *quotaWriter) Write(p []byte) (int, error) {
+	if err := w.quota.Reserve(int64(len(p))); err != nil {
+		return 0, err
+	}
+	n, err := w.writer.Write(p)
+	if n < len(p) {
+		w.quota.Add(int64(n - len(p)))
+	}
+	return n, err
+}
+
+type quotaWriterAt struct {
+	quota *Quota
+	file  *os.File
+
+	locker sync.Mutex
+	size   int64
+}
+
+func (w *quotaWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+
+	var growth int64
+	if end := off + int64(len(p)); end > w.size {
+		growth = end - w.size
+	}
+	if err := w.quota.Reserve(growth); err != nil {
+		return 0, err
+	}
+
+	n, err := w.file.WriteAt(p, off)
+	if end := off + int64(n); end > w.size {
+		w.quota.Add(end - w.size - growth)
+		w.size = end
+	} else {
+		w.quota.Add(-growth)
+	}
+	return n, err
+}
+
+func (w *quotaWriterAt) Close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,76 @@
+package files
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuota(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "existing.dat"), make([]byte, 600), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var warnings int
+	quota := NewQuota(root, 1000)
+	quota.OnWarning = func(used, limit int64) {
+		warnings++
+	}
+
+	if err := quota.Recalculate(); err != nil {
+		t.Fatal(err)
+	}
+	if quota.Used() != 600 {
+		t.Fatalf("Used() = %d, want 600", quota.Used())
+	}
+
+	var buffer bytes.Buffer
+	writer := quota.Writer(&buffer)
+	if _, err := writer.Write(make([]byte, 350)); err != nil {
+		t.Fatalf("write within quota failed: %s", err)
+	}
+	if warnings != 1 {
+		t.Errorf("expected a warning after passing %d%%, got %d", QuotaWarningPercent, warnings)
+	}
+	if _, err := writer.Write(make([]byte, 100)); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("write over quota returned %v, want ErrQuotaExceeded", err)
+	}
+	if quota.Used() != 950 {
+		t.Errorf("Used() = %d, want 950", quota.Used())
+	}
+
+	quota.Add(-500)
+	quota.Add(500)
+	if warnings != 2 {
+		t.Errorf("expected the warning to fire again after dropping below, got %d", warnings)
+	}
+}
+
+func TestQuotaWriterAt(t *testing.T) {
+	root := t.TempDir()
+	file, err := os.Create(filepath.Join(root, "upload.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	quota := NewQuota(root, 100)
+	writer := quota.WriterAt(file)
+
+	if _, err = writer.WriteAt(make([]byte, 60), 0); err != nil {
+		t.Fatal(err)
+	}
+	//rewriting what is already there does not use more space
+	if _, err = writer.WriteAt(make([]byte, 60), 0); err != nil {
+		t.Fatal(err)
+	}
+	if quota.Used() != 60 {
+		t.Errorf("Used() = %d, want 60", quota.Used())
+	}
+	if _, err = writer.WriteAt(make([]byte, 60), 60); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("write over quota returned %v, want ErrQuotaExceeded", err)
+	}
+}
@@ -0,0 +1,11 @@
+package SkyPanel
+
+type GitDeploy struct {
+	Repo    string   `json:"repo"`
+	Branch  string   `json:"branch,omitempty"`  //empty uses the default branch of the repo
+	Target  string   `json:"target,omitempty"`  //folder inside the server to deploy to, empty is the server folder
+	Include []string `json:"include,omitempty"` //globs of files to deploy, such as plugins/**, empty deploys everything
+	Exclude []string `json:"exclude,omitempty"` //globs of files to leave out, applied after include
+	Secret  string   `json:"secret,omitempty"`  //secret the webhook is signed with, empty disables the webhook
+	Restart bool     `json:"restart,omitempty"` //restart the server after the webhook deploys, if it is running
+} //@name GitDeploy
@@ -0,0 +1,229 @@
+package gitdeploy
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Result is what a deploy changed in the server folder
+type Result struct {
+	Commit  string   `json:"commit"`
+	Changed []string `json:"changed"`
+	Removed []string `json:"removed"`
+} //@name GitDeployResult
+
+// manifest is kept next to each clone, so files which leave the repo can be removed from the server
+type manifest struct {
+	Commit string   `json:"commit"`
+	Target string   `json:"target"`
+	Files  []string `json:"files"`
+}
+
+var locks sync.Map
+
+// Deploy fetches the repo and copies the files which match the globs into the server
+// Files deployed before which are no longer in the repo are removed, anything else in the server folder is left alone
+func Deploy(ctx context.Context, fs files.FileServer, serverId string, d SkyPanel.GitDeploy) (Result, error) {
+	result := Result{Changed: []string{}, Removed: []string{}}
+	if d.Repo == "" {
+		return result, SkyPanel.ErrDeployNotConfigured
+	}
+
+	dir := cloneFolder(serverId, d)
+	lock, _ := locks.LoadOrStore(dir, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	repo, hash, err := fetch(ctx, serverId, dir, d)
+	if err != nil {
+		return result, err
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return result, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return result, err
+	}
+
+	target := path.Clean("/" + d.Target)[1:]
+	deployed := make([]string, 0)
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if err := ctx.Err(); err != nil {
+			return context.Cause(ctx)
+		}
+		//links could point anywhere on the node, so they are never followed
+		if f.Mode == filemode.Symlink || !Included(f.Name, d.Include, d.Exclude) {
+			return nil
+		}
+
+		deployed = append(deployed, f.Name)
+		changed, err := writeFile(fs, path.Join(target, f.Name), f)
+		if changed {
+			result.Changed = append(result.Changed, f.Name)
+		}
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	previous := readManifest(dir)
+	if previous.Target == target {
+		result.Removed = removed(previous.Files, deployed)
+	} else if previous.Files != nil {
+		result.Removed = previous.Files
+	}
+	for _, v := range result.Removed {
+		if err = fs.Remove(path.Join(previous.Target, v)); err != nil && !os.IsNotExist(err) {
+			return result, err
+		}
+	}
+
+	result.Commit = hash.String()
+	return result, writeManifest(dir, manifest{Commit: result.Commit, Target: target, Files: deployed})
+}
+
+// DeleteServer removes the clones and deploy key of a server
+func DeleteServer(serverId string) {
+	_ = os.RemoveAll(filepath.Join(config.DeployFolder.Value(), serverId))
+}
+
+func cloneFolder(serverId string, d SkyPanel.GitDeploy) string {
+	sum := sha1.Sum([]byte(d.Repo + "#" + d.Branch))
+	return filepath.Join(config.DeployFolder.Value(), serverId, "repos", hex.EncodeToString(sum[:])[:16])
+}
+
+// fetch brings the clone up to date, cloning it first if needed, and returns the commit to deploy
+func fetch(ctx context.Context, serverId, dir string, d SkyPanel.GitDeploy) (*git.Repository, plumbing.Hash, error) {
+	auth, err := authFor(serverId, d.Repo)
+	if err != nil {
+		return nil, plumbing.ZeroHash, err
+	}
+
+	repo, err := git.PlainOpen(dir)
+	if err == nil {
+		err = repo.FetchContext(ctx, &git.FetchOptions{RemoteName: "origin", Auth: auth, Force: true})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil, plumbing.ZeroHash, err
+		}
+	} else {
+		_ = os.RemoveAll(dir)
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return nil, plumbing.ZeroHash, err
+		}
+		options := &git.CloneOptions{URL: d.Repo, Auth: auth}
+		if d.Branch != "" {
+			options.ReferenceName = plumbing.NewBranchReferenceName(d.Branch)
+			options.SingleBranch = true
+		}
+		repo, err = git.PlainCloneContext(ctx, dir, true, options)
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, plumbing.ZeroHash, err
+		}
+	}
+
+	branch := d.Branch
+	if branch == "" {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, plumbing.ZeroHash, err
+		}
+		branch = head.Name().Short()
+	}
+
+	for _, name := range []plumbing.ReferenceName{plumbing.NewRemoteReferenceName("origin", branch), plumbing.NewBranchReferenceName(branch)} {
+		if ref, err := repo.Reference(name, true); err == nil {
+			return repo, ref.Hash(), nil
+		}
+	}
+	return nil, plumbing.ZeroHash, plumbing.ErrReferenceNotFound
+}
+
+// writeFile copies a file from the repo into the server, skipping it if the server already has the same content
+func writeFile(fs files.FileServer, target string, f *object.File) (bool, error) {
+	if existing, err := fs.Open(target); err == nil {
+		data, err := io.ReadAll(existing)
+		utils.Close(existing)
+		if err == nil && plumbing.ComputeHash(plumbing.BlobObject, data) == f.Hash {
+			return false, nil
+		}
+	}
+
+	if dir := path.Dir(target); dir != "." {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			return false, err
+		}
+	}
+
+	mode := os.FileMode(0644)
+	if f.Mode == filemode.Executable {
+		mode = 0755
+	}
+	file, err := fs.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return false, err
+	}
+	defer utils.Close(file)
+
+	reader, err := f.Reader()
+	if err != nil {
+		return false, err
+	}
+	defer utils.Close(reader)
+
+	_, err = io.Copy(file, reader)
+	return true, err
+}
+
+func removed(previous, current []string) []string {
+	keep := make(map[string]bool, len(current))
+	for _, v := range current {
+		keep[v] = true
+	}
+	result := make([]string, 0)
+	for _, v := range previous {
+		if !keep[v] {
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func readManifest(dir string) manifest {
+	var m manifest
+	data, err := os.ReadFile(dir + ".json")
+	if err == nil {
+		_ = json.Unmarshal(data, &m)
+	}
+	return m
+}
+
+func writeManifest(dir string, m manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dir+".json", data, 0600)
+}
@@ -0,0 +1,177 @@
+package gitdeploy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/stretchr/testify/assert"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@localhost",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@localhost")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %s", args, out)
+	}
+}
+
+// commit writes the files into the work tree, removes those set to nil and pushes to the bare repo
+func commit(t *testing.T, work string, changes map[string][]byte) {
+	for name, data := range changes {
+		file := filepath.Join(work, name)
+		if data == nil {
+			runGit(t, work, "rm", "-q", name)
+			continue
+		}
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, data, 0644))
+		runGit(t, work, "add", name)
+	}
+	runGit(t, work, "commit", "-q", "-m", "update")
+	runGit(t, work, "push", "-q", "origin", "main")
+}
+
+func TestDeploy(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	_ = config.DeployFolder.Set(t.TempDir(), false)
+
+	bare := t.TempDir()
+	work := t.TempDir()
+	runGit(t, bare, "init", "-q", "--bare", "-b", "main")
+	runGit(t, work, "init", "-q", "-b", "main")
+	runGit(t, work, "remote", "add", "origin", bare)
+	commit(t, work, map[string][]byte{
+		"plugins/a.jar":            []byte("a"),
+		"plugins/b.jar":            []byte("b"),
+		"plugins/Essentials/x.yml": []byte("x: 1"),
+		"README.md":                []byte("readme"),
+		"world/level.dat":          []byte("level"),
+	})
+
+	serverDir := t.TempDir()
+	fileServer, err := files.NewFileServer(serverDir, os.Getuid(), os.Getgid())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer fileServer.Close()
+	assert.NoError(t, os.WriteFile(filepath.Join(serverDir, "server.properties"), []byte("keep"), 0644))
+
+	d := SkyPanel.GitDeploy{Repo: bare, Target: "data", Include: []string{"plugins/**", "*.md"}, Exclude: []string{"Essentials"}}
+	result, err := Deploy(context.Background(), fileServer, "test", d)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"README.md", "plugins/a.jar", "plugins/b.jar"}, result.Changed)
+	assert.Empty(t, result.Removed)
+	assertFile(t, serverDir, "data/plugins/a.jar", "a")
+	assertMissing(t, serverDir, "data/plugins/Essentials/x.yml")
+	assertMissing(t, serverDir, "data/world/level.dat")
+
+	commit(t, work, map[string][]byte{"plugins/a.jar": []byte("a2"), "plugins/b.jar": nil})
+	result, err = Deploy(context.Background(), fileServer, "test", d)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"plugins/a.jar"}, result.Changed)
+	assert.Equal(t, []string{"plugins/b.jar"}, result.Removed)
+	assertFile(t, serverDir, "data/plugins/a.jar", "a2")
+	assertMissing(t, serverDir, "data/plugins/b.jar")
+	assertFile(t, serverDir, "server.properties", "keep")
+
+	result, err = Deploy(context.Background(), fileServer, "test", d)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Changed)
+	assert.Empty(t, result.Removed)
+
+	DeleteServer("test")
+	_, err = os.Stat(filepath.Join(config.DeployFolder.Value(), "test"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestKeys(t *testing.T) {
+	_ = config.DeployFolder.Set(t.TempDir(), false)
+
+	_, err := PublicKey("test")
+	assert.Equal(t, "ErrDeployKeyMissing", SkyPanel.FromError(err).GetCode())
+
+	generated, err := GenerateKey("test")
+	assert.NoError(t, err)
+	assert.Regexp(t, `^ssh-ed25519 \S+ skypanel-test$`, generated)
+
+	public, err := PublicKey("test")
+	assert.NoError(t, err)
+	assert.Equal(t, generated, public)
+
+	auth, err := authFor("test", "git@github.com:example/repo.git")
+	assert.NoError(t, err)
+	assert.NotNil(t, auth)
+	auth, err = authFor("test", "https://github.com/example/repo.git")
+	assert.NoError(t, err)
+	assert.Nil(t, auth)
+
+	assert.NoError(t, DeleteKey("test"))
+	_, err = authFor("test", "ssh://git@example.com/repo.git")
+	assert.Equal(t, "ErrDeployKeyMissing", SkyPanel.FromError(err).GetCode())
+}
+
+func TestMatch(t *testing.T) {
+	assert.True(t, Match("plugins/**", "plugins/a/b.jar"))
+	assert.True(t, Match("plugins", "plugins/a.jar"))
+	assert.True(t, Match("*.jar", "plugins/a.jar"))
+	assert.True(t, Match("plugins/*.jar", "plugins/a.jar"))
+	assert.True(t, Match("config/**/*.yml", "config/a.yml"))
+	assert.True(t, Match("config/**/*.yml", "config/a/b/c.yml"))
+	assert.False(t, Match("plugins/*.jar", "plugins/a/b.jar"))
+	assert.False(t, Match("plugins/**", "other/plugins.jar"))
+	assert.False(t, Match("", "a"))
+
+	assert.True(t, Included("a", nil, nil))
+	assert.False(t, Included(".git/config", nil, []string{".git"}))
+	assert.False(t, Included("b", []string{"a"}, nil))
+}
+
+func TestWebhook(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main","repository":{"default_branch":"main"}}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, VerifyWebhook("secret", http.Header{"X-Hub-Signature-256": {"sha256=" + signature}}, body))
+	assert.True(t, VerifyWebhook("secret", http.Header{"X-Gitea-Signature": {signature}}, body))
+	assert.True(t, VerifyWebhook("secret", http.Header{"X-Gitlab-Token": {"secret"}}, body))
+	assert.False(t, VerifyWebhook("other", http.Header{"X-Hub-Signature-256": {"sha256=" + signature}}, body))
+	assert.False(t, VerifyWebhook("secret", http.Header{}, body))
+	assert.False(t, VerifyWebhook("", http.Header{"X-Gitlab-Token": {""}}, body))
+
+	assert.True(t, ShouldDeploy(SkyPanel.GitDeploy{}, http.Header{"X-Github-Event": {"push"}}, body))
+	assert.True(t, ShouldDeploy(SkyPanel.GitDeploy{Branch: "main"}, http.Header{}, body))
+	assert.False(t, ShouldDeploy(SkyPanel.GitDeploy{Branch: "dev"}, http.Header{}, body))
+	assert.False(t, ShouldDeploy(SkyPanel.GitDeploy{}, http.Header{"X-Github-Event": {"ping"}}, body))
+	assert.True(t, ShouldDeploy(SkyPanel.GitDeploy{Branch: "dev"}, http.Header{}, nil))
+}
+
+func assertFile(t *testing.T, dir, name, expected string) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
+
+func assertMissing(t *testing.T, dir, name string) {
+	_, err := os.Stat(filepath.Join(dir, name))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
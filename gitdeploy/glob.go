@@ -0,0 +1,65 @@
+package gitdeploy
+
+import (
+	"path"
+	"strings"
+)
+
+// Included checks if a file in the repo is deployed, an empty include list deploys everything
+func Included(name string, include, exclude []string) bool {
+	if len(include) > 0 && !matchAny(name, include) {
+		return false
+	}
+	return !matchAny(name, exclude)
+}
+
+func matchAny(name string, patterns []string) bool {
+	for _, v := range patterns {
+		if Match(v, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match checks a path against a glob, ** matches any number of folders
+// A pattern without a / matches at any depth, like .gitignore, and a pattern matching a folder matches everything in it
+func Match(pattern, name string) bool {
+	pattern = strings.Trim(strings.TrimPrefix(pattern, "./"), "/")
+	if pattern == "" {
+		return false
+	}
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+
+	patterns := strings.Split(pattern, "/")
+	segments := strings.Split(name, "/")
+	for i := len(segments); i > 0; i-- {
+		if matchSegments(patterns, segments[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(patterns, segments []string) bool {
+	if len(patterns) == 0 {
+		return len(segments) == 0
+	}
+	if patterns[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(patterns[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(patterns[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(patterns[1:], segments[1:])
+}
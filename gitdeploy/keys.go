@@ -0,0 +1,142 @@
+package gitdeploy
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var knownHostsLocker sync.Mutex
+
+// GenerateKey creates a new deploy key for the server, replacing any it had, and returns the public key
+// The public key is what gets added to the repo as a read-only deploy key
+func GenerateKey(serverId string) (string, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	block, err := ssh.MarshalPrivateKey(private, comment(serverId))
+	if err != nil {
+		return "", err
+	}
+
+	file := keyFile(serverId)
+	if err = os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(file, pem.EncodeToMemory(block), 0600); err != nil {
+		return "", err
+	}
+
+	key, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return "", err
+	}
+	return authorizedKey(key, serverId), nil
+}
+
+// PublicKey returns the public half of the deploy key of the server
+func PublicKey(serverId string) (string, error) {
+	signer, err := loadKey(serverId)
+	if err != nil {
+		return "", err
+	}
+	return authorizedKey(signer.PublicKey(), serverId), nil
+}
+
+// DeleteKey removes the deploy key of the server, repos are then pulled without a key
+func DeleteKey(serverId string) error {
+	err := os.Remove(keyFile(serverId))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func loadKey(serverId string) (ssh.Signer, error) {
+	data, err := os.ReadFile(keyFile(serverId))
+	if os.IsNotExist(err) {
+		return nil, SkyPanel.ErrDeployKeyMissing
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ssh.ParsePrivateKey(data)
+}
+
+// authFor returns the deploy key to use for the repo, ssh repos are the only ones which use it
+func authFor(serverId, repo string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(repo)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Protocol != "ssh" {
+		return nil, nil
+	}
+
+	signer, err := loadKey(serverId)
+	if err != nil {
+		return nil, err
+	}
+	user := endpoint.User
+	if user == "" {
+		user = "git"
+	}
+	auth := &gitssh.PublicKeys{User: user, Signer: signer}
+	auth.HostKeyCallback = trustOnFirstUse(filepath.Join(config.DeployFolder.Value(), serverId, "known_hosts"))
+	return auth, nil
+}
+
+// trustOnFirstUse accepts the key of a host the first time it is seen and remembers it
+// Later connections to that host must present the same key
+func trustOnFirstUse(file string) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		knownHostsLocker.Lock()
+		defer knownHostsLocker.Unlock()
+
+		if _, err := os.Stat(file); err == nil {
+			callback, err := knownhosts.New(file)
+			if err != nil {
+				return err
+			}
+			err = callback(hostname, remote, key)
+			var keyErr *knownhosts.KeyError
+			if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+				return err
+			}
+		}
+
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.WriteString(knownhosts.Line([]string{hostname}, key) + "\n")
+		return err
+	}
+}
+
+func authorizedKey(key ssh.PublicKey, serverId string) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))) + " " + comment(serverId)
+}
+
+func comment(serverId string) string {
+	return "skypanel-" + serverId
+}
+
+func keyFile(serverId string) string {
+	return filepath.Join(config.DeployFolder.Value(), serverId, "deploy_key")
+}
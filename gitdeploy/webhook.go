@@ -0,0 +1,73 @@
+package gitdeploy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+)
+
+// VerifyWebhook checks the webhook was sent by something which knows the secret
+// GitHub and Gitea/Forgejo sign the body, GitLab sends the secret as a token
+func VerifyWebhook(secret string, header http.Header, body []byte) bool {
+	if secret == "" {
+		return false
+	}
+
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+
+	signature := strings.TrimPrefix(header.Get("X-Hub-Signature-256"), "sha256=")
+	if signature == "" {
+		signature = header.Get("X-Gitea-Signature")
+	}
+	given, err := hex.DecodeString(signature)
+	if err != nil || len(given) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(given, mac.Sum(nil))
+}
+
+// ShouldDeploy checks if the webhook is a push to the branch being deployed
+// Events which say nothing about a branch, such as a manual call, always deploy
+func ShouldDeploy(d SkyPanel.GitDeploy, header http.Header, body []byte) bool {
+	for _, v := range []string{"X-GitHub-Event", "X-Gitea-Event"} {
+		if event := header.Get(v); event != "" && event != "push" {
+			return false
+		}
+	}
+	if event := header.Get("X-Gitlab-Event"); event != "" && event != "Push Hook" {
+		return false
+	}
+
+	var push struct {
+		Ref        string `json:"ref"`
+		Repository struct {
+			DefaultBranch string `json:"default_branch"`
+		} `json:"repository"`
+		Project struct {
+			DefaultBranch string `json:"default_branch"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(body, &push); err != nil || push.Ref == "" {
+		return true
+	}
+
+	branch := d.Branch
+	if branch == "" {
+		branch = push.Repository.DefaultBranch
+	}
+	if branch == "" {
+		branch = push.Project.DefaultBranch
+	}
+	return branch == "" || push.Ref == "refs/heads/"+branch
+}
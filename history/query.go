@@ -0,0 +1,90 @@
+package history
+
+import (
+	"time"
+)
+
+type History struct {
+	From       int64   `json:"from"`
+	To         int64   `json:"to"`
+	Step       int64   `json:"step"`
+	Resolution int64   `json:"resolution"`
+	Points     []Point `json:"points"`
+} //@name StatsHistory
+
+// Point is the average over one step, with the highest CPU and memory seen in it
+type Point struct {
+	Time      int64   `json:"time" gorm:"column:bucket"`
+	Cpu       float64 `json:"cpu"`
+	CpuMax    float64 `json:"cpuMax"`
+	Memory    float64 `json:"memory"`
+	MemoryMax float64 `json:"memoryMax"`
+	RxRate    float64 `json:"rxRate"`
+	TxRate    float64 `json:"txRate"`
+	ReadRate  float64 `json:"readRate"`
+	WriteRate float64 `json:"writeRate"`
+	DiskUsed  int64   `json:"diskUsed"`
+} //@name StatsHistoryPoint
+
+const query = `SELECT time / ? * ? AS bucket,
+	SUM(cpu * count) / SUM(count) AS cpu, MAX(cpu_max) AS cpu_max,
+	SUM(memory * count) / SUM(count) AS memory, MAX(memory_max) AS memory_max,
+	SUM(rx_rate * count) / SUM(count) AS rx_rate, SUM(tx_rate * count) / SUM(count) AS tx_rate,
+	SUM(read_rate * count) / SUM(count) AS read_rate, SUM(write_rate * count) / SUM(count) AS write_rate,
+	MAX(disk_used) AS disk_used
+FROM samples
+WHERE server_id = ? AND resolution = ? AND time >= ? AND time < ?
+GROUP BY bucket
+ORDER BY bucket`
+
+// Query gets the history of a server between from and to, averaged over step.
+// The finest resolution still kept for the start of the range is used, a step of 0 picks one that gives a readable chart.
+func (s *Store) Query(serverId string, from, to time.Time, step time.Duration) (*History, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() || !from.Before(to) {
+		from = to.Add(-time.Hour)
+	}
+
+	resolution := pickResolution(from, step)
+	stepSeconds := normalizeStep(int64(step/time.Second), resolution, to.Unix()-from.Unix())
+
+	result := &History{
+		From:       from.Unix(),
+		To:         to.Unix(),
+		Step:       stepSeconds,
+		Resolution: resolution,
+		Points:     make([]Point, 0),
+	}
+
+	err := s.db.Raw(query, stepSeconds, stepSeconds, serverId, resolution, from.Unix(), to.Unix()).Scan(&result.Points).Error
+	return result, err
+}
+
+// pickResolution finds the finest samples which are both still kept for from, and not finer than step needs
+func pickResolution(from time.Time, step time.Duration) int64 {
+	age := time.Since(from)
+	switch {
+	case step < time.Minute && age <= retention[ResolutionRaw]:
+		return ResolutionRaw
+	case step < time.Hour && age <= retention[ResolutionMinute]:
+		return ResolutionMinute
+	default:
+		return ResolutionHour
+	}
+}
+
+// normalizeStep makes the step a multiple of the resolution, large enough to stay under maxPoints
+func normalizeStep(step, resolution, span int64) int64 {
+	if minimum := span / maxPoints; step < minimum {
+		step = minimum
+	}
+	if step < resolution {
+		step = resolution
+	}
+	if remainder := step % resolution; remainder != 0 {
+		step += resolution - remainder
+	}
+	return step
+}
@@ -0,0 +1,254 @@
+package history
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Resolutions samples are kept at, in seconds
+const (
+	ResolutionRaw    int64 = 1
+	ResolutionMinute int64 = 60
+	ResolutionHour   int64 = 3600
+)
+
+var retention = map[int64]time.Duration{
+	ResolutionRaw:    24 * time.Hour,
+	ResolutionMinute: 7 * 24 * time.Hour,
+	ResolutionHour:   90 * 24 * time.Hour,
+}
+
+// maxPoints caps how many points one query returns, the step is raised to stay under it
+const maxPoints = 2000
+
+const flushInterval = 10 * time.Second
+const pruneInterval = time.Hour
+
+var ErrNotEnabled = errors.New("stats history is not enabled")
+
+type sample struct {
+	ServerId   string `gorm:"primaryKey;size:64"`
+	Resolution int64  `gorm:"primaryKey;autoIncrement:false"`
+	Time       int64  `gorm:"primaryKey;autoIncrement:false"`
+	Count      int64
+	Cpu        float64
+	CpuMax     float64
+	Memory     float64
+	MemoryMax  float64
+	RxRate     float64
+	TxRate     float64
+	ReadRate   float64
+	WriteRate  float64
+	DiskUsed   int64
+}
+
+func (sample) TableName() string {
+	return "samples"
+}
+
+// Store keeps the stats of every server, rolled up into coarser samples as they age
+type Store struct {
+	db      *gorm.DB
+	pending chan sample
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+var store *Store
+
+// Init opens the default store, which Record and Query use
+func Init(path string) (err error) {
+	store, err = Open(path)
+	return
+}
+
+// Close flushes and closes the default store
+func Close() {
+	if store != nil {
+		store.Close()
+		store = nil
+	}
+}
+
+// Record queues the stats of a server to be stored, if the store is running
+func Record(serverId string, stats *SkyPanel.ServerStats) {
+	if store != nil {
+		store.Record(serverId, time.Now(), stats)
+	}
+}
+
+// Query gets the history of a server from the default store
+func Query(serverId string, from, to time.Time, step time.Duration) (*History, error) {
+	if store == nil {
+		return nil, ErrNotEnabled
+	}
+	return store.Query(serverId, from, to, step)
+}
+
+// DeleteServer removes everything stored for a server
+func DeleteServer(serverId string) {
+	if store != nil {
+		if err := store.DeleteServer(serverId); err != nil {
+			logging.Error.Printf("Error deleting stats history for %s: %s", serverId, err)
+		}
+	}
+}
+
+func Open(path string) (*Store, error) {
+	gormConfig := &gorm.Config{
+		Logger: logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{LogLevel: logger.Silent}),
+	}
+	db, err := gorm.Open(sqlite.Open("file:"+path+"?_journal_mode=WAL&_busy_timeout=5000"), gormConfig)
+	if err != nil {
+		return nil, err
+	}
+	if d, err := db.DB(); err == nil {
+		d.SetMaxOpenConns(1)
+	}
+
+	if err = db.AutoMigrate(&sample{}); err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		db:      db,
+		pending: make(chan sample, 1024),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+func (s *Store) Close() {
+	close(s.done)
+	s.wg.Wait()
+	if d, err := s.db.DB(); err == nil {
+		_ = d.Close()
+	}
+}
+
+// Record queues a sample, it is written with the next flush
+func (s *Store) Record(serverId string, at time.Time, stats *SkyPanel.ServerStats) {
+	entry := sample{
+		ServerId:  serverId,
+		Time:      at.Unix(),
+		Count:     1,
+		Cpu:       stats.Cpu,
+		CpuMax:    stats.Cpu,
+		Memory:    stats.Memory,
+		MemoryMax: stats.Memory,
+	}
+	if stats.Network != nil {
+		entry.RxRate = stats.Network.RxRate
+		entry.TxRate = stats.Network.TxRate
+	}
+	if stats.DiskIO != nil {
+		entry.ReadRate = stats.DiskIO.ReadRate
+		entry.WriteRate = stats.DiskIO.WriteRate
+	}
+	if stats.Disk != nil {
+		entry.DiskUsed = stats.Disk.Used
+	}
+
+	select {
+	case s.pending <- entry:
+	default:
+		//the writer is behind, losing a sample is better than holding up stats
+	}
+}
+
+func (s *Store) run() {
+	defer s.wg.Done()
+
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+
+	s.prune()
+
+	var batch []sample
+	for {
+		select {
+		case entry := <-s.pending:
+			batch = append(batch, entry)
+		case <-flush.C:
+			batch = s.flush(batch)
+		case <-prune.C:
+			s.prune()
+		case <-s.done:
+			for {
+				select {
+				case entry := <-s.pending:
+					batch = append(batch, entry)
+				default:
+					s.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// upsert adds a sample into its bucket, averaging it with what the bucket already holds
+const upsert = `INSERT INTO samples (server_id, resolution, time, count, cpu, cpu_max, memory, memory_max, rx_rate, tx_rate, read_rate, write_rate, disk_used)
+VALUES (?, ?, ?, 1, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (server_id, resolution, time) DO UPDATE SET
+	cpu = (samples.cpu * samples.count + excluded.cpu) / (samples.count + 1),
+	cpu_max = MAX(samples.cpu_max, excluded.cpu_max),
+	memory = (samples.memory * samples.count + excluded.memory) / (samples.count + 1),
+	memory_max = MAX(samples.memory_max, excluded.memory_max),
+	rx_rate = (samples.rx_rate * samples.count + excluded.rx_rate) / (samples.count + 1),
+	tx_rate = (samples.tx_rate * samples.count + excluded.tx_rate) / (samples.count + 1),
+	read_rate = (samples.read_rate * samples.count + excluded.read_rate) / (samples.count + 1),
+	write_rate = (samples.write_rate * samples.count + excluded.write_rate) / (samples.count + 1),
+	disk_used = excluded.disk_used,
+	count = samples.count + 1`
+
+func (s *Store) flush(batch []sample) []sample {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, v := range batch {
+			for _, resolution := range []int64{ResolutionRaw, ResolutionMinute, ResolutionHour} {
+				bucket := v.Time / resolution * resolution
+				err := tx.Exec(upsert, v.ServerId, resolution, bucket, v.Cpu, v.CpuMax, v.Memory, v.MemoryMax,
+					v.RxRate, v.TxRate, v.ReadRate, v.WriteRate, v.DiskUsed).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Error.Printf("Error writing stats history: %s", err)
+	}
+	return batch[:0]
+}
+
+func (s *Store) prune() {
+	for resolution, keep := range retention {
+		cutoff := time.Now().Add(-keep).Unix()
+		err := s.db.Where("resolution = ? AND time < ?", resolution, cutoff).Delete(&sample{}).Error
+		if err != nil {
+			logging.Error.Printf("Error pruning stats history: %s", err)
+		}
+	}
+}
+
+func (s *Store) DeleteServer(serverId string) error {
+	return s.db.Where("server_id = ?", serverId).Delete(&sample{}).Error
+}
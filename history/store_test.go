@@ -0,0 +1,78 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+)
+
+func TestStoreQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now().Add(-10 * time.Minute).Truncate(time.Minute)
+	for i := 0; i < 24; i++ {
+		s.Record("server1", start.Add(time.Duration(i)*5*time.Second), &SkyPanel.ServerStats{Cpu: float64(i), Memory: 100})
+	}
+	s.Record("server2", start, &SkyPanel.ServerStats{Cpu: 99})
+	//closing flushes whatever is pending
+	s.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	tests := []struct {
+		name           string
+		step           time.Duration
+		wantResolution int64
+		wantPoints     int
+		wantCpu        float64
+		wantCpuMax     float64
+	}{
+		{name: "raw", step: 5 * time.Second, wantResolution: ResolutionRaw, wantPoints: 24, wantCpu: 0, wantCpuMax: 0},
+		{name: "raw grouped", step: 30 * time.Second, wantResolution: ResolutionRaw, wantPoints: 4, wantCpu: 2.5, wantCpuMax: 5},
+		{name: "minute rollup", step: time.Minute, wantResolution: ResolutionMinute, wantPoints: 2, wantCpu: 5.5, wantCpuMax: 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.Query("server1", start, start.Add(2*time.Minute), tt.step)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Resolution != tt.wantResolution {
+				t.Errorf("resolution = %d, want %d", result.Resolution, tt.wantResolution)
+			}
+			if len(result.Points) != tt.wantPoints {
+				t.Fatalf("got %d points, want %d", len(result.Points), tt.wantPoints)
+			}
+			first := result.Points[0]
+			if first.Cpu != tt.wantCpu || first.CpuMax != tt.wantCpuMax || first.Memory != 100 {
+				t.Errorf("first point = %+v, want cpu %v and cpuMax %v", first, tt.wantCpu, tt.wantCpuMax)
+			}
+		})
+	}
+}
+
+func TestNormalizeStep(t *testing.T) {
+	tests := []struct {
+		step, resolution, span, want int64
+	}{
+		{step: 0, resolution: ResolutionRaw, span: 3600, want: 1},
+		{step: 90, resolution: ResolutionMinute, span: 3600, want: 120},
+		{step: 5, resolution: ResolutionRaw, span: 86400, want: 43},
+		{step: 0, resolution: ResolutionHour, span: 90 * 86400, want: 3600 * 2},
+	}
+	for _, tt := range tests {
+		if got := normalizeStep(tt.step, tt.resolution, tt.span); got != tt.want {
+			t.Errorf("normalizeStep(%d, %d, %d) = %d, want %d", tt.step, tt.resolution, tt.span, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package SkyPanel
 
-import "github.com/SkyPanel/SkyPanel/v3/utils"
+import (
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+)
 
 type ServerIdResponse struct {
 	Id string `json:"id"`
@@ -10,18 +14,82 @@ type ServerStats struct {
 	Cpu    float64         `json:"cpu"`
 	Memory float64         `json:"memory"`
 	Jvm    *utils.JvmStats `json:"jvm,omitempty"`
+	Limits *LimitStats     `json:"limits,omitempty"`
+	Disk   *DiskUsage      `json:"disk,omitempty"`
+
+	Network   *NetworkStats `json:"network,omitempty"`
+	DiskIO    *DiskIOStats  `json:"diskIo,omitempty"`
+	OpenFiles int32         `json:"openFiles,omitempty"`
+	Threads   int32         `json:"threads,omitempty"`
 } //@name ServerStats
 
+// NetworkStats are the total bytes moved since the server started, and the bytes per second since the last sample
+type NetworkStats struct {
+	RxBytes uint64  `json:"rxBytes"`
+	TxBytes uint64  `json:"txBytes"`
+	RxRate  float64 `json:"rxRate"`
+	TxRate  float64 `json:"txRate"`
+} //@name NetworkStats
+
+// DiskIOStats are the total bytes read and written since the server started, and the bytes per second since the last sample
+type DiskIOStats struct {
+	ReadBytes  uint64  `json:"readBytes"`
+	WriteBytes uint64  `json:"writeBytes"`
+	ReadRate   float64 `json:"readRate"`
+	WriteRate  float64 `json:"writeRate"`
+} //@name DiskIOStats
+
+type DiskUsage struct {
+	Used    int64   `json:"used"`
+	Quota   int64   `json:"quota,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+} //@name DiskUsage
+
+// LimitStats reports usage against the resource limits of a server, when the environment enforces them
+type LimitStats struct {
+	MemoryCurrent      uint64  `json:"memoryCurrent"`
+	MemoryMax          uint64  `json:"memoryMax,omitempty"`
+	CpuQuota           float64 `json:"cpuQuota,omitempty"`
+	CpuThrottledMicros uint64  `json:"cpuThrottledMicros"`
+	PidsCurrent        uint64  `json:"pidsCurrent"`
+	PidsMax            uint64  `json:"pidsMax,omitempty"`
+	IOWeight           uint16  `json:"ioWeight,omitempty"`
+	OomKills           uint64  `json:"oomKills"`
+} //@name LimitStats
+
 type ServerLogs struct {
 	Epoch int64  `json:"epoch"`
 	Logs  []byte `json:"logs"`
 } //@name ServerLogs
 
 type ServerRunning struct {
-	Running    bool `json:"running"`
-	Installing bool `json:"installing"`
+	Running      bool               `json:"running"`
+	Installing   bool               `json:"installing"`
+	CrashLooping bool               `json:"crashLooping,omitempty"`
+	NextRestart  *time.Time         `json:"nextRestart,omitempty"`
+	DownCause    string             `json:"downCause,omitempty"`
+	Disk         *DiskUsage         `json:"disk,omitempty"`
+	Progress     *OperationProgress `json:"progress,omitempty"` //only while installing
 } //@name ServerRunning
 
+const (
+	ProgressRunning = "running"
+	ProgressDone    = "done"
+	ProgressFailed  = "failed"
+	ProgressSkipped = "skipped"
+)
+
+// OperationProgress is where an install, task or hook is, sent as a progress message on the status socket
+type OperationProgress struct {
+	Step      int    `json:"step,omitempty"`  //1 based, 0 when not part of a process, such as pulling the docker image to start
+	Total     int    `json:"total,omitempty"` //number of steps in the process
+	Operation string `json:"operation,omitempty"`
+	State     string `json:"state"`
+	Error     string `json:"error,omitempty"`
+	Current   int64  `json:"current,omitempty"` //bytes done, for downloads
+	Size      int64  `json:"size,omitempty"`    //bytes in total, 0 if not known
+} //@name OperationProgress
+
 type ServerData struct {
 	Variables map[string]Variable `json:"data"`
 	Groups    []Group             `json:"groups,omitempty"`
@@ -0,0 +1,24 @@
+package SkyPanel
+
+// ResourceLimits are the optional limits a server definition can put on the process it runs.
+// A zero value means the limit is not applied.
+type ResourceLimits struct {
+	//MemoryMax is the hard memory limit in MiB
+	MemoryMax int64 `json:"memoryMax,omitempty"`
+	//CpuQuota is how many cores the server may use, 1.5 means one and a half cores
+	CpuQuota float64 `json:"cpuQuota,omitempty"`
+	//PidsMax limits how many processes and threads can exist at once
+	PidsMax int64 `json:"pidsMax,omitempty"`
+	//IOWeight is the relative block IO weight, between 1 and 10000 (the kernel default is 100)
+	IOWeight uint16 `json:"ioWeight,omitempty"`
+} //@name ResourceLimits
+
+// IsSet returns true if any limit is configured
+func (l ResourceLimits) IsSet() bool {
+	return l.MemoryMax > 0 || l.CpuQuota > 0 || l.PidsMax > 0 || l.IOWeight > 0
+}
+
+// MemoryBytes returns the memory limit in bytes
+func (l ResourceLimits) MemoryBytes() int64 {
+	return l.MemoryMax * 1024 * 1024
+}
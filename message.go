@@ -8,7 +8,10 @@ type Transmission struct {
 type TransmissionType string
 
 const (
-	MessageTypeLog    = "console"
-	MessageTypeStats  = "stat"
-	MessageTypeStatus = "status"
+	MessageTypeLog      = "console"
+	MessageTypeStats    = "stat"
+	MessageTypeStatus   = "status"
+	MessageTypeProgress = "progress"
+
+	MessageTypeNotification = "notification"
 )
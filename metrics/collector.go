@@ -0,0 +1,252 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/query"
+	"github.com/SkyPanel/SkyPanel/v3/servers"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/host"
+	"github.com/shirou/gopsutil/load"
+	"github.com/shirou/gopsutil/mem"
+)
+
+const namespace = "skypanel"
+
+var serverLabels = []string{"server", "name"}
+
+func serverDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "server", name), help, serverLabels, nil)
+}
+
+func nodeDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "node", name), help, nil, nil)
+}
+
+var (
+	daemonInfo = prometheus.NewDesc(prometheus.BuildFQName(namespace, "daemon", "info"), "Version of the daemon", []string{"version"}, nil)
+
+	serverRunning    = serverDesc("running", "Whether the server is running")
+	serverInstalling = serverDesc("installing", "Whether the server is being installed")
+	serverCpu        = serverDesc("cpu_percent", "CPU used by the server, where 100 is one core")
+	serverMemory     = serverDesc("memory_bytes", "Memory used by the server")
+	serverMemoryMax  = serverDesc("memory_limit_bytes", "Memory limit of the server, when one is enforced")
+	serverOomKills   = serverDesc("oom_kills_total", "Processes of the server killed for running out of memory")
+
+	serverHeapUsed           = serverDesc("jvm_heap_used_bytes", "Java heap in use")
+	serverHeapCommitted      = serverDesc("jvm_heap_committed_bytes", "Java heap reserved by the JVM")
+	serverMetaspaceUsed      = serverDesc("jvm_metaspace_used_bytes", "Java metaspace in use")
+	serverMetaspaceCommitted = serverDesc("jvm_metaspace_committed_bytes", "Java metaspace reserved by the JVM")
+
+	serverNetworkRx = serverDesc("network_receive_bytes_total", "Bytes received by the server since it started")
+	serverNetworkTx = serverDesc("network_transmit_bytes_total", "Bytes sent by the server since it started")
+	serverDiskRead  = serverDesc("disk_read_bytes_total", "Bytes read from disk by the server since it started")
+	serverDiskWrite = serverDesc("disk_written_bytes_total", "Bytes written to disk by the server since it started")
+	serverDiskUsed  = serverDesc("disk_used_bytes", "Disk space used by the files of the server")
+	serverDiskQuota = serverDesc("disk_quota_bytes", "Disk quota of the server, when one is set")
+
+	serverCrashes       = serverDesc("crashes_total", "Times the server exited unexpectedly since the daemon started")
+	serverCrashRestarts = serverDesc("crash_restarts", "Crashes within the crash window, automatic restarts stop past the crash limit")
+	serverCrashLooping  = serverDesc("crash_looping", "Whether the server crashed too often and is no longer restarted")
+
+	serverBackupDuration = serverDesc("backup_duration_seconds", "How long the last backup took")
+	serverBackupTime     = serverDesc("backup_last_timestamp_seconds", "When the last backup started")
+	serverBackupSuccess  = serverDesc("backup_last_success", "Whether the last backup succeeded")
+
+	serverPlayers    = serverDesc("players_online", "Players online, as reported by the query of the server")
+	serverPlayersMax = serverDesc("players_max", "Player slots, as reported by the query of the server")
+
+	nodeServers     = nodeDesc("servers", "Servers on the node")
+	nodeCpu         = nodeDesc("cpu_percent", "CPU used by the whole node")
+	nodeMemoryUsed  = nodeDesc("memory_used_bytes", "Memory used on the node")
+	nodeMemoryTotal = nodeDesc("memory_total_bytes", "Memory of the node")
+	nodeLoad1       = nodeDesc("load1", "Load average over 1 minute")
+	nodeLoad5       = nodeDesc("load5", "Load average over 5 minutes")
+	nodeLoad15      = nodeDesc("load15", "Load average over 15 minutes")
+	nodeUptime      = nodeDesc("uptime_seconds", "Time since the node booted")
+	nodeDiskUsed    = nodeDesc("disk_used_bytes", "Disk used on the volume holding the servers")
+	nodeDiskTotal   = nodeDesc("disk_total_bytes", "Size of the volume holding the servers")
+)
+
+// playerCacheTime is how long a query answer is reused, so scrapes do not ping every game each time
+const playerCacheTime = 30 * time.Second
+
+type players struct {
+	at          time.Time
+	online, max int
+	ok          bool
+}
+
+// collector reads the state of the node and its servers when scraped
+type collector struct {
+	locker  sync.Mutex
+	players map[string]players
+}
+
+func newCollector() *collector {
+	return &collector{players: make(map[string]players)}
+}
+
+// Describe sends nothing, which leaves the collector unchecked.
+// The metrics depend on which servers exist, and describing them by collecting would query every game.
+func (c *collector) Describe(chan<- *prometheus.Desc) {
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(daemonInfo, prometheus.GaugeValue, 1, SkyPanel.Version)
+
+	all := servers.GetAll()
+	ch <- prometheus.MustNewConstMetric(nodeServers, prometheus.GaugeValue, float64(len(all)))
+	collectNode(ch)
+
+	var wg sync.WaitGroup
+	for _, v := range all {
+		wg.Add(1)
+		go func(p *servers.Server) {
+			defer wg.Done()
+			c.collectServer(ch, p)
+		}(v)
+	}
+	wg.Wait()
+
+	c.forgetDeleted(all)
+}
+
+func (c *collector) collectServer(ch chan<- prometheus.Metric, p *servers.Server) {
+	labels := []string{p.Id(), p.Server.Display}
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, labels...)
+	}
+
+	running, _ := p.IsRunning()
+	gauge(serverRunning, boolToFloat(running))
+	gauge(serverInstalling, boolToFloat(p.GetEnvironment().IsInstalling()))
+	counter(serverCrashes, float64(p.CrashCount()))
+	gauge(serverCrashRestarts, float64(p.RecentCrashes()))
+	gauge(serverCrashLooping, boolToFloat(p.IsCrashLooping()))
+
+	if backup := p.LastBackup(); backup != nil {
+		gauge(serverBackupDuration, backup.Duration.Seconds())
+		gauge(serverBackupTime, float64(backup.Started.Unix()))
+		gauge(serverBackupSuccess, boolToFloat(backup.Success))
+	}
+
+	if stats, err := p.GetStats(); err == nil {
+		gauge(serverCpu, stats.Cpu)
+		gauge(serverMemory, stats.Memory)
+		if stats.Jvm != nil {
+			gauge(serverHeapUsed, float64(stats.Jvm.HeapUsed))
+			gauge(serverHeapCommitted, float64(stats.Jvm.HeapTotal))
+			gauge(serverMetaspaceUsed, float64(stats.Jvm.MetaspaceUsed))
+			gauge(serverMetaspaceCommitted, float64(stats.Jvm.MetaspaceTotal))
+		}
+		if stats.Limits != nil {
+			if stats.Limits.MemoryMax > 0 {
+				gauge(serverMemoryMax, float64(stats.Limits.MemoryMax))
+			}
+			counter(serverOomKills, float64(stats.Limits.OomKills))
+		}
+		if stats.Network != nil {
+			counter(serverNetworkRx, float64(stats.Network.RxBytes))
+			counter(serverNetworkTx, float64(stats.Network.TxBytes))
+		}
+		if stats.DiskIO != nil {
+			counter(serverDiskRead, float64(stats.DiskIO.ReadBytes))
+			counter(serverDiskWrite, float64(stats.DiskIO.WriteBytes))
+		}
+		if stats.Disk != nil {
+			gauge(serverDiskUsed, float64(stats.Disk.Used))
+			if stats.Disk.Quota > 0 {
+				gauge(serverDiskQuota, float64(stats.Disk.Quota))
+			}
+		}
+	}
+
+	if running {
+		if result := c.queryPlayers(p); result.ok {
+			gauge(serverPlayers, float64(result.online))
+			gauge(serverPlayersMax, float64(result.max))
+		}
+	}
+}
+
+// queryPlayers gets the player count of a server, asking the game only when the cached answer is too old
+func (c *collector) queryPlayers(p *servers.Server) players {
+	c.locker.Lock()
+	cached, exists := c.players[p.Id()]
+	c.locker.Unlock()
+	if exists && time.Since(cached.at) < playerCacheTime {
+		return cached
+	}
+
+	result := players{at: time.Now()}
+	if res, err := p.QueryGame(); err == nil {
+		for _, v := range res {
+			if count, ok := v.(query.Players); ok {
+				result.online, result.max = count.PlayerCount()
+				result.ok = true
+				break
+			}
+		}
+	}
+
+	c.locker.Lock()
+	c.players[p.Id()] = result
+	c.locker.Unlock()
+	return result
+}
+
+func (c *collector) forgetDeleted(all []*servers.Server) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+
+	existing := make(map[string]players, len(all))
+	for _, v := range all {
+		if cached, ok := c.players[v.Id()]; ok {
+			existing[v.Id()] = cached
+		}
+	}
+	c.players = existing
+}
+
+func collectNode(ch chan<- prometheus.Metric) {
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+
+	//with no interval this is the usage since the previous scrape
+	if percent, err := cpu.Percent(0, false); err == nil && len(percent) > 0 {
+		gauge(nodeCpu, percent[0])
+	}
+	if memory, err := mem.VirtualMemory(); err == nil {
+		gauge(nodeMemoryUsed, float64(memory.Used))
+		gauge(nodeMemoryTotal, float64(memory.Total))
+	}
+	if avg, err := load.Avg(); err == nil {
+		gauge(nodeLoad1, avg.Load1)
+		gauge(nodeLoad5, avg.Load5)
+		gauge(nodeLoad15, avg.Load15)
+	}
+	if uptime, err := host.Uptime(); err == nil {
+		gauge(nodeUptime, float64(uptime))
+	}
+	if usage, err := disk.Usage(config.ServersFolder.Value()); err == nil {
+		gauge(nodeDiskUsed, float64(usage.Used))
+		gauge(nodeDiskTotal, float64(usage.Total))
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var handler http.Handler
+var handlerOnce sync.Once
+
+// Handler serves the metrics of the daemon and its servers, in the Prometheus text or OpenMetrics format.
+// It uses its own registry, so metrics of the embedded Gatus on the default registry are not mixed in.
+func Handler() http.Handler {
+	handlerOnce.Do(func() {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(
+			newCollector(),
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+		handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		})
+	})
+	return handler
+}
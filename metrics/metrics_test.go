@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+	body := recorder.Body.String()
+	for _, name := range []string{"skypanel_daemon_info", "skypanel_node_servers 0", "skypanel_node_memory_total_bytes", "go_goroutines"} {
+		if !strings.Contains(body, name) {
+			t.Errorf("metrics are missing %s", name)
+		}
+	}
+}
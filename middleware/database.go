@@ -53,7 +53,7 @@ func HasTransaction(c *gin.Context) {
 
 	c.Set("noTransactionDb", db)
 
-	_ = db.Transaction(func(trans *gorm.DB) error {
+	err := db.Transaction(func(trans *gorm.DB) error {
 		c.Set("db", trans)
 
 		c.Next()
@@ -66,4 +66,26 @@ func HasTransaction(c *gin.Context) {
 		}
 		return nil
 	})
+
+	if err == nil {
+		if callbacks, ok := c.Get(afterCommitKey); ok {
+			for _, v := range callbacks.([]func()) {
+				v()
+			}
+		}
+	}
+}
+
+const afterCommitKey = "afterCommit"
+
+// AfterCommit runs fn once the transaction of the request is committed, and never if it is rolled back
+// Requests without a transaction run it right away
+func AfterCommit(c *gin.Context, fn func()) {
+	if _, exists := c.Get("noTransactionDb"); !exists {
+		fn()
+		return
+	}
+	callbacks, _ := c.Get(afterCommitKey)
+	list, _ := callbacks.([]func())
+	c.Set(afterCommitKey, append(list, fn))
 }
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/alerts"
+	"gopkg.in/go-playground/validator.v9"
+	"gorm.io/gorm"
+)
+
+type AlertRule struct {
+	ID             uint    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name           string  `gorm:"column:name;not null;size:100" json:"name" validate:"required,max=100"`
+	ServerID       *string `gorm:"column:server_id;size:20;index" json:"serverId,omitempty" validate:"omitempty,printascii"` // vacío para reglas globales
+	Expression     string  `gorm:"column:expression;not null;size:1000" json:"expression" validate:"required,max=1000"`
+	Severity       string  `gorm:"column:severity;not null;size:20" json:"severity" validate:"oneof=info warning critical"`
+	Cooldown       int64   `gorm:"column:cooldown;not null" json:"cooldown" validate:"min=0"` // segundos entre avisos mientras siga activa
+	NotifyRecovery bool    `gorm:"column:notify_recovery;not null" json:"notifyRecovery"`
+	Enabled        bool    `gorm:"column:enabled;not null" json:"enabled"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+} //@name AlertRule
+
+func (r *AlertRule) IsValid() (err error) {
+	err = validator.New().Struct(r)
+	if err != nil {
+		return SkyPanel.GenerateValidationMessage(err)
+	}
+	if _, err = alerts.Compile(r.Expression); err != nil {
+		return SkyPanel.ErrInvalidAlertExpression(err)
+	}
+	return
+}
+
+func (r *AlertRule) BeforeSave(*gorm.DB) (err error) {
+	if r.ServerID != nil && *r.ServerID == "" {
+		r.ServerID = nil
+	}
+	if r.Severity == "" {
+		r.Severity = alerts.SeverityWarning
+	}
+	return r.IsValid()
+}
+
+// Rule gives what the alert tracker needs to evaluate this rule
+func (r *AlertRule) Rule() alerts.Rule {
+	return alerts.Rule{
+		Id:         r.ID,
+		Expression: r.Expression,
+		Cooldown:   time.Duration(r.Cooldown) * time.Second,
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"gopkg.in/go-playground/validator.v9"
+	"gorm.io/gorm"
+)
+
+// MaintenanceWindow es un periodo de mantenimiento planificado, que no cuenta para el SLA.
+// Sin servidor ni nodo se aplica a todos los servidores, el nodo local es el 0.
+type MaintenanceWindow struct {
+	ID          uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ServerID    *string   `gorm:"column:server_id;size:20;index" json:"serverId,omitempty" validate:"omitempty,printascii"`
+	NodeID      *uint     `gorm:"column:node_id;index" json:"nodeId,omitempty" validate:"-"`
+	StartTime   time.Time `gorm:"column:start_time;not null;index" json:"startTime" validate:"required"`
+	EndTime     time.Time `gorm:"column:end_time;not null;index" json:"endTime" validate:"required,gtfield=StartTime"`
+	Description string    `gorm:"column:description;size:200" json:"description" validate:"max=200"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+} //@name MaintenanceWindow
+
+func (m *MaintenanceWindow) IsValid() (err error) {
+	err = validator.New().Struct(m)
+	if err != nil {
+		err = SkyPanel.GenerateValidationMessage(err)
+	}
+	return
+}
+
+func (m *MaintenanceWindow) BeforeSave(*gorm.DB) (err error) {
+	if m.ServerID != nil && *m.ServerID == "" {
+		m.ServerID = nil
+	}
+	return m.IsValid()
+}
+
+// AppliesTo dice si la ventana cubre un servidor del nodo dado
+func (m *MaintenanceWindow) AppliesTo(serverId string, nodeId uint) bool {
+	if m.ServerID != nil && *m.ServerID != serverId {
+		return false
+	}
+	if m.NodeID != nil && *m.NodeID != nodeId {
+		return false
+	}
+	return true
+}
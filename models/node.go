@@ -10,6 +10,11 @@ import (
 	"time"
 )
 
+const (
+	NodeConnectionDirect  = "direct"
+	NodeConnectionReverse = "reverse"
+)
+
 type Node struct {
 	ID          uint   `json:"-"`
 	Name        string `gorm:"column:name;not null;size:100;uniqueIndex;unique" json:"-" validate:"required,printascii"`
@@ -21,6 +26,8 @@ type Node struct {
 
 	Secret string `gorm:"column:secret;not null;size=36" json:"-" validate:"required"`
 
+	ConnectionMode string `gorm:"column:connection_mode;not null;size:20;default:direct" json:"-" validate:"omitempty,oneof=direct reverse"`
+
 	CreatedAt time.Time `json:"-"`
 	UpdatedAt time.Time `json:"-"`
 
@@ -43,6 +50,9 @@ func (n *Node) BeforeSave(*gorm.DB) (err error) {
 	if n.IsLocal() {
 		return errors.New("cannot save local node")
 	}
+	if n.ConnectionMode == "" {
+		n.ConnectionMode = NodeConnectionDirect
+	}
 	return
 }
 
@@ -50,6 +60,11 @@ func (n *Node) IsLocal() bool {
 	return n.Local
 }
 
+// IsReverse reports if the daemon dials the panel instead of the panel dialing the daemon
+func (n *Node) IsReverse() bool {
+	return !n.IsLocal() && n.ConnectionMode == NodeConnectionReverse
+}
+
 var LocalNode = &Node{
 	ID:          0,
 	Name:        "LocalNode",
@@ -59,6 +74,8 @@ var LocalNode = &Node{
 	PrivatePort: 8080,
 	SFTPPort:    5657,
 	Local:       true,
+
+	ConnectionMode: NodeConnectionDirect,
 }
 
 func init() {
@@ -15,6 +15,8 @@ type NodeView struct {
 	PrivatePort uint16 `json:"privatePort,omitempty"`
 	SFTPPort    uint16 `json:"sftpPort,omitempty"`
 	Local       bool   `json:"isLocal"`
+
+	ConnectionMode string `json:"connectionMode,omitempty"`
 } //@name Node
 
 type NodesView []*NodeView //@name Nodes
@@ -29,6 +31,8 @@ func FromNode(n *Node) *NodeView {
 		PrivatePort: n.PrivatePort,
 		SFTPPort:    n.SFTPPort,
 		Local:       n.IsLocal(),
+
+		ConnectionMode: n.ConnectionMode,
 	}
 }
 
@@ -66,11 +70,30 @@ func (n *NodeView) CopyToModel(newModel *Node) {
 	if n.SFTPPort > 0 {
 		newModel.SFTPPort = n.SFTPPort
 	}
+
+	if n.ConnectionMode != "" {
+		newModel.ConnectionMode = n.ConnectionMode
+	}
+
+	//reverse nodes are never dialed, so the private address only matters for display
+	if newModel.ConnectionMode == NodeConnectionReverse {
+		if newModel.PrivateHost == "" {
+			newModel.PrivateHost = newModel.PublicHost
+		}
+		if newModel.PrivatePort == 0 {
+			newModel.PrivatePort = newModel.PublicPort
+		}
+	}
 }
 
 func (n *NodeView) Valid(allowEmpty bool) error {
 	validate := validator.New()
 
+	if validate.Var(n.ConnectionMode, "omitempty,oneof=direct reverse") != nil {
+		return SkyPanel.ErrFieldNotValidOption("connectionMode", NodeConnectionDirect, NodeConnectionReverse)
+	}
+	reverse := n.ConnectionMode == NodeConnectionReverse
+
 	if !allowEmpty && validate.Var(n.Name, "required") != nil {
 		return SkyPanel.ErrFieldRequired("name")
 	}
@@ -92,7 +115,7 @@ func (n *NodeView) Valid(allowEmpty bool) error {
 		return SkyPanel.ErrFieldIsInvalidHost("publicHost")
 	}
 
-	if !allowEmpty && validate.Var(n.PrivateHost, "required") != nil {
+	if !allowEmpty && !reverse && validate.Var(n.PrivateHost, "required") != nil {
 		return SkyPanel.ErrFieldMustBePrintable("privateHost")
 	}
 
@@ -117,7 +140,7 @@ func (n *NodeView) Valid(allowEmpty bool) error {
 			return SkyPanel.ErrFieldNotBetween("publicPort", 1, 65535)
 		}
 
-		if validate.Var(n.PrivatePort, "min=1,max=65535") != nil {
+		if validate.Var(n.PrivatePort, "min=1,max=65535") != nil && !(reverse && n.PrivatePort == 0) {
 			return SkyPanel.ErrFieldNotBetween("privatePort", 1, 65535)
 		}
 
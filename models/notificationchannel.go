@@ -0,0 +1,102 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/notifications"
+	"gopkg.in/go-playground/validator.v9"
+	"gorm.io/gorm"
+)
+
+type NotificationChannel struct {
+	ID       uint    `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID   uint    `gorm:"column:user_id;not null;index" json:"-"`
+	ServerID *string `gorm:"column:server_id;size:20;index" json:"serverId,omitempty" validate:"omitempty,printascii"` // vacío para todos los servidores del usuario
+	Name     string  `gorm:"column:name;not null;size:100" json:"name" validate:"required,max=100"`
+	Type     string  `gorm:"column:type;not null;size:20" json:"type" validate:"required"`
+	Enabled  bool    `gorm:"column:enabled;not null" json:"enabled"`
+
+	RawSettings string                 `gorm:"column:settings;not null;size:4000" json:"-"`
+	Settings    map[string]interface{} `gorm:"-" json:"settings"`
+	RawEvents   string                 `gorm:"column:events;not null;size:1000;default:''" json:"-"`
+	Events      []string               `gorm:"-" json:"events"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+} //@name NotificationChannel
+
+func (n *NotificationChannel) IsValid() (err error) {
+	err = validator.New().Struct(n)
+	if err != nil {
+		return SkyPanel.GenerateValidationMessage(err)
+	}
+	if _, ok := notifications.Types[n.Type]; !ok {
+		options := make([]string, 0, len(notifications.Types))
+		for k := range notifications.Types {
+			options = append(options, k)
+		}
+		sort.Strings(options)
+		return SkyPanel.ErrFieldNotValidOption("type", options...)
+	}
+	for _, v := range n.Events {
+		if !isNotificationEvent(v) {
+			return SkyPanel.ErrFieldNotValidOption("events", notifications.Events...)
+		}
+	}
+	if _, err = notifications.New(n.Type, n.Settings); err != nil {
+		return SkyPanel.ErrInvalidNotificationSettings(err)
+	}
+	return
+}
+
+func (n *NotificationChannel) BeforeSave(*gorm.DB) error {
+	if n.ServerID != nil && *n.ServerID == "" {
+		n.ServerID = nil
+	}
+	if err := n.IsValid(); err != nil {
+		return err
+	}
+
+	settings, err := json.Marshal(n.Settings)
+	if err != nil {
+		return err
+	}
+	n.RawSettings = string(settings)
+	n.RawEvents = strings.Join(n.Events, ",")
+	return nil
+}
+
+func (n *NotificationChannel) AfterFind(*gorm.DB) error {
+	n.Settings = make(map[string]interface{})
+	if n.RawSettings != "" {
+		if err := json.Unmarshal([]byte(n.RawSettings), &n.Settings); err != nil {
+			return err
+		}
+	}
+	n.Events = make([]string, 0)
+	if n.RawEvents != "" {
+		n.Events = strings.Split(n.RawEvents, ",")
+	}
+	return nil
+}
+
+// Channel creates the sender for this channel
+func (n *NotificationChannel) Channel() (notifications.Channel, error) {
+	return notifications.New(n.Type, n.Settings)
+}
+
+func isNotificationEvent(event string) bool {
+	if event == "*" {
+		return true
+	}
+	for _, v := range notifications.Events {
+		if v == event {
+			return true
+		}
+	}
+	return false
+}
@@ -21,6 +21,8 @@ type Server struct {
 	Type string `gorm:"NOT NULL;default='generic'" json:"-" validate:"required,printascii"`
 	Icon string `gorm:"" json:"-"`
 
+	Monitored bool `gorm:"column:monitored;not null;default:false" json:"-"` // tiene un endpoint en Gatus
+
 	CreatedAt time.Time `json:"-"`
 	UpdatedAt time.Time `json:"-"`
 }
@@ -7,9 +7,10 @@ import (
 type ServerCreation struct {
 	SkyPanel.Server
 
-	NodeId uint     `json:"node"`
-	Users  []string `json:"users"`
-	Name   string   `json:"name"`
+	NodeId    uint     `json:"node"`
+	Users     []string `json:"users"`
+	Name      string   `json:"name"`
+	Monitored bool     `json:"monitored,omitempty"`
 } //@name CreatedServer
 
 type GetServerResponse struct {
@@ -30,3 +31,7 @@ type ServerWithName struct {
 	SkyPanel.Server
 	Name string `json:"name"`
 } //@name NamedServer
+
+type ServerMonitoring struct {
+	Enabled bool `json:"enabled"`
+} //@name ServerMonitoring
@@ -17,6 +17,7 @@ type ServerView struct {
 	Type         string           `json:"type"`
 	Icon         string           `json:"icon,omitempty"`
 	CanGetStatus bool             `json:"canGetStatus,omitempty"`
+	Monitored    bool             `json:"monitored,omitempty"`
 } //@name ServerInfo
 
 type ServerUserView struct {
@@ -33,6 +34,7 @@ func FromServer(server *Server) *ServerView {
 		Port:       server.Port,
 		Type:       server.Type,
 		Icon:       server.Icon,
+		Monitored:  server.Monitored,
 		Node:       FromNode(&server.Node),
 	}
 
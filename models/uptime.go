@@ -8,6 +8,15 @@ import (
 	"gorm.io/gorm"
 )
 
+// motivos de una caída, que se guardan en UptimeStatus.Cause
+const (
+	DowntimeCrash       = "crash"
+	DowntimeStopped     = "stopped"
+	DowntimeNodeOffline = "nodeOffline"
+	DowntimeMaintenance = "maintenance" //solo en informes, para lo que cae dentro de una ventana de mantenimiento
+	DowntimeUnknown     = "unknown"
+)
+
 type UptimeStatus struct {
 	ID        uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
 	ServerID  string    `gorm:"column:server_id;not null;size:20;index" json:"-" validate:"required,printascii"`
@@ -16,6 +25,7 @@ type UptimeStatus struct {
 	StartTime time.Time `gorm:"column:start_time;not null;index" json:"startTime"`
 	EndTime   *time.Time `gorm:"column:end_time;index" json:"endTime,omitempty"`
 	Duration  int64     `gorm:"column:duration;default:0" json:"duration"` // Duración en segundos
+	Cause     string    `gorm:"column:cause;size:20" json:"cause,omitempty"` // por qué estaba parado, vacío si no se sabe
 
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -33,3 +43,37 @@ func (u *UptimeStatus) BeforeSave(*gorm.DB) (err error) {
 	err = u.IsValid()
 	return
 }
+
+// UptimeIncident es un periodo en el que el servidor estuvo parado
+type UptimeIncident struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Duration int64     `json:"duration"` //seconds
+	Cause    string    `json:"cause"`
+} //@name UptimeIncident
+
+// UptimeReport es el informe de SLA de un servidor en un periodo, sin contar las ventanas de mantenimiento
+type UptimeReport struct {
+	ServerId           string           `json:"serverId"`
+	ServerName         string           `json:"serverName"`
+	NodeName           string           `json:"nodeName"`
+	From               time.Time        `json:"from"`
+	To                 time.Time        `json:"to"`
+	UptimeSeconds      int64            `json:"uptimeSeconds"`
+	DowntimeSeconds    int64            `json:"downtimeSeconds"`
+	MaintenanceSeconds int64            `json:"maintenanceSeconds"`
+	UptimePercent      float64          `json:"uptimePercent"`
+	Incidents          []UptimeIncident `json:"incidents"`
+} //@name UptimeReport
+
+// UptimeSummary junta los informes de varios servidores, de un nodo o de todo el panel
+type UptimeSummary struct {
+	From               time.Time       `json:"from"`
+	To                 time.Time       `json:"to"`
+	Period             string          `json:"period"`
+	UptimeSeconds      int64           `json:"uptimeSeconds"`
+	DowntimeSeconds    int64           `json:"downtimeSeconds"`
+	MaintenanceSeconds int64           `json:"maintenanceSeconds"`
+	UptimePercent      float64         `json:"uptimePercent"`
+	Servers            []*UptimeReport `json:"servers"`
+} //@name UptimeSummary
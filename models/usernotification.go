@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+)
+
+// UserNotification is an entry of a user's in-panel inbox
+type UserNotification struct {
+	ID         uint      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	UserID     uint      `gorm:"column:user_id;not null;index" json:"-"`
+	Event      string    `gorm:"column:event;not null;size:50" json:"event"`
+	Severity   string    `gorm:"column:severity;not null;size:20" json:"severity"`
+	Title      string    `gorm:"column:title;not null;size:200" json:"title"`
+	Message    string    `gorm:"column:message;not null;size:1000" json:"message"`
+	ServerID   string    `gorm:"column:server_id;size:20" json:"serverId,omitempty"`
+	ServerName string    `gorm:"column:server_name;size:100" json:"serverName,omitempty"`
+	Read       bool      `gorm:"column:is_read;not null;index" json:"read"`
+	CreatedAt  time.Time `gorm:"index" json:"createdAt"`
+} //@name UserNotification
+
+type UserNotificationSearch struct {
+	Unread    bool `form:"unread"`
+	PageLimit uint `form:"limit"`
+	Page      uint `form:"page"`
+} //@name UserNotificationSearch
+
+type UserNotificationSearchResponse struct {
+	Notifications []*UserNotification `json:"notifications"`
+	Unread        int64               `json:"unread"`
+	*SkyPanel.Metadata
+} //@name UserNotificationSearchResponse
+
+// NotificationPreferences maps an event to the ways the user gets it, inbox and/or email
+type NotificationPreferences map[string][]string //@name NotificationPreferences
@@ -0,0 +1,74 @@
+package notifications
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var ErrPrivateAddress = errors.New("notifications can not be sent to private, loopback or link-local addresses")
+
+// allowPrivate lets tests send to servers on loopback
+var allowPrivate = false
+
+// carrier-grade NAT, which net.IP does not count as private
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// client refuses to connect to internal addresses, also after a redirect or when a name resolves to one
+var client = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: refusePrivate,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isPrivate(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+func isPrivate(ip net.IP) bool {
+	if allowPrivate {
+		return false
+	}
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// checkUrl makes sure a channel url is http or https and does not point at an internal address
+// Names are checked when connecting, as what they resolve to can change
+func checkUrl(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("url must be http or https")
+	}
+	host := u.Hostname()
+	if host == "" {
+		return ErrUrlRequired
+	}
+	if ip := net.ParseIP(host); ip != nil && isPrivate(ip) {
+		return ErrPrivateAddress
+	}
+	if host == "localhost" && !allowPrivate {
+		return ErrPrivateAddress
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package notifications
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUrlRequired = errors.New("url is required")
+
+// Webhook posts the notification as JSON.
+// With a secret set, X-SkyPanel-Signature is the hex HMAC-SHA256 of "<timestamp>.<body>", keyed with the secret,
+// where the timestamp is sent in X-SkyPanel-Timestamp.
+type Webhook struct {
+	Url    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+}
+
+func (w *Webhook) Validate() error {
+	if w.Url == "" {
+		return ErrUrlRequired
+	}
+	return checkUrl(w.Url)
+}
+
+func (w *Webhook) Send(n *Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := map[string]string{
+		"X-SkyPanel-Event":     n.Event,
+		"X-SkyPanel-Timestamp": timestamp,
+	}
+	if w.Secret != "" {
+		headers["X-SkyPanel-Signature"] = "sha256=" + Sign(w.Secret, timestamp, data)
+	}
+	return post(w.Url, "application/json", data, headers)
+}
+
+// Sign computes the signature a webhook receiver should compare against
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type Discord struct {
+	Url string `json:"url"`
+}
+
+func (d *Discord) Validate() error {
+	if d.Url == "" {
+		return ErrUrlRequired
+	}
+	return checkUrl(d.Url)
+}
+
+func (d *Discord) Send(n *Notification) error {
+	type field struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Inline bool   `json:"inline"`
+	}
+	fields := make([]field, 0, len(n.Fields))
+	for _, v := range n.Fields {
+		fields = append(fields, field{Name: v.Name, Value: v.Value, Inline: true})
+	}
+
+	embed := map[string]interface{}{
+		"title":       n.Title,
+		"description": n.Message,
+		"color":       severityColor(n.Severity),
+		"fields":      fields,
+		"timestamp":   n.Time.Format(time.RFC3339),
+	}
+	return postJSON(d.Url, map[string]interface{}{"embeds": []interface{}{embed}}, nil)
+}
+
+type Slack struct {
+	Url string `json:"url"`
+}
+
+func (s *Slack) Validate() error {
+	if s.Url == "" {
+		return ErrUrlRequired
+	}
+	return checkUrl(s.Url)
+}
+
+func (s *Slack) Send(n *Notification) error {
+	type field struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+		Short bool   `json:"short"`
+	}
+	fields := make([]field, 0, len(n.Fields))
+	for _, v := range n.Fields {
+		fields = append(fields, field{Title: v.Name, Value: v.Value, Short: true})
+	}
+
+	attachment := map[string]interface{}{
+		"title":  n.Title,
+		"text":   n.Message,
+		"color":  fmt.Sprintf("#%06X", severityColor(n.Severity)),
+		"fields": fields,
+		"ts":     n.Time.Unix(),
+	}
+	return postJSON(s.Url, map[string]interface{}{"text": n.Title, "attachments": []interface{}{attachment}}, nil)
+}
+
+type Telegram struct {
+	Token  string `json:"token"`
+	ChatId string `json:"chatId"`
+	ApiUrl string `json:"apiUrl,omitempty"`
+}
+
+func (t *Telegram) Validate() error {
+	if t.Token == "" || t.ChatId == "" {
+		return errors.New("token and chatId are required")
+	}
+	if t.ApiUrl != "" {
+		return checkUrl(t.ApiUrl)
+	}
+	return nil
+}
+
+func (t *Telegram) Send(n *Notification) error {
+	apiUrl := t.ApiUrl
+	if apiUrl == "" {
+		apiUrl = "https://api.telegram.org"
+	}
+
+	text := &strings.Builder{}
+	text.WriteString("<b>" + escapeHTML(n.Title) + "</b>\n" + escapeHTML(n.Message))
+	for _, v := range n.Fields {
+		text.WriteString("\n<b>" + escapeHTML(v.Name) + ":</b> " + escapeHTML(v.Value))
+	}
+
+	body := map[string]interface{}{
+		"chat_id":    t.ChatId,
+		"text":       text.String(),
+		"parse_mode": "HTML",
+	}
+	return postJSON(strings.TrimSuffix(apiUrl, "/")+"/bot"+t.Token+"/sendMessage", body, nil)
+}
+
+type Ntfy struct {
+	Url   string `json:"url,omitempty"`
+	Topic string `json:"topic"`
+	Token string `json:"token,omitempty"`
+}
+
+func (t *Ntfy) Validate() error {
+	if t.Topic == "" {
+		return errors.New("topic is required")
+	}
+	if t.Url != "" {
+		return checkUrl(t.Url)
+	}
+	return nil
+}
+
+func (t *Ntfy) Send(n *Notification) error {
+	serverUrl := t.Url
+	if serverUrl == "" {
+		serverUrl = "https://ntfy.sh"
+	}
+
+	message := n.Message
+	for _, v := range n.Fields {
+		message += "\n" + v.Name + ": " + v.Value
+	}
+
+	priority := 3
+	switch n.Severity {
+	case SeverityWarning:
+		priority = 4
+	case SeverityCritical:
+		priority = 5
+	}
+
+	var headers map[string]string
+	if t.Token != "" {
+		headers = map[string]string{"Authorization": "Bearer " + t.Token}
+	}
+
+	body := map[string]interface{}{
+		"topic":    t.Topic,
+		"title":    n.Title,
+		"message":  message,
+		"priority": priority,
+		"tags":     []string{n.Event},
+	}
+	return postJSON(strings.TrimSuffix(serverUrl, "/"), body, headers)
+}
+
+type Email struct {
+	To string `json:"to"`
+}
+
+func (e *Email) Validate() error {
+	if e.To == "" {
+		return errors.New("to is required")
+	}
+	return nil
+}
+
+func (e *Email) Send(n *Notification) error {
+	if Mailer == nil {
+		return errors.New("email is not available")
+	}
+	return Mailer(e.To, n)
+}
+
+func severityColor(severity string) int {
+	switch severity {
+	case SeveritySuccess:
+		return 0x00FF00
+	case SeverityWarning:
+		return 0xFFA500
+	case SeverityCritical:
+		return 0xFF0000
+	default:
+		return 0x3498DB
+	}
+}
+
+func escapeHTML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,128 @@
+package notifications
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// allowLoopback lets a test send to an httptest server
+func allowLoopback(t *testing.T) {
+	allowPrivate = true
+	t.Cleanup(func() { allowPrivate = false })
+}
+
+func TestWebhookSignature(t *testing.T) {
+	allowLoopback(t)
+	var received *Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature := r.Header.Get("X-SkyPanel-Signature")
+		if want := "sha256=" + Sign("secret", r.Header.Get("X-SkyPanel-Timestamp"), body); signature != want {
+			t.Errorf("signature = %q, want %q", signature, want)
+		}
+		if event := r.Header.Get("X-SkyPanel-Event"); event != EventServerCrash {
+			t.Errorf("event header = %q, want %q", event, EventServerCrash)
+		}
+		received = &Notification{}
+		if err := json.Unmarshal(body, received); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	channel, err := New("webhook", map[string]interface{}{"url": server.URL, "secret": "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := &Notification{Event: EventServerCrash, Severity: SeverityCritical, Title: "crash", ServerId: "abc", Time: time.Now()}
+	if err = channel.Send(n); err != nil {
+		t.Fatal(err)
+	}
+	if received == nil || received.ServerId != "abc" || received.Title != "crash" {
+		t.Errorf("received = %+v", received)
+	}
+}
+
+func TestSendErrorStatus(t *testing.T) {
+	allowLoopback(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal secret", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	channel := &Slack{Url: server.URL}
+	err := channel.Send(&Notification{Title: "test", Time: time.Now()})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("error = %v, want status 401", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "internal secret") {
+		t.Errorf("error = %v, should not contain the response body", err)
+	}
+}
+
+func TestPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not reach a loopback server")
+	}))
+	defer server.Close()
+
+	//the dialer refuses it even when the url was never validated
+	if err := (&Slack{Url: server.URL}).Send(&Notification{Title: "test", Time: time.Now()}); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("error = %v, want %v", err, ErrPrivateAddress)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		channelType string
+		settings    map[string]interface{}
+		wantErr     bool
+	}{
+		{channelType: "discord", settings: map[string]interface{}{"url": "https://example.com"}},
+		{channelType: "discord", settings: map[string]interface{}{}, wantErr: true},
+		{channelType: "discord", settings: map[string]interface{}{"url": "ftp://example.com"}, wantErr: true},
+		{channelType: "webhook", settings: map[string]interface{}{"url": "http://127.0.0.1:8080/api"}, wantErr: true},
+		{channelType: "webhook", settings: map[string]interface{}{"url": "http://169.254.169.254/latest/meta-data"}, wantErr: true},
+		{channelType: "webhook", settings: map[string]interface{}{"url": "http://[::1]/"}, wantErr: true},
+		{channelType: "webhook", settings: map[string]interface{}{"url": "http://10.0.0.5/hook"}, wantErr: true},
+		{channelType: "ntfy", settings: map[string]interface{}{"topic": "panel", "url": "http://localhost:2586"}, wantErr: true},
+		{channelType: "telegram", settings: map[string]interface{}{"token": "t", "chatId": "1", "apiUrl": "http://192.168.1.1"}, wantErr: true},
+		{channelType: "telegram", settings: map[string]interface{}{"token": "t", "chatId": "1"}},
+		{channelType: "telegram", settings: map[string]interface{}{"token": "t"}, wantErr: true},
+		{channelType: "ntfy", settings: map[string]interface{}{"topic": "panel"}},
+		{channelType: "email", settings: map[string]interface{}{"to": "a@example.com"}},
+		{channelType: "pigeon", settings: map[string]interface{}{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.channelType, tt.settings); (err != nil) != tt.wantErr {
+			t.Errorf("New(%s, %v) error = %v, wantErr %v", tt.channelType, tt.settings, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSubscribed(t *testing.T) {
+	tests := []struct {
+		events []string
+		event  string
+		want   bool
+	}{
+		{events: nil, event: EventServerCrash, want: true},
+		{events: []string{"*"}, event: EventBackupFailed, want: true},
+		{events: []string{EventServerCrash}, event: EventServerCrash, want: true},
+		{events: []string{EventServerCrash}, event: EventBackupFailed, want: false},
+		{events: []string{EventServerCrash}, event: EventTest, want: true},
+	}
+	for _, tt := range tests {
+		if got := Subscribed(tt.events, tt.event); got != tt.want {
+			t.Errorf("Subscribed(%v, %s) = %v, want %v", tt.events, tt.event, got, tt.want)
+		}
+	}
+}
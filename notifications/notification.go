@@ -0,0 +1,153 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Events a channel can subscribe to
+const (
+	EventServerOnline  = "server.online"
+	EventServerOffline = "server.offline"
+	EventServerCrash   = "server.crash"
+	EventServerHung    = "server.unhealthy"
+	EventBackupSuccess = "backup.success"
+	EventBackupFailed  = "backup.failed"
+	EventAlertFiring   = "alert.firing"
+	EventAlertResolved = "alert.resolved"
+	EventDiskWarning   = "disk.warning"
+	EventNodeOffline   = "node.offline"
+	EventLoginNewIp    = "login.newip"
+	EventTest          = "test"
+)
+
+var Events = []string{
+	EventServerOnline, EventServerOffline, EventServerCrash, EventServerHung,
+	EventBackupSuccess, EventBackupFailed,
+	EventAlertFiring, EventAlertResolved,
+	EventDiskWarning,
+	EventNodeOffline,
+	EventLoginNewIp,
+}
+
+// Severities of a notification, they match the severities of alert rules
+const (
+	SeverityInfo     = "info"
+	SeveritySuccess  = "success"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+var ErrUnknownChannelType = errors.New("unknown notification channel type")
+
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Notification struct {
+	Event      string    `json:"event"`
+	Severity   string    `json:"severity"`
+	Title      string    `json:"title"`
+	Message    string    `json:"message"`
+	ServerId   string    `json:"serverId,omitempty"`
+	ServerName string    `json:"serverName,omitempty"`
+	Fields     []Field   `json:"fields,omitempty"`
+	Time       time.Time `json:"time"`
+	UserId     uint      `json:"-"` // only this user gets it, for events about their own account
+}
+
+// Channel delivers notifications somewhere
+type Channel interface {
+	Send(n *Notification) error
+}
+
+// Validator is implemented by channels which can tell if their settings are usable
+type Validator interface {
+	Validate() error
+}
+
+// Mailer sends the email channel's notifications, the panel sets it to its email service
+var Mailer func(to string, n *Notification) error
+
+// Types maps every channel type to a function returning empty settings for it
+var Types = map[string]func() Channel{
+	"webhook":  func() Channel { return &Webhook{} },
+	"discord":  func() Channel { return &Discord{} },
+	"slack":    func() Channel { return &Slack{} },
+	"telegram": func() Channel { return &Telegram{} },
+	"ntfy":     func() Channel { return &Ntfy{} },
+	"email":    func() Channel { return &Email{} },
+}
+
+// New creates a channel of the given type from its settings
+func New(channelType string, settings map[string]interface{}) (Channel, error) {
+	factory, ok := Types[channelType]
+	if !ok {
+		return nil, ErrUnknownChannelType
+	}
+	channel := factory()
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, channel); err != nil {
+		return nil, err
+	}
+
+	if v, ok := channel.(Validator); ok {
+		if err = v.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return channel, nil
+}
+
+// Subscribed checks if a notification's event is in a subscription list, an empty list takes every event
+func Subscribed(events []string, event string) bool {
+	if len(events) == 0 || event == EventTest {
+		return true
+	}
+	for _, v := range events {
+		if v == event || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func postJSON(url string, body interface{}, headers map[string]string) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return post(url, "application/json", data, headers)
+}
+
+func post(url, contentType string, data []byte, headers map[string]string) error {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	//the body is not reported, as whoever set the url can read the error
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("notification to %s returned status %d", request.URL.Host, response.StatusCode)
+	}
+	return nil
+}
@@ -1,5 +1,7 @@
 package SkyPanel
 
+import "context"
+
 type Operation interface {
 	Run(args RunOperatorArgs) OperationResult
 }
@@ -19,6 +21,15 @@ type CreateOperation struct {
 type RunOperatorArgs struct {
 	Environment *Environment
 	Server      DaemonServer
+	Context     context.Context //cancelled when the operation times out or the install is cancelled
+}
+
+// GetContext returns the context of the run, operations run outside a process get one which is never cancelled
+func (a RunOperatorArgs) GetContext() context.Context {
+	if a.Context == nil {
+		return context.Background()
+	}
+	return a.Context
 }
 
 type OperationResult struct {
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/SkyPanel/SkyPanel/v3"
@@ -16,6 +17,7 @@ type Command struct {
 
 func (c Command) Run(args SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
 	env := args.Environment
+	ctx := args.GetContext()
 
 	for _, cmd := range c.Commands {
 		logging.Info.Printf("Executing command: %s", cmd)
@@ -37,7 +39,12 @@ func (c Command) Run(args SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
 		if err != nil {
 			return SkyPanel.OperationResult{Error: err}
 		}
-		err = <-ch
+		//the process itself is killed by whoever cancelled the context
+		select {
+		case err = <-ch:
+		case <-ctx.Done():
+			err = context.Cause(ctx)
+		}
 		if err != nil {
 			return SkyPanel.OperationResult{Error: err}
 		}
@@ -81,6 +81,35 @@ func getLatestFiles(projectId uint) ([]File, error) {
 	return addon.Data.LatestFiles, err
 }
 
+// GetFiles gets the files of a project, newest first, only the ones for the given game version if it is set
+func GetFiles(projectId uint, gameVersion string) ([]File, error) {
+	u := fmt.Sprintf("https://api.curseforge.com/v1/mods/%d/files", projectId)
+	if gameVersion != "" {
+		u += "?gameVersion=" + url.QueryEscape(gameVersion)
+	}
+
+	response, err := callCurseForge(u)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.CloseResponse(response)
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, SkyPanel.ErrCurseForgeFile(projectId, 0)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, SkyPanel.ErrCurseForgeStatus(response.Status)
+	}
+
+	var res FilesResponse
+	err = json.NewDecoder(response.Body).Decode(&res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
 func getFileById(projectId uint, fileId uint) (File, error) {
 	addon, addonErr := getAddonData(projectId)
 
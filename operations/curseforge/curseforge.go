@@ -223,28 +223,14 @@ func (c CurseForge) Run(args SkyPanel.RunOperatorArgs) SkyPanel.OperationResult
 				jarFile = installerJar
 			}
 
-			err = installViaJar(args.Server, env, jarFile, c.JavaBinary)
+			err = InstallViaJar(args.Server, env, jarFile, c.JavaBinary)
 			if err != nil {
 				return SkyPanel.OperationResult{Error: err}
 			}
 
-			//grab the ServerStarter if there isn't a server.jar, just to help out
-			//would prefer Forge's variant, but this will do
-			runJarFile := filepath.Join(env.GetRootDirectory(), "server.jar")
-			if _, err = os.Stat(runJarFile); os.IsNotExist(err) {
-				env.DisplayToConsole(true, "Grabbing ServerStarter")
-				var cachePath = filepath.Join(config.CacheFolder.Value(), "github.com", "neoforgedl", "serverstarter", NeoForgeServerStarterVersion, "server.jar")
-				if _, err = os.Stat(cachePath); os.IsNotExist(err) {
-					env.DisplayToConsole(true, "Downloading "+NeoForgeServerStarter)
-					err = SkyPanel.DownloadFileToCache(NeoForgeServerStarter, cachePath)
-					if err != nil {
-						return SkyPanel.OperationResult{Error: err}
-					}
-				}
-				err = files.CopyFile(cachePath, runJarFile)
-				if err != nil {
-					return SkyPanel.OperationResult{Error: err}
-				}
+			err = InstallServerStarter(env)
+			if err != nil {
+				return SkyPanel.OperationResult{Error: err}
 			}
 		}
 	default:
@@ -276,7 +262,8 @@ func findInstallerJar(env *SkyPanel.Environment) (string, error) {
 	return "", os.ErrNotExist
 }
 
-func installViaJar(server SkyPanel.DaemonServer, env *SkyPanel.Environment, jarFile string, javaBinary string) error {
+// InstallViaJar runs a forge or neoforge installer that is in the server root, then removes it
+func InstallViaJar(server SkyPanel.DaemonServer, env *SkyPanel.Environment, jarFile string, javaBinary string) error {
 	//installer found, we will run this one
 	result := make(chan int, 1)
 	err := env.Execute(SkyPanel.ExecutionData{
@@ -328,6 +315,26 @@ func installViaJar(server SkyPanel.DaemonServer, env *SkyPanel.Environment, jarF
 	return nil
 }
 
+// InstallServerStarter grabs the ServerStarter if there isn't a server.jar, just to help out
+// would prefer Forge's variant, but this will do
+func InstallServerStarter(env *SkyPanel.Environment) error {
+	runJarFile := filepath.Join(env.GetRootDirectory(), "server.jar")
+	if _, err := os.Stat(runJarFile); !os.IsNotExist(err) {
+		return nil
+	}
+
+	env.DisplayToConsole(true, "Grabbing ServerStarter")
+	var cachePath = filepath.Join(config.CacheFolder.Value(), "github.com", "neoforgedl", "serverstarter", NeoForgeServerStarterVersion, "server.jar")
+	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
+		env.DisplayToConsole(true, "Downloading "+NeoForgeServerStarter)
+		err = SkyPanel.DownloadFileToCache(NeoForgeServerStarter, cachePath)
+		if err != nil {
+			return err
+		}
+	}
+	return files.CopyFile(cachePath, runJarFile)
+}
+
 func installFabric(env *SkyPanel.Environment, data map[string]string, javaBinary string) error {
 	//this is a mess
 	//there's 2 options that exist for fabric
@@ -354,37 +361,40 @@ func installFabric(env *SkyPanel.Environment, data map[string]string, javaBinary
 			return err
 		}
 
-		//forge installer found, we will run this one
-		result := make(chan int, 1)
-		err = env.Execute(SkyPanel.ExecutionData{
-			Command: fmt.Sprintf("%s -jar fabric-installer server -mcversion %s -loader %s -downloadMinecraft", javaBinary, data["mcVersion"], data["version"]),
-			Callback: func(exitCode int) {
-				result <- exitCode
-				env.DisplayToConsole(true, "Installer exit code: %d", exitCode)
-			},
-		})
-		if err != nil {
-			return err
-		}
-		if <-result != 0 {
-			return errors.New("failed to run fabric installer")
-		}
-
-		//delete installer now
-		err = os.Remove(filepath.Join(env.GetRootDirectory(), "fabric-installer.jar"))
-		if err != nil {
-			env.DisplayToConsole(true, "Failed to delete installer")
-		}
-
-		//replace jar with the fabric jar
-		_ = os.Remove(filepath.Join(env.GetRootDirectory(), "server.jar"))
-		err = os.Rename(filepath.Join(env.GetRootDirectory(), "fabric-server-launch.jar"), filepath.Join(env.GetRootDirectory(), "server.jar"))
-		return err
+		return RunFabricInstaller(env, data["mcVersion"], data["version"], javaBinary)
 	} else {
 		return err
 	}
 }
 
+// RunFabricInstaller runs the fabric-installer.jar that is in the server root and makes the fabric launcher the server.jar
+func RunFabricInstaller(env *SkyPanel.Environment, mcVersion, loaderVersion, javaBinary string) error {
+	result := make(chan int, 1)
+	err := env.Execute(SkyPanel.ExecutionData{
+		Command: fmt.Sprintf("%s -jar fabric-installer.jar server -mcversion %s -loader %s -downloadMinecraft", javaBinary, mcVersion, loaderVersion),
+		Callback: func(exitCode int) {
+			result <- exitCode
+			env.DisplayToConsole(true, "Installer exit code: %d", exitCode)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if <-result != 0 {
+		return errors.New("failed to run fabric installer")
+	}
+
+	//delete installer now
+	err = os.Remove(filepath.Join(env.GetRootDirectory(), "fabric-installer.jar"))
+	if err != nil {
+		env.DisplayToConsole(true, "Failed to delete installer")
+	}
+
+	//replace jar with the fabric jar
+	_ = os.Remove(filepath.Join(env.GetRootDirectory(), "server.jar"))
+	return os.Rename(filepath.Join(env.GetRootDirectory(), "fabric-server-launch.jar"), filepath.Join(env.GetRootDirectory(), "server.jar"))
+}
+
 func downloadFile(url, target string) error {
 	file, err := os.Create(target)
 	if err != nil {
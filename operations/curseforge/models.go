@@ -28,6 +28,22 @@ type File struct {
 	IsServerPack        bool
 	ServerPackFileId    uint
 	ParentProjectFileId uint
+	Hashes              []FileHash
+}
+
+type FileHash struct {
+	Value string
+	Algo  int
+}
+
+// Sha1 gets the sha1 hash of the file, CurseForge gives it as algo 1
+func (f File) Sha1() string {
+	for _, v := range f.Hashes {
+		if v.Algo == 1 {
+			return v.Value
+		}
+	}
+	return ""
 }
 
 type Category struct {
@@ -1,25 +1,309 @@
 package download
 
 import (
-	"github.com/cavaliergopher/grab/v3"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
 	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
 	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+	"github.com/cavaliergopher/grab/v3"
 )
 
+// time waited before each round of retries, multiplied by the round
+var retryDelay = time.Second
+
+// how often the bytes downloaded are reported while a file downloads
+const progressInterval = 500 * time.Millisecond
+
 type Download struct {
-	Files []string
+	Files   []File
+	Retries int
+}
+
+type File struct {
+	Url     string
+	Mirrors []string
+	Target  string
+	Sha256  string
+	Sha1    string
+	Cache   bool
 }
 
 func (d Download) Run(args SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
 	env := args.Environment
+	ctx := args.GetContext()
 
 	for _, file := range d.Files {
-		logging.Info.Printf("Download file from %s to %s", file, env.GetRootDirectory())
-		env.DisplayToConsole(true, "Downloading file %s\n", file)
-		_, err := grab.Get(env.GetRootDirectory(), file)
-		if err != nil {
+		logging.Info.Printf("Download file from %s to %s", file.Url, env.GetRootDirectory())
+		env.DisplayToConsole(true, "Downloading file %s\n", file.Url)
+		if err := d.download(ctx, env, file); err != nil {
 			return SkyPanel.OperationResult{Error: err}
 		}
 	}
 	return SkyPanel.OperationResult{Error: nil}
 }
+
+func (d Download) download(ctx context.Context, env *SkyPanel.Environment, file File) error {
+	target, err := file.target()
+	if err != nil {
+		return err
+	}
+	root := env.GetRootDirectory()
+
+	//without a hash there is no way to tell if a cached copy is still the right file
+	if !file.Cache || !file.hasHash() {
+		if file.Cache {
+			logging.Info.Printf("Not caching %s as it has no sha256 or sha1", file.Url)
+		}
+		part := filepath.Join(root, filepath.FromSlash(file.partName(target)))
+		if err = os.MkdirAll(filepath.Dir(part), 0755); err != nil {
+			return err
+		}
+		name, err := d.fetch(ctx, env, file, part)
+		if err != nil {
+			return err
+		}
+		if target == "" {
+			target = name
+		}
+		return os.Rename(part, filepath.Join(root, filepath.FromSlash(target)))
+	}
+
+	cachePath := filepath.Join(config.CacheFolder.Value(), "downloads", file.cacheKey())
+	if file.verify(cachePath) == nil {
+		logging.Info.Printf("Using cached copy of file: %s\n", file.Url)
+	} else {
+		if err = os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+			return err
+		}
+		name, err := d.fetch(ctx, env, file, cachePath+".part")
+		if err != nil {
+			return err
+		}
+		if err = os.Rename(cachePath+".part", cachePath); err != nil {
+			return err
+		}
+		//the cache is named by the hash, so the name the server gave is kept next to it
+		_ = os.WriteFile(cachePath+".name", []byte(name), 0644)
+	}
+
+	if target == "" {
+		if target, err = file.cachedName(cachePath); err != nil {
+			return err
+		}
+	}
+	targetPath := filepath.Join(root, filepath.FromSlash(target))
+	if err = os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return err
+	}
+	return copyFile(cachePath, targetPath)
+}
+
+// fetch downloads into the part file, trying each mirror in turn and retrying them all if none works
+// The part file is resumed between attempts, and only kept once its hashes match
+// It returns the name the server gave the file
+func (d Download) fetch(ctx context.Context, env *SkyPanel.Environment, file File, part string) (string, error) {
+	if !file.hasHash() {
+		//a leftover from another run cannot be checked, so it cannot be resumed either
+		_ = os.Remove(part)
+	}
+
+	urls := append([]string{file.Url}, file.Mirrors...)
+	var err error
+	for attempt := 0; attempt <= d.Retries; attempt++ {
+		if attempt > 0 {
+			env.DisplayToConsole(true, "Retrying download of %s (%s)\n", file.Url, err.Error())
+			select {
+			case <-time.After(retryDelay * time.Duration(attempt)):
+			case <-ctx.Done():
+				return "", context.Cause(ctx)
+			}
+		}
+
+		for _, u := range urls {
+			var name string
+			if name, err = get(ctx, env, u, part); err != nil {
+				if ctx.Err() != nil {
+					return "", context.Cause(ctx)
+				}
+				logging.Info.Printf("Failed to download %s: %s", u, err.Error())
+				continue
+			}
+			if err = file.verify(part); err != nil {
+				logging.Info.Printf("Downloaded file from %s does not match: %s", u, err.Error())
+				_ = os.Remove(part)
+				continue
+			}
+			return name, nil
+		}
+	}
+	return "", err
+}
+
+// get downloads u into target and returns the name the server gave the file
+func get(ctx context.Context, env *SkyPanel.Environment, u, target string) (string, error) {
+	client := grab.NewClient()
+	client.HTTPClient = SkyPanel.Http()
+
+	request, err := grab.NewRequest(target, u)
+	if err != nil {
+		return "", err
+	}
+	request = request.WithContext(ctx)
+	response := client.Do(request)
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for waiting := true; waiting; {
+		select {
+		case <-ticker.C:
+		case <-response.Done:
+			waiting = false
+		}
+		//grab gives -1 until the server says how big the file is
+		env.UpdateProgress(response.BytesComplete(), max(response.Size(), 0))
+	}
+
+	if err = response.Err(); err != nil {
+		return "", err
+	}
+	if response.DidResume {
+		logging.Debug.Printf("Resumed download of %s", u)
+	}
+	return fileName(response.HTTPResponse, u)
+}
+
+// fileName works out the name of a download like grab does, from the Content-Disposition header first and the url otherwise
+func fileName(response *http.Response, u string) (string, error) {
+	name := ""
+	if response != nil {
+		if _, params, err := mime.ParseMediaType(response.Header.Get("Content-Disposition")); err == nil {
+			name = params["filename"]
+		}
+		if name == "" && response.Request != nil {
+			name = path.Base(response.Request.URL.Path)
+		}
+	}
+	if name == "" {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return "", err
+		}
+		name = path.Base(parsed.Path)
+	}
+	return cleanName(name, u)
+}
+
+// cleanName keeps only the last part of a name, so a header cannot point outside the server
+func cleanName(name, u string) (string, error) {
+	name = filepath.Base(filepath.FromSlash(name))
+	if name == "." || name == ".." || name == string(filepath.Separator) {
+		return "", errors.New("cannot tell the file name of " + u + ", a target is needed")
+	}
+	return name, nil
+}
+
+// target is where the file goes in the server, empty when the name comes from the server
+func (f File) target() (string, error) {
+	if f.Target == "" {
+		return "", nil
+	}
+	if !filepath.IsLocal(filepath.FromSlash(f.Target)) {
+		return "", SkyPanel.ErrUnsafePath(f.Target)
+	}
+	return f.Target, nil
+}
+
+// partName is where the file is downloaded to, the name in the url stands in until the server gives the real one
+func (f File) partName(target string) string {
+	if target != "" {
+		return target + ".part"
+	}
+	if name, err := fileName(nil, f.Url); err == nil {
+		return name + ".part"
+	}
+	return "download.part"
+}
+
+// cachedName is the name the server gave a cached file, the name in the url for files cached before it was kept
+func (f File) cachedName(cachePath string) (string, error) {
+	if data, err := os.ReadFile(cachePath + ".name"); err == nil {
+		return cleanName(string(data), f.Url)
+	}
+	return fileName(nil, f.Url)
+}
+
+func (f File) hasHash() bool {
+	return f.Sha256 != "" || f.Sha1 != ""
+}
+
+func (f File) cacheKey() string {
+	if f.Sha256 != "" {
+		return filepath.Join("sha256", f.Sha256)
+	}
+	return filepath.Join("sha1", f.Sha1)
+}
+
+// verify checks the file against every hash that was given
+func (f File) verify(file string) error {
+	if !f.hasHash() {
+		_, err := os.Stat(file)
+		return err
+	}
+
+	in, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(in)
+
+	sha256Hash, sha1Hash := sha256.New(), sha1.New()
+	if _, err = io.Copy(io.MultiWriter(sha256Hash, sha1Hash), in); err != nil {
+		return err
+	}
+
+	for _, v := range []struct {
+		expected string
+		hash     hash.Hash
+	}{{f.Sha256, sha256Hash}, {f.Sha1, sha1Hash}} {
+		if actual := hex.EncodeToString(v.hash.Sum(nil)); v.expected != "" && v.expected != actual {
+			return SkyPanel.ErrHashMismatch(f.Url, v.expected, actual)
+		}
+	}
+	return nil
+}
+
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(in)
+
+	part := target + ".part"
+	out, err := os.Create(part)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	utils.Close(out)
+	if err != nil {
+		_ = os.Remove(part)
+		return err
+	}
+	return os.Rename(part, target)
+}
@@ -0,0 +1,188 @@
+package download
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownload(t *testing.T) {
+	retryDelay = 0
+	_ = config.CacheFolder.Set(t.TempDir(), false)
+
+	data := bytes.Repeat([]byte("server jar "), 1000)
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	var ranged atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/broken/server.jar":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/tampered/server.jar":
+			_, _ = w.Write([]byte("tampered"))
+		case "/download":
+			w.Header().Set("Content-Disposition", `attachment; filename="`+r.URL.Query().Get("name")+`"`)
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+		default:
+			if r.Method == "GET" && r.Header.Get("Range") != "" {
+				ranged.Store(true)
+			}
+			http.ServeContent(w, r, "server.jar", time.Time{}, bytes.NewReader(data))
+		}
+	}))
+	defer server.Close()
+
+	run := func(d Download) (string, error) {
+		root := t.TempDir()
+		env := &SkyPanel.Environment{
+			RootDirectory:  root,
+			ConsoleBuffer:  SkyPanel.CreateCache(),
+			ConsoleTracker: SkyPanel.CreateTracker(),
+		}
+		return root, d.Run(SkyPanel.RunOperatorArgs{Environment: env}).Error
+	}
+
+	t.Run("HashMismatchFails", func(t *testing.T) {
+		root, err := run(Download{Files: []File{{Url: server.URL + "/tampered/server.jar", Sha256: hash}}, Retries: 1})
+		assert.Equal(t, "ErrHashMismatch", SkyPanel.FromError(err).GetCode())
+		assert.NoFileExists(t, filepath.Join(root, "server.jar"))
+		assert.NoFileExists(t, filepath.Join(root, "server.jar.part"))
+	})
+
+	t.Run("UsesMirrors", func(t *testing.T) {
+		root, err := run(Download{Files: []File{{
+			Url:     server.URL + "/broken/server.jar",
+			Mirrors: []string{server.URL + "/tampered/server.jar", server.URL + "/good/server.jar"},
+			Target:  "bin/server.jar",
+			Sha256:  hash,
+		}}})
+		if !assert.NoError(t, err) {
+			return
+		}
+		content, _ := os.ReadFile(filepath.Join(root, "bin", "server.jar"))
+		assert.Equal(t, data, content)
+	})
+
+	t.Run("Resumes", func(t *testing.T) {
+		root := t.TempDir()
+		_ = os.WriteFile(filepath.Join(root, "server.jar.part"), data[:len(data)/2], 0644)
+
+		env := &SkyPanel.Environment{RootDirectory: root, ConsoleBuffer: SkyPanel.CreateCache(), ConsoleTracker: SkyPanel.CreateTracker()}
+		d := Download{Files: []File{{Url: server.URL + "/good/server.jar", Sha256: hash}}}
+		if !assert.NoError(t, d.Run(SkyPanel.RunOperatorArgs{Environment: env}).Error) {
+			return
+		}
+		assert.True(t, ranged.Load())
+		content, _ := os.ReadFile(filepath.Join(root, "server.jar"))
+		assert.Equal(t, data, content)
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		file := File{Url: server.URL + "/good/server.jar", Sha256: hash, Cache: true}
+		_, err := run(Download{Files: []File{file}})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		//the cached copy is used even if the url is gone
+		file.Url = server.URL + "/broken/server.jar"
+		root, err := run(Download{Files: []File{file}, Retries: 0})
+		if !assert.NoError(t, err) {
+			return
+		}
+		content, _ := os.ReadFile(filepath.Join(root, "server.jar"))
+		assert.Equal(t, data, content)
+	})
+
+	t.Run("UsesContentDisposition", func(t *testing.T) {
+		root, err := run(Download{Files: []File{{Url: server.URL + "/download?name=plugin.jar"}}})
+		if !assert.NoError(t, err) {
+			return
+		}
+		content, _ := os.ReadFile(filepath.Join(root, "plugin.jar"))
+		assert.Equal(t, data, content)
+		assert.NoFileExists(t, filepath.Join(root, "download"))
+		assert.NoFileExists(t, filepath.Join(root, "download.part"))
+
+		//only the name is used, the header cannot point outside the server
+		root, err = run(Download{Files: []File{{Url: server.URL + "/download?name=../escape.jar"}}})
+		if assert.NoError(t, err) {
+			assert.FileExists(t, filepath.Join(root, "escape.jar"))
+		}
+
+		//a cached copy keeps the name the server gave
+		_ = config.CacheFolder.Set(t.TempDir(), false)
+		file := File{Url: server.URL + "/download?name=cached.jar", Sha256: hash, Cache: true}
+		if _, err = run(Download{Files: []File{file}}); !assert.NoError(t, err) {
+			return
+		}
+		file.Url = server.URL + "/broken/server.jar?name=cached.jar"
+		root, err = run(Download{Files: []File{file}})
+		if assert.NoError(t, err) {
+			assert.FileExists(t, filepath.Join(root, "cached.jar"))
+		}
+	})
+
+	t.Run("RejectsUnsafeTarget", func(t *testing.T) {
+		_, err := run(Download{Files: []File{{Url: server.URL + "/good/server.jar", Target: "../server.jar"}}})
+		assert.Equal(t, "ErrUnsafePath", SkyPanel.FromError(err).GetCode())
+	})
+}
+
+func TestCreate(t *testing.T) {
+	op, err := Factory.Create(SkyPanel.CreateOperation{OperationArgs: map[string]interface{}{
+		"files": []interface{}{
+			"https://example.com/a.jar",
+			map[string]interface{}{
+				"url":     "https://example.com/b.jar",
+				"mirrors": []interface{}{"https://mirror.example.com/b.jar"},
+				"target":  "libs/b.jar",
+				"sha1":    "A94A8FE5CCB19BA61C4C0873D391E987982FBBD3",
+				"cache":   true,
+			},
+		},
+		"retries": 1,
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	d := op.(*Download)
+	assert.Equal(t, 1, d.Retries)
+	assert.Equal(t, File{Url: "https://example.com/a.jar"}, d.Files[0])
+	assert.Equal(t, File{
+		Url:     "https://example.com/b.jar",
+		Mirrors: []string{"https://mirror.example.com/b.jar"},
+		Target:  "libs/b.jar",
+		Sha1:    "a94a8fe5ccb19ba61c4c0873d391e987982fbbd3",
+		Cache:   true,
+	}, d.Files[1])
+
+	_, err = Factory.Create(SkyPanel.CreateOperation{OperationArgs: map[string]interface{}{
+		"files": []interface{}{map[string]interface{}{"target": "a.jar"}},
+	}})
+	assert.Error(t, err)
+
+	for _, hash := range []map[string]interface{}{{"sha256": "../../escape"}, {"sha1": "abc"}, {"sha1": "g94a8fe5ccb19ba61c4c0873d391e987982fbbd3"}} {
+		args := map[string]interface{}{"url": "https://example.com/a.jar"}
+		for k, v := range hash {
+			args[k] = v
+		}
+		_, err = Factory.Create(SkyPanel.CreateOperation{OperationArgs: map[string]interface{}{"files": []interface{}{args}}})
+		if assert.Error(t, err) {
+			assert.Equal(t, "ErrInvalidHash", SkyPanel.FromError(err).GetCode())
+		}
+	}
+}
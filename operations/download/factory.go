@@ -1,7 +1,13 @@
 package download
 
 import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"strings"
+
 	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
 	"github.com/spf13/cast"
 )
 
@@ -10,8 +16,49 @@ type OperationFactory struct {
 }
 
 func (of OperationFactory) Create(op SkyPanel.CreateOperation) (SkyPanel.Operation, error) {
-	files := cast.ToStringSlice(op.OperationArgs["files"])
-	return &Download{Files: files}, nil
+	var entries []interface{}
+	if v, ok := op.OperationArgs["files"].(string); ok {
+		entries = cast.ToSlice(cast.ToStringSlice(v))
+	} else {
+		entries = cast.ToSlice(op.OperationArgs["files"])
+	}
+
+	//files can be plain urls, or objects with the url and how to check it
+	files := make([]File, 0, len(entries))
+	for _, v := range entries {
+		if u, ok := v.(string); ok {
+			files = append(files, File{Url: u})
+			continue
+		}
+
+		data := cast.ToStringMap(v)
+		file := File{
+			Url:     cast.ToString(data["url"]),
+			Mirrors: cast.ToStringSlice(data["mirrors"]),
+			Target:  cast.ToString(data["target"]),
+			Sha256:  strings.ToLower(cast.ToString(data["sha256"])),
+			Sha1:    strings.ToLower(cast.ToString(data["sha1"])),
+			Cache:   cast.ToBool(data["cache"]),
+		}
+		if file.Url == "" {
+			return nil, errors.New("missing url")
+		}
+		//the hashes name the file in the cache, so they have to be plain hex
+		if file.Sha256 != "" && !utils.IsHex(file.Sha256, sha256.Size*2) {
+			return nil, SkyPanel.ErrInvalidHash(file.Url, file.Sha256)
+		}
+		if file.Sha1 != "" && !utils.IsHex(file.Sha1, sha1.Size*2) {
+			return nil, SkyPanel.ErrInvalidHash(file.Url, file.Sha1)
+		}
+		files = append(files, file)
+	}
+
+	retries := 3
+	if v, ok := op.OperationArgs["retries"]; ok {
+		retries = max(cast.ToInt(v), 0)
+	}
+
+	return &Download{Files: files, Retries: retries}, nil
 }
 
 func (of OperationFactory) Key() string {
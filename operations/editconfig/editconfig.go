@@ -0,0 +1,30 @@
+package editconfig
+
+import (
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/configfile"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+)
+
+type EditConfig struct {
+	File   string
+	Format string
+	Edit   configfile.Edit
+}
+
+func (c EditConfig) Run(args SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
+	env := args.Environment
+	fs := args.Server.GetFileServer()
+
+	logging.Info.Printf("Editing config file: %s", c.File)
+	env.DisplayToConsole(true, "Editing config file: %s\n", c.File)
+
+	doc, _, err := configfile.Read(fs, c.File, c.Format)
+	if err != nil {
+		return SkyPanel.OperationResult{Error: err}
+	}
+	if err = c.Edit.Apply(doc); err != nil {
+		return SkyPanel.OperationResult{Error: err}
+	}
+	return SkyPanel.OperationResult{Error: configfile.Write(fs, c.File, doc)}
+}
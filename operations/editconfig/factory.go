@@ -0,0 +1,67 @@
+package editconfig
+
+import (
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/configfile"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+	"github.com/spf13/cast"
+)
+
+type OperationFactory struct {
+	SkyPanel.OperationFactory
+}
+
+func (of OperationFactory) Create(op SkyPanel.CreateOperation) (SkyPanel.Operation, error) {
+	file := cast.ToString(op.OperationArgs["file"])
+	format := cast.ToString(op.OperationArgs["format"])
+	if format == "" {
+		format = configfile.DetectFormat(file)
+	}
+	if format == "" {
+		return nil, SkyPanel.ErrUnknownConfigFormat(file)
+	}
+
+	var remove []string
+	if v, ok := op.OperationArgs["delete"].(string); ok {
+		remove = []string{v}
+	} else {
+		remove = cast.ToStringSlice(op.OperationArgs["delete"])
+	}
+
+	//nested values are not replaced when the process is generated, so variables are filled in here
+	edit := configfile.Edit{
+		Set:    replaceTokens(cast.ToStringMap(op.OperationArgs["set"]), op.DataMap).(map[string]interface{}),
+		Delete: utils.ReplaceTokensInArr(remove, op.DataMap),
+		Merge:  replaceTokens(cast.ToStringMap(op.OperationArgs["merge"]), op.DataMap).(map[string]interface{}),
+	}
+
+	return EditConfig{File: file, Format: format, Edit: edit}, nil
+}
+
+func (of OperationFactory) Key() string {
+	return "editconfig"
+}
+
+func replaceTokens(value interface{}, mapping map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return utils.ReplaceTokens(v, mapping)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			result[k] = replaceTokens(e, mapping)
+		}
+		return result
+	case map[interface{}]interface{}:
+		return replaceTokens(cast.ToStringMap(v), mapping)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, e := range v {
+			result[i] = replaceTokens(e, mapping)
+		}
+		return result
+	}
+	return value
+}
+
+var Factory OperationFactory
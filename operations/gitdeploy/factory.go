@@ -0,0 +1,42 @@
+package gitdeploy
+
+import (
+	"errors"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/spf13/cast"
+)
+
+type OperationFactory struct {
+	SkyPanel.OperationFactory
+}
+
+func (of OperationFactory) Create(op SkyPanel.CreateOperation) (SkyPanel.Operation, error) {
+	deploy := SkyPanel.GitDeploy{
+		Repo:    cast.ToString(op.OperationArgs["repo"]),
+		Branch:  cast.ToString(op.OperationArgs["branch"]),
+		Target:  cast.ToString(op.OperationArgs["target"]),
+		Include: globs(op.OperationArgs["include"]),
+		Exclude: globs(op.OperationArgs["exclude"]),
+	}
+	if deploy.Repo == "" {
+		return nil, errors.New("missing repo")
+	}
+	return GitDeploy{Deploy: deploy}, nil
+}
+
+func (of OperationFactory) Key() string {
+	return "gitdeploy"
+}
+
+func globs(value interface{}) []string {
+	if v, ok := value.(string); ok {
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	}
+	return cast.ToStringSlice(value)
+}
+
+var Factory OperationFactory
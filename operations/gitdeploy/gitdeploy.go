@@ -0,0 +1,25 @@
+package gitdeploy
+
+import (
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/gitdeploy"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+)
+
+type GitDeploy struct {
+	Deploy SkyPanel.GitDeploy
+}
+
+func (c GitDeploy) Run(args SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
+	env := args.Environment
+
+	logging.Info.Printf("Deploying %s", c.Deploy.Repo)
+	env.DisplayToConsole(true, "Deploying %s\n", c.Deploy.Repo)
+
+	result, err := gitdeploy.Deploy(args.GetContext(), args.Server.GetFileServer(), args.Server.Id(), c.Deploy)
+	if err != nil {
+		return SkyPanel.OperationResult{Error: err}
+	}
+	env.DisplayToConsole(true, "Deployed commit %s, %d files changed and %d removed\n", result.Commit, len(result.Changed), len(result.Removed))
+	return SkyPanel.OperationResult{}
+}
@@ -0,0 +1,174 @@
+package modrinth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+)
+
+var ApiUrl = "https://api.modrinth.com/v2"
+
+// Modrinth asks every client to send a user agent that identifies it
+var UserAgent = SkyPanel.Display + " https://github.com/SkyPanel/SkyPanel"
+
+var errNotFound = errors.New("not found on modrinth")
+
+func GetProject(projectId string) (Project, error) {
+	var project Project
+	err := callModrinth("GET", "/project/"+url.PathEscape(projectId), nil, nil, &project)
+	if errors.Is(err, errNotFound) {
+		return project, SkyPanel.ErrModrinthNoVersion(projectId)
+	}
+	return project, err
+}
+
+func GetVersion(versionId string) (Version, error) {
+	var version Version
+	err := callModrinth("GET", "/version/"+url.PathEscape(versionId), nil, nil, &version)
+	if errors.Is(err, errNotFound) {
+		return version, SkyPanel.ErrModrinthNoVersion(versionId)
+	}
+	return version, err
+}
+
+// GetVersions gets the versions of a project, newest first, only for the given loader and game version if they are set
+func GetVersions(projectId, loader, gameVersion string) ([]Version, error) {
+	query := url.Values{}
+	if loader != "" {
+		query.Set("loaders", `["`+loader+`"]`)
+	}
+	if gameVersion != "" {
+		query.Set("game_versions", `["`+gameVersion+`"]`)
+	}
+
+	var versions []Version
+	err := callModrinth("GET", "/project/"+url.PathEscape(projectId)+"/version", query, nil, &versions)
+	if errors.Is(err, errNotFound) {
+		return nil, SkyPanel.ErrModrinthNoVersion(projectId)
+	}
+	return versions, err
+}
+
+// LatestVersion gets the newest release of a project, or the newest beta or alpha if there are no releases
+func LatestVersion(projectId, loader, gameVersion string) (Version, error) {
+	versions, err := GetVersions(projectId, loader, gameVersion)
+	if err != nil {
+		return Version{}, err
+	}
+	if len(versions) == 0 {
+		return Version{}, SkyPanel.ErrModrinthNoVersion(projectId)
+	}
+
+	latest := versions[0]
+	for _, v := range versions {
+		if v.VersionType == "release" {
+			if latest.VersionType != "release" || v.DatePublished.After(latest.DatePublished) {
+				latest = v
+			}
+		} else if latest.VersionType != "release" && v.DatePublished.After(latest.DatePublished) {
+			latest = v
+		}
+	}
+	return latest, nil
+}
+
+// Search looks for projects of a type (mod, plugin, modpack), optionally only the ones for a loader and game version
+func Search(query, projectType, loader, gameVersion string, limit int) (SearchResponse, error) {
+	facets := make([][]string, 0)
+	if projectType != "" {
+		facets = append(facets, []string{"project_type:" + projectType})
+	}
+	if loader != "" {
+		facets = append(facets, []string{"categories:" + loader})
+	}
+	if gameVersion != "" {
+		facets = append(facets, []string{"versions:" + gameVersion})
+	}
+
+	values := url.Values{}
+	values.Set("query", query)
+	values.Set("limit", strconv.Itoa(limit))
+	if len(facets) > 0 {
+		data, _ := json.Marshal(facets)
+		values.Set("facets", string(data))
+	}
+
+	var result SearchResponse
+	err := callModrinth("GET", "/search", values, nil, &result)
+	return result, err
+}
+
+// GetVersionsByHash finds which version each file belongs to, from their sha1 hashes
+// Files that are not on Modrinth are not in the result
+func GetVersionsByHash(hashes []string) (map[string]Version, error) {
+	result := make(map[string]Version)
+	if len(hashes) == 0 {
+		return result, nil
+	}
+
+	body := map[string]interface{}{
+		"hashes":    hashes,
+		"algorithm": "sha1",
+	}
+	err := callModrinth("POST", "/version_files", nil, body, &result)
+	return result, err
+}
+
+func callModrinth(method, path string, query url.Values, body interface{}, result interface{}) error {
+	u := ApiUrl + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("User-Agent", UserAgent)
+	if body != nil {
+		request.Header.Add("Content-Type", "application/json")
+	}
+
+	logging.Debug.Printf("Calling %s\n", request.URL.String())
+	response, err := SkyPanel.Http().Do(request)
+	defer utils.CloseResponse(response)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if response.StatusCode != http.StatusOK {
+		return SkyPanel.ErrModrinthStatus(response.Status)
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// isPluginLoader tells if files for this loader go in the plugins folder instead of mods
+func isPluginLoader(loader string) bool {
+	switch strings.ToLower(loader) {
+	case "bukkit", "spigot", "paper", "purpur", "folia", "sponge", "velocity", "bungeecord", "waterfall":
+		return true
+	}
+	return false
+}
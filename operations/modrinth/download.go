@@ -0,0 +1,150 @@
+package modrinth
+
+import (
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+)
+
+// cacheFile downloads a file into the cache, under its sha1, trying each url until one gives the expected hashes
+// A file that is already in the cache is only used if its hashes still match
+func cacheFile(name string, urls []string, hashes Hashes) (string, error) {
+	if hashes.Sha1 == "" && hashes.Sha512 == "" {
+		return "", errors.New("no hashes given for " + name)
+	}
+	//the hashes come from the pack and make up the cache path, so they have to be checked before use
+	if hashes.Sha1 != "" && !utils.IsHex(hashes.Sha1, sha1.Size*2) {
+		return "", SkyPanel.ErrInvalidHash(name, hashes.Sha1)
+	}
+	if hashes.Sha512 != "" && !utils.IsHex(hashes.Sha512, sha512.Size*2) {
+		return "", SkyPanel.ErrInvalidHash(name, hashes.Sha512)
+	}
+
+	key := hashes.Sha1
+	if key == "" {
+		key = hashes.Sha512
+	}
+	cachePath := filepath.Join(config.CacheFolder.Value(), "modrinth", "files", key[:2], key)
+
+	if err := verifyFile(cachePath, name, hashes); err == nil {
+		logging.Debug.Printf("Using cached copy of %s\n", name)
+		return cachePath, nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(cachePath), 0755)
+	if err != nil && !os.IsExist(err) {
+		return "", err
+	}
+
+	err = errors.New("no download urls for " + name)
+	for _, u := range urls {
+		if err = downloadVerified(u, cachePath, name, hashes); err == nil {
+			return cachePath, nil
+		}
+		logging.Info.Printf("Failed to download %s from %s: %s\n", name, u, err.Error())
+	}
+	return "", err
+}
+
+func downloadVerified(url, target, name string, hashes Hashes) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(target), "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	defer utils.Close(tmpFile)
+
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("User-Agent", UserAgent)
+
+	logging.Info.Printf("Downloading: %s\n", url)
+	response, err := SkyPanel.Http().Do(request)
+	defer utils.CloseResponse(response)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return SkyPanel.ErrModrinthStatus(response.Status)
+	}
+
+	sha1Hash, sha512Hash := sha1.New(), sha512.New()
+	_, err = io.Copy(io.MultiWriter(tmpFile, sha1Hash, sha512Hash), response.Body)
+	if err != nil {
+		return err
+	}
+	utils.Close(tmpFile)
+
+	err = checkHashes(name, hashes, hex.EncodeToString(sha1Hash.Sum(nil)), hex.EncodeToString(sha512Hash.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), target)
+}
+
+func verifyFile(file, name string, hashes Hashes) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(f)
+
+	sha1Hash, sha512Hash := sha1.New(), sha512.New()
+	if _, err = io.Copy(io.MultiWriter(sha1Hash, sha512Hash), f); err != nil {
+		return err
+	}
+	return checkHashes(name, hashes, hex.EncodeToString(sha1Hash.Sum(nil)), hex.EncodeToString(sha512Hash.Sum(nil)))
+}
+
+func checkHashes(name string, expected Hashes, sha1Sum, sha512Sum string) error {
+	if expected.Sha512 != "" && expected.Sha512 != sha512Sum {
+		return SkyPanel.ErrHashMismatch(name, expected.Sha512, sha512Sum)
+	}
+	if expected.Sha1 != "" && expected.Sha1 != sha1Sum {
+		return SkyPanel.ErrHashMismatch(name, expected.Sha1, sha1Sum)
+	}
+	return nil
+}
+
+// copyToServer copies a cached file into the server, refusing any path that leaves the server directory
+func copyToServer(fs files.FileServer, source, target string) error {
+	target = path.Clean(filepath.ToSlash(target))
+	if !filepath.IsLocal(target) {
+		return SkyPanel.ErrUnsafePath(target)
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(in)
+
+	if dir := path.Dir(target); dir != "." {
+		if err = fs.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	out, err := fs.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(out)
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,33 @@
+package modrinth
+
+import (
+	"errors"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/spf13/cast"
+)
+
+type OperationFactory struct {
+	SkyPanel.OperationFactory
+}
+
+func (of OperationFactory) Create(op SkyPanel.CreateOperation) (SkyPanel.Operation, error) {
+	projectId := cast.ToString(op.OperationArgs["projectId"])
+	versionId := cast.ToString(op.OperationArgs["versionId"])
+	if projectId == "" && versionId == "" {
+		return nil, errors.New("missing projectId and versionId")
+	}
+
+	javaBinary := cast.ToString(op.OperationArgs["java"])
+	if javaBinary == "" {
+		javaBinary = "java"
+	}
+
+	return Modrinth{ProjectId: projectId, VersionId: versionId, JavaBinary: javaBinary}, nil
+}
+
+func (of OperationFactory) Key() string {
+	return "modrinth"
+}
+
+var Factory OperationFactory
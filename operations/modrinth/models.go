@@ -0,0 +1,84 @@
+package modrinth
+
+import "time"
+
+type Project struct {
+	Id          string `json:"id"`
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	ProjectType string `json:"project_type"`
+}
+
+type Version struct {
+	Id            string        `json:"id"`
+	ProjectId     string        `json:"project_id"`
+	Name          string        `json:"name"`
+	VersionNumber string        `json:"version_number"`
+	VersionType   string        `json:"version_type"`
+	GameVersions  []string      `json:"game_versions"`
+	Loaders       []string      `json:"loaders"`
+	DatePublished time.Time     `json:"date_published"`
+	Files         []VersionFile `json:"files"`
+}
+
+type VersionFile struct {
+	Hashes   Hashes `json:"hashes"`
+	Url      string `json:"url"`
+	Filename string `json:"filename"`
+	Primary  bool   `json:"primary"`
+	Size     int64  `json:"size"`
+}
+
+type Hashes struct {
+	Sha1   string `json:"sha1"`
+	Sha512 string `json:"sha512"`
+}
+
+type SearchResponse struct {
+	Hits      []SearchHit `json:"hits"`
+	TotalHits int         `json:"total_hits"`
+}
+
+type SearchHit struct {
+	ProjectId     string   `json:"project_id"`
+	Slug          string   `json:"slug"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	Author        string   `json:"author"`
+	IconUrl       string   `json:"icon_url"`
+	Downloads     int      `json:"downloads"`
+	ProjectType   string   `json:"project_type"`
+	Categories    []string `json:"categories"`
+	LatestVersion string   `json:"latest_version"`
+}
+
+// Index is the modrinth.index.json inside a .mrpack
+type Index struct {
+	FormatVersion int               `json:"formatVersion"`
+	Game          string            `json:"game"`
+	VersionId     string            `json:"versionId"`
+	Name          string            `json:"name"`
+	Files         []IndexFile       `json:"files"`
+	Dependencies  map[string]string `json:"dependencies"`
+}
+
+type IndexFile struct {
+	Path      string            `json:"path"`
+	Hashes    Hashes            `json:"hashes"`
+	Env       map[string]string `json:"env"`
+	Downloads []string          `json:"downloads"`
+	FileSize  int64             `json:"fileSize"`
+}
+
+// PrimaryFile gets the file marked as primary, or the first one if none is
+func (v Version) PrimaryFile() (VersionFile, bool) {
+	for _, f := range v.Files {
+		if f.Primary {
+			return f, true
+		}
+	}
+	if len(v.Files) > 0 {
+		return v.Files[0], true
+	}
+	return VersionFile{}, false
+}
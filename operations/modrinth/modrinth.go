@@ -0,0 +1,215 @@
+package modrinth
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/operations/curseforge"
+	"github.com/SkyPanel/SkyPanel/v3/operations/fabricdl"
+	"github.com/SkyPanel/SkyPanel/v3/operations/forgedl"
+	"github.com/SkyPanel/SkyPanel/v3/operations/neoforgedl"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+	"github.com/klauspost/compress/zip"
+)
+
+const indexFile = "modrinth.index.json"
+
+// overrides are applied in this order, so server-overrides win over the common ones
+var overrideFolders = []string{"overrides/", "server-overrides/"}
+
+type Modrinth struct {
+	ProjectId  string
+	VersionId  string
+	JavaBinary string
+}
+
+//plan
+//resolve the version of the pack (given one, or the latest release)
+//download the .mrpack to the cache, checking the hashes Modrinth gives
+//read modrinth.index.json
+//- download every file the server needs to the cache, checking their hashes, and copy them to the server
+//- copy overrides, then server-overrides
+//install the loader from the dependencies with the forgedl, neoforgedl or fabricdl operations
+
+func (m Modrinth) Run(args SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
+	env := args.Environment
+	fs := args.Server.GetFileServer()
+
+	var version Version
+	var err error
+	if m.VersionId != "" {
+		version, err = GetVersion(m.VersionId)
+	} else {
+		version, err = LatestVersion(m.ProjectId, "", "")
+	}
+	if err != nil {
+		return SkyPanel.OperationResult{Error: err}
+	}
+
+	var pack VersionFile
+	for _, v := range version.Files {
+		if strings.HasSuffix(v.Filename, ".mrpack") && (v.Primary || pack.Url == "") {
+			pack = v
+		}
+	}
+	if pack.Url == "" {
+		return SkyPanel.OperationResult{Error: errors.New("version " + version.Id + " has no .mrpack file")}
+	}
+
+	env.DisplayToConsole(true, "Downloading modpack %s (%s)\n", version.Name, version.VersionNumber)
+	packPath, err := cacheFile(pack.Filename, []string{pack.Url}, pack.Hashes)
+	if err != nil {
+		return SkyPanel.OperationResult{Error: err}
+	}
+
+	index, err := readIndex(packPath)
+	if err != nil {
+		return SkyPanel.OperationResult{Error: err}
+	}
+	if index.Game != "minecraft" {
+		return SkyPanel.OperationResult{Error: errors.New("unsupported game " + index.Game)}
+	}
+
+	for i, file := range index.Files {
+		if file.Env["server"] == "unsupported" {
+			continue
+		}
+		env.DisplayToConsole(true, "Downloading file %d of %d: %s\n", i+1, len(index.Files), file.Path)
+		cached, err := cacheFile(file.Path, file.Downloads, file.Hashes)
+		if err != nil {
+			return SkyPanel.OperationResult{Error: err}
+		}
+		if err = copyToServer(fs, cached, file.Path); err != nil {
+			return SkyPanel.OperationResult{Error: err}
+		}
+	}
+
+	env.DisplayToConsole(true, "Copying overrides\n")
+	if err = extractOverrides(fs, packPath); err != nil {
+		return SkyPanel.OperationResult{Error: err}
+	}
+
+	if err = m.installLoader(args, index.Dependencies); err != nil {
+		return SkyPanel.OperationResult{Error: err}
+	}
+
+	env.DisplayToConsole(true, "Pack installed and should be good to go!")
+	return SkyPanel.OperationResult{Error: nil}
+}
+
+func (m Modrinth) installLoader(args SkyPanel.RunOperatorArgs, dependencies map[string]string) error {
+	env := args.Environment
+	mcVersion := dependencies["minecraft"]
+
+	switch {
+	case dependencies["neoforge"] != "":
+		installer := "neoforge-installer.jar"
+		op := neoforgedl.NeoforgeDL{Version: dependencies["neoforge"], Filename: installer, OutputVariable: "opNeoForgeVersion"}
+		if result := op.Run(args); result.Error != nil {
+			return result.Error
+		}
+		if err := curseforge.InstallViaJar(args.Server, env, installer, m.JavaBinary); err != nil {
+			return err
+		}
+		return curseforge.InstallServerStarter(env)
+	case dependencies["forge"] != "":
+		installer := "forge-installer.jar"
+		version := dependencies["forge"]
+		if !strings.HasPrefix(version, mcVersion) {
+			version = mcVersion + "-" + version
+		}
+		op := forgedl.ForgeDl{Version: version, Filename: installer, OutputVariable: "opForgeVersion"}
+		if result := op.Run(args); result.Error != nil {
+			return result.Error
+		}
+		if err := curseforge.InstallViaJar(args.Server, env, installer, m.JavaBinary); err != nil {
+			return err
+		}
+		return curseforge.InstallServerStarter(env)
+	case dependencies["fabric-loader"] != "":
+		op := &fabricdl.Fabricdl{}
+		if result := op.Run(args); result.Error != nil {
+			return result.Error
+		}
+		return curseforge.RunFabricInstaller(env, mcVersion, dependencies["fabric-loader"], m.JavaBinary)
+	case dependencies["quilt-loader"] != "":
+		env.DisplayToConsole(true, "Quilt is not supported, the loader has to be installed by hand")
+		return nil
+	default:
+		env.DisplayToConsole(true, "The pack has no mod loader, only the files were installed")
+		return nil
+	}
+}
+
+func readIndex(packPath string) (Index, error) {
+	var index Index
+
+	reader, err := zip.OpenReader(packPath)
+	if err != nil {
+		return index, err
+	}
+	defer utils.Close(reader)
+
+	file, err := reader.Open(indexFile)
+	if err != nil {
+		return index, err
+	}
+	defer utils.Close(file)
+
+	err = json.NewDecoder(file).Decode(&index)
+	return index, err
+}
+
+func extractOverrides(fs files.FileServer, packPath string) error {
+	reader, err := zip.OpenReader(packPath)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(reader)
+
+	for _, folder := range overrideFolders {
+		for _, entry := range reader.File {
+			if !strings.HasPrefix(entry.Name, folder) || entry.FileInfo().IsDir() {
+				continue
+			}
+			target := path.Clean(strings.TrimPrefix(entry.Name, folder))
+			if !filepath.IsLocal(target) {
+				return SkyPanel.ErrUnsafePath(target)
+			}
+			if err = extractEntry(fs, entry, target); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func extractEntry(fs files.FileServer, entry *zip.File, target string) error {
+	if dir := path.Dir(target); dir != "." {
+		if err := fs.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	in, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer utils.Close(in)
+
+	out, err := fs.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer utils.Close(out)
+
+	_, err = io.Copy(out, in)
+	return err
+}
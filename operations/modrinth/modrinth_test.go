@@ -0,0 +1,119 @@
+package modrinth
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/klauspost/compress/zip"
+	"github.com/stretchr/testify/assert"
+)
+
+func hashesOf(data []byte) Hashes {
+	s1 := sha1.Sum(data)
+	s512 := sha512.Sum512(data)
+	return Hashes{Sha1: hex.EncodeToString(s1[:]), Sha512: hex.EncodeToString(s512[:])}
+}
+
+func TestCacheFile(t *testing.T) {
+	_ = config.CacheFolder.Set(t.TempDir(), false)
+
+	data := []byte("mod contents")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			_, _ = w.Write([]byte("tampered"))
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	t.Run("RejectsWrongHash", func(t *testing.T) {
+		_, err := cacheFile("mod.jar", []string{server.URL + "/bad"}, hashesOf(data))
+		assert.Error(t, err)
+	})
+
+	t.Run("FallsBackToNextUrl", func(t *testing.T) {
+		cached, err := cacheFile("mod.jar", []string{server.URL + "/bad", server.URL + "/good"}, hashesOf(data))
+		if !assert.NoError(t, err) {
+			return
+		}
+		content, err := os.ReadFile(cached)
+		assert.NoError(t, err)
+		assert.Equal(t, data, content)
+	})
+
+	t.Run("RejectsInvalidHash", func(t *testing.T) {
+		for _, hashes := range []Hashes{{Sha1: "a"}, {Sha1: "../../x"}, {Sha512: "../" + hashesOf(data).Sha512[3:]}, {Sha1: strings.ToUpper(hashesOf(data).Sha1)}} {
+			_, err := cacheFile("mod.jar", []string{server.URL + "/good"}, hashes)
+			assert.Equal(t, "ErrInvalidHash", SkyPanel.FromError(err).GetCode())
+		}
+	})
+
+	t.Run("UsesCache", func(t *testing.T) {
+		server.Close()
+		_, err := cacheFile("mod.jar", []string{server.URL + "/good"}, hashesOf(data))
+		assert.NoError(t, err)
+	})
+}
+
+func TestExtractOverrides(t *testing.T) {
+	root := t.TempDir()
+	fs, err := files.NewFileServer(root, os.Getuid(), os.Getgid())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer fs.Close()
+
+	writePack := func(entries map[string]string) string {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		for name, content := range entries {
+			f, _ := w.Create(name)
+			_, _ = f.Write([]byte(content))
+		}
+		_ = w.Close()
+		p := filepath.Join(t.TempDir(), "pack.mrpack")
+		_ = os.WriteFile(p, buf.Bytes(), 0644)
+		return p
+	}
+
+	t.Run("ServerOverridesWin", func(t *testing.T) {
+		pack := writePack(map[string]string{
+			"overrides/config/a.toml":        "client",
+			"server-overrides/config/a.toml": "server",
+			"overrides/server.properties":    "motd=hi",
+		})
+		if !assert.NoError(t, extractOverrides(fs, pack)) {
+			return
+		}
+		content, _ := os.ReadFile(filepath.Join(root, "config", "a.toml"))
+		assert.Equal(t, "server", string(content))
+		content, _ = os.ReadFile(filepath.Join(root, "server.properties"))
+		assert.Equal(t, "motd=hi", string(content))
+	})
+
+	t.Run("RejectsTraversal", func(t *testing.T) {
+		pack := writePack(map[string]string{
+			"overrides/../../evil.txt": "x",
+		})
+		assert.Error(t, extractOverrides(fs, pack))
+	})
+}
+
+func TestTargetFolder(t *testing.T) {
+	assert.Equal(t, "plugins", targetFolder(Version{Loaders: []string{"paper", "spigot"}}, ""))
+	assert.Equal(t, "mods", targetFolder(Version{Loaders: []string{"fabric", "paper"}}, ""))
+	assert.Equal(t, "plugins", targetFolder(Version{Loaders: []string{"fabric", "paper"}}, "paper"))
+	assert.Equal(t, "mods", targetFolder(Version{Loaders: []string{"forge"}}, "forge"))
+}
@@ -0,0 +1,233 @@
+package modrinth
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+)
+
+var modFolders = []string{"mods", "plugins"}
+
+// InstalledFile is a jar in the mods or plugins folder that Modrinth knows about
+type InstalledFile struct {
+	File          string `json:"file"`
+	ProjectId     string `json:"projectId"`
+	VersionId     string `json:"versionId"`
+	VersionNumber string `json:"versionNumber"`
+	Name          string `json:"name"`
+} //@name ModrinthInstalledFile
+
+// ListInstalled finds the jars of the server that come from Modrinth, by their hashes
+func ListInstalled(fs files.FileServer) ([]InstalledFile, error) {
+	byHash := make(map[string]string)
+	for _, folder := range modFolders {
+		entries, err := fs.ReadDir(folder)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(strings.ToLower(entry.Name()), ".jar") {
+				continue
+			}
+			file := path.Join(folder, entry.Name())
+			hash, err := hashFile(fs, file)
+			if err != nil {
+				return nil, err
+			}
+			byHash[hash] = file
+		}
+	}
+
+	hashes := make([]string, 0, len(byHash))
+	for k := range byHash {
+		hashes = append(hashes, k)
+	}
+	versions, err := GetVersionsByHash(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]InstalledFile, 0, len(versions))
+	for hash, version := range versions {
+		file, ok := byHash[hash]
+		if !ok {
+			continue
+		}
+		result = append(result, InstalledFile{
+			File:          file,
+			ProjectId:     version.ProjectId,
+			VersionId:     version.Id,
+			VersionNumber: version.VersionNumber,
+			Name:          version.Name,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].File < result[j].File
+	})
+	return result, nil
+}
+
+// Install adds a project to the server, the given version or the latest one for the loader and game version
+func Install(fs files.FileServer, projectId, versionId, loader, gameVersion string) (InstalledFile, error) {
+	var version Version
+	var err error
+	if versionId != "" {
+		version, err = GetVersion(versionId)
+	} else {
+		version, err = LatestVersion(projectId, loader, gameVersion)
+	}
+	if err != nil {
+		return InstalledFile{}, err
+	}
+	return installVersion(fs, version, loader)
+}
+
+// Update replaces the installed file of a project with its latest version, it returns false if it was already the latest
+func Update(fs files.FileServer, projectId, loader, gameVersion string) (InstalledFile, bool, error) {
+	installed, err := findInstalled(fs, projectId)
+	if err != nil {
+		return InstalledFile{}, false, err
+	}
+	current := installed[0]
+
+	latest, err := LatestVersion(current.ProjectId, loader, gameVersion)
+	if err != nil {
+		return InstalledFile{}, false, err
+	}
+	if latest.Id == current.VersionId {
+		return current, false, nil
+	}
+
+	if loader == "" && path.Dir(current.File) == "plugins" {
+		loader = "bukkit"
+	}
+	updated, err := installVersion(fs, latest, loader)
+	if err != nil {
+		return InstalledFile{}, false, err
+	}
+	for _, v := range installed {
+		if v.File != updated.File {
+			if err = fs.Remove(v.File); err != nil {
+				return InstalledFile{}, false, err
+			}
+		}
+	}
+	return updated, true, nil
+}
+
+// Remove deletes every installed file of a project
+func Remove(fs files.FileServer, projectId string) ([]InstalledFile, error) {
+	installed, err := findInstalled(fs, projectId)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range installed {
+		if err = fs.Remove(v.File); err != nil {
+			return nil, err
+		}
+	}
+	return installed, nil
+}
+
+func installVersion(fs files.FileServer, version Version, loader string) (InstalledFile, error) {
+	file, ok := version.PrimaryFile()
+	if !ok {
+		return InstalledFile{}, SkyPanel.ErrModrinthNoVersion(version.ProjectId)
+	}
+
+	cached, err := cacheFile(file.Filename, []string{file.Url}, file.Hashes)
+	if err != nil {
+		return InstalledFile{}, err
+	}
+
+	target := path.Join(targetFolder(version, loader), path.Base(file.Filename))
+	if err = copyToServer(fs, cached, target); err != nil {
+		return InstalledFile{}, err
+	}
+	return InstalledFile{
+		File:          target,
+		ProjectId:     version.ProjectId,
+		VersionId:     version.Id,
+		VersionNumber: version.VersionNumber,
+		Name:          version.Name,
+	}, nil
+}
+
+// targetFolder is plugins for plugin loaders and mods for everything else
+// Without a loader, a version only goes to plugins if all its loaders are plugin loaders
+func targetFolder(version Version, loader string) string {
+	if loader != "" {
+		if isPluginLoader(loader) {
+			return "plugins"
+		}
+		return "mods"
+	}
+
+	if len(version.Loaders) == 0 {
+		return "mods"
+	}
+	for _, v := range version.Loaders {
+		if !isPluginLoader(v) {
+			return "mods"
+		}
+	}
+	return "plugins"
+}
+
+// findInstalled gets the installed files of a project, which can be given by id or slug
+func findInstalled(fs files.FileServer, projectId string) ([]InstalledFile, error) {
+	installed, err := ListInstalled(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := filterProject(installed, projectId)
+	if len(result) == 0 && len(installed) > 0 {
+		//might be a slug, Modrinth only gives us ids
+		project, err := GetProject(projectId)
+		if err == nil {
+			result = filterProject(installed, project.Id)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, SkyPanel.ErrModrinthNotInstalled(projectId)
+	}
+	return result, nil
+}
+
+func filterProject(installed []InstalledFile, projectId string) []InstalledFile {
+	result := make([]InstalledFile, 0)
+	for _, v := range installed {
+		if v.ProjectId == projectId {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func hashFile(fs files.FileServer, file string) (string, error) {
+	f, err := fs.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer utils.Close(f)
+
+	h := sha1.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,94 @@
+package resolveversion
+
+import (
+	"context"
+	"errors"
+	"net/url"
+)
+
+var FabricUrl = "https://meta.fabricmc.net/v2"
+var QuiltUrl = "https://meta.quiltmc.org/v3"
+
+// Fabric resolves Minecraft versions Fabric supports, the builds are the loader versions for it
+type Fabric struct{}
+
+// Quilt works like Fabric, but its meta service has no server jar so the builds have no url and the Quilt installer has to install them
+type Quilt struct{}
+
+type metaVersion struct {
+	Version string `json:"version"`
+	Stable  *bool  `json:"stable"`
+}
+
+type metaLoader struct {
+	Loader metaVersion `json:"loader"`
+}
+
+func (v metaVersion) stable() bool {
+	if v.Stable != nil {
+		return *v.Stable
+	}
+	return isStable(v.Version)
+}
+
+func (f Fabric) Versions(ctx context.Context) ([]Version, error) {
+	return metaGameVersions(ctx, FabricUrl)
+}
+
+func (f Fabric) Builds(ctx context.Context, version string) ([]Build, error) {
+	var installers []metaVersion
+	if err := getJson(ctx, FabricUrl+"/versions/installer", &installers); err != nil {
+		return nil, err
+	}
+	installer := ""
+	for _, v := range installers {
+		if v.stable() {
+			installer = v.Version
+			break
+		}
+	}
+	if installer == "" {
+		return nil, errors.New("no stable Fabric installer found")
+	}
+
+	builds, err := metaLoaders(ctx, FabricUrl, version)
+	for i, v := range builds {
+		builds[i].Url = FabricUrl + "/versions/loader/" + url.PathEscape(version) + "/" + url.PathEscape(v.Id) + "/" + url.PathEscape(installer) + "/server/jar"
+	}
+	return builds, err
+}
+
+func (q Quilt) Versions(ctx context.Context) ([]Version, error) {
+	return metaGameVersions(ctx, QuiltUrl)
+}
+
+func (q Quilt) Builds(ctx context.Context, version string) ([]Build, error) {
+	return metaLoaders(ctx, QuiltUrl, version)
+}
+
+// metaGameVersions reads the game versions from the Fabric or Quilt meta service, which lists them newest first
+func metaGameVersions(ctx context.Context, base string) ([]Version, error) {
+	var data []metaVersion
+	if err := getJson(ctx, base+"/versions/game", &data); err != nil {
+		return nil, err
+	}
+
+	result := make([]Version, 0, len(data))
+	for _, v := range data {
+		result = append(result, Version{Id: v.Version, Stable: v.stable()})
+	}
+	return result, nil
+}
+
+func metaLoaders(ctx context.Context, base, version string) ([]Build, error) {
+	var data []metaLoader
+	if err := getJson(ctx, base+"/versions/loader/"+url.PathEscape(version), &data); err != nil {
+		return nil, err
+	}
+
+	result := make([]Build, 0, len(data))
+	for _, v := range data {
+		result = append(result, Build{Id: v.Loader.Version, Stable: v.Loader.stable()})
+	}
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package resolveversion
+
+import (
+	"errors"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/spf13/cast"
+)
+
+type OperationFactory struct {
+	SkyPanel.OperationFactory
+}
+
+func (of OperationFactory) Create(op SkyPanel.CreateOperation) (SkyPanel.Operation, error) {
+	provider := cast.ToString(op.OperationArgs["provider"])
+	if provider == "" {
+		return nil, errors.New("missing provider")
+	}
+	if _, err := GetProvider(provider); err != nil {
+		return nil, err
+	}
+
+	return ResolveVersion{
+		Provider: provider,
+		Version:  cast.ToString(op.OperationArgs["version"]),
+		Build:    cast.ToString(op.OperationArgs["build"]),
+		Outputs: Outputs{
+			Version: variable(op.OperationArgs, "outputVariable", "opVersion"),
+			Build:   variable(op.OperationArgs, "buildVariable", "opBuild"),
+			Url:     variable(op.OperationArgs, "urlVariable", "opUrl"),
+			Sha256:  variable(op.OperationArgs, "sha256Variable", "opSha256"),
+			Sha1:    variable(op.OperationArgs, "sha1Variable", "opSha1"),
+		},
+	}, nil
+}
+
+func (of OperationFactory) Key() string {
+	return "resolveversion"
+}
+
+func variable(args map[string]interface{}, key, fallback string) string {
+	if v := cast.ToString(args[key]); v != "" {
+		return v
+	}
+	return fallback
+}
+
+var Factory OperationFactory
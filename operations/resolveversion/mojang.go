@@ -0,0 +1,51 @@
+package resolveversion
+
+import (
+	"context"
+
+	"github.com/SkyPanel/SkyPanel/v3/operations/mojangdl"
+)
+
+var MojangUrl = mojangdl.VersionJsonUrl
+
+// Mojang resolves vanilla releases and snapshots, there is one build per version holding the server jar
+type Mojang struct{}
+
+func (m Mojang) Versions(ctx context.Context) ([]Version, error) {
+	var data mojangdl.LauncherJson
+	if err := getJson(ctx, MojangUrl, &data); err != nil {
+		return nil, err
+	}
+
+	result := make([]Version, 0, len(data.Versions))
+	for _, v := range data.Versions {
+		//old_alpha and old_beta have no server
+		if v.Type == "release" || v.Type == "snapshot" {
+			result = append(result, Version{Id: v.Id, Stable: v.Type == "release"})
+		}
+	}
+	return result, nil
+}
+
+func (m Mojang) Builds(ctx context.Context, version string) ([]Build, error) {
+	var data mojangdl.LauncherJson
+	if err := getJson(ctx, MojangUrl, &data); err != nil {
+		return nil, err
+	}
+
+	for _, v := range data.Versions {
+		if v.Id != version {
+			continue
+		}
+		var meta mojangdl.VersionJson
+		if err := getJson(ctx, v.Url, &meta); err != nil {
+			return nil, err
+		}
+		server, ok := meta.Downloads["server"]
+		if !ok {
+			return nil, nil
+		}
+		return []Build{{Stable: v.Type == "release", Url: server.Url, Sha1: server.Sha1}}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,69 @@
+package resolveversion
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var PaperUrl = "https://fill.papermc.io/v3"
+
+// PaperMC resolves the projects on the PaperMC download service: paper, folia, velocity and waterfall
+type PaperMC struct {
+	Project string
+}
+
+type paperVersions struct {
+	Versions []struct {
+		Version struct {
+			Id string `json:"id"`
+		} `json:"version"`
+	} `json:"versions"`
+}
+
+type paperBuild struct {
+	Id        int    `json:"id"`
+	Channel   string `json:"channel"`
+	Downloads map[string]struct {
+		Url       string `json:"url"`
+		Checksums struct {
+			Sha256 string `json:"sha256"`
+		} `json:"checksums"`
+	} `json:"downloads"`
+}
+
+func (p PaperMC) Versions(ctx context.Context) ([]Version, error) {
+	var data paperVersions
+	if err := getJson(ctx, PaperUrl+"/projects/"+url.PathEscape(p.Project)+"/versions", &data); err != nil {
+		return nil, err
+	}
+
+	result := make([]Version, 0, len(data.Versions))
+	for _, v := range data.Versions {
+		result = append(result, Version{Id: v.Version.Id, Stable: isStable(v.Version.Id)})
+	}
+	sortNewestFirst(result)
+	return result, nil
+}
+
+func (p PaperMC) Builds(ctx context.Context, version string) ([]Build, error) {
+	var data []paperBuild
+	if err := getJson(ctx, PaperUrl+"/projects/"+url.PathEscape(p.Project)+"/versions/"+url.PathEscape(version)+"/builds", &data); err != nil {
+		return nil, err
+	}
+
+	result := make([]Build, 0, len(data))
+	for _, v := range data {
+		download := v.Downloads["server:default"]
+		channel := strings.ToUpper(v.Channel)
+		result = append(result, Build{
+			Id:     strconv.Itoa(v.Id),
+			Stable: channel == "STABLE" || channel == "RECOMMENDED",
+			Url:    download.Url,
+			Sha256: download.Checksums.Sha256,
+		})
+	}
+	sortBuilds(result)
+	return result, nil
+}
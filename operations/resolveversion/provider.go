@@ -0,0 +1,108 @@
+package resolveversion
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+	"github.com/hashicorp/go-version"
+)
+
+var UserAgent = SkyPanel.Display + " https://github.com/SkyPanel/SkyPanel"
+
+// Version is a game or proxy version a provider knows about
+type Version struct {
+	Id     string
+	Stable bool
+}
+
+// Build is one build of a version, providers without builds give a single build with an empty id
+type Build struct {
+	Id     string
+	Stable bool
+	Url    string
+	Sha256 string
+	Sha1   string
+}
+
+// Provider looks up the versions and builds of a piece of server software
+// Both lists are returned newest first
+type Provider interface {
+	Versions(ctx context.Context) ([]Version, error)
+	Builds(ctx context.Context, version string) ([]Build, error)
+}
+
+var providers = map[string]Provider{}
+
+// Register adds a provider, replacing any with the same name
+func Register(name string, provider Provider) {
+	providers[strings.ToLower(name)] = provider
+}
+
+func GetProvider(name string) (Provider, error) {
+	provider, ok := providers[strings.ToLower(name)]
+	if !ok {
+		return nil, SkyPanel.ErrUnknownVersionProvider(name)
+	}
+	return provider, nil
+}
+
+func init() {
+	for _, v := range []string{"paper", "folia", "velocity", "waterfall"} {
+		Register(v, PaperMC{Project: v})
+	}
+	Register("purpur", Purpur{})
+	Register("fabric", Fabric{})
+	Register("quilt", Quilt{})
+	Register("mojang", Mojang{})
+}
+
+func getJson(ctx context.Context, u string, result interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("User-Agent", UserAgent)
+
+	logging.Debug.Printf("Calling %s\n", u)
+	response, err := SkyPanel.Http().Do(request)
+	defer utils.CloseResponse(response)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return SkyPanel.ErrVersionProviderStatus(response.Status, u)
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// isStable checks a version id for pre-release markers, for providers which do not say themselves
+func isStable(id string) bool {
+	if v, err := version.NewVersion(id); err == nil {
+		return v.Prerelease() == ""
+	}
+	lower := strings.ToLower(id)
+	for _, marker := range []string{"snapshot", "pre", "rc", "beta", "alpha"} {
+		if strings.Contains(lower, marker) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortNewestFirst orders versions by semver, versions which do not parse are kept in order at the end
+func sortNewestFirst(versions []Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, errA := version.NewVersion(versions[i].Id)
+		b, errB := version.NewVersion(versions[j].Id)
+		if errA != nil || errB != nil {
+			return errA == nil && errB != nil
+		}
+		return a.GreaterThan(b)
+	})
+}
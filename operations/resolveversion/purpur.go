@@ -0,0 +1,49 @@
+package resolveversion
+
+import (
+	"context"
+	"net/url"
+)
+
+var PurpurUrl = "https://api.purpurmc.org/v2/purpur"
+
+// Purpur does not mark builds as stable, every build which was published is taken as one
+type Purpur struct{}
+
+func (p Purpur) Versions(ctx context.Context) ([]Version, error) {
+	var data struct {
+		Versions []string `json:"versions"`
+	}
+	if err := getJson(ctx, PurpurUrl, &data); err != nil {
+		return nil, err
+	}
+
+	result := make([]Version, 0, len(data.Versions))
+	for _, v := range data.Versions {
+		result = append(result, Version{Id: v, Stable: isStable(v)})
+	}
+	sortNewestFirst(result)
+	return result, nil
+}
+
+func (p Purpur) Builds(ctx context.Context, version string) ([]Build, error) {
+	var data struct {
+		Builds struct {
+			All []string `json:"all"`
+		} `json:"builds"`
+	}
+	if err := getJson(ctx, PurpurUrl+"/"+url.PathEscape(version), &data); err != nil {
+		return nil, err
+	}
+
+	result := make([]Build, 0, len(data.Builds.All))
+	for _, v := range data.Builds.All {
+		result = append(result, Build{
+			Id:     v,
+			Stable: true,
+			Url:    PurpurUrl + "/" + url.PathEscape(version) + "/" + url.PathEscape(v) + "/download",
+		})
+	}
+	sortBuilds(result)
+	return result, nil
+}
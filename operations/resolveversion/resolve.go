@@ -0,0 +1,128 @@
+package resolveversion
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/hashicorp/go-version"
+)
+
+// Resolved is the version and build a request resolved to
+type Resolved struct {
+	Version string
+	Build
+}
+
+// Resolve picks a version and build of what a provider serves
+// The version can be latest, stable, an exact version or a constraint such as >= 1.20, < 1.21 or 1.20.x
+// The build can be latest, stable or an exact build, empty picks a stable build when a stable version was asked for
+func Resolve(ctx context.Context, name, versionSpec, buildSpec string) (Resolved, error) {
+	var result Resolved
+	provider, err := GetProvider(name)
+	if err != nil {
+		return result, err
+	}
+
+	versions, err := provider.Versions(ctx)
+	if err != nil {
+		return result, err
+	}
+	var ok bool
+	result.Version, ok = pickVersion(versions, versionSpec)
+	if !ok {
+		return result, SkyPanel.ErrNoMatchingVersion(name, versionSpec)
+	}
+
+	if buildSpec == "" && isStableSpec(versionSpec) {
+		buildSpec = "stable"
+	}
+	builds, err := provider.Builds(ctx, result.Version)
+	if err != nil {
+		return result, err
+	}
+	build, ok := pickBuild(builds, buildSpec)
+	if !ok {
+		return result, SkyPanel.ErrNoMatchingBuild(name, result.Version, buildSpec)
+	}
+	result.Build = build
+	return result, nil
+}
+
+// pickVersion finds the version, versions which are not semver can only be picked by their exact id or as latest
+func pickVersion(versions []Version, spec string) (string, bool) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "" || strings.EqualFold(spec, "latest"):
+		if len(versions) > 0 {
+			return versions[0].Id, true
+		}
+	case isStableSpec(spec):
+		for _, v := range versions {
+			if v.Stable {
+				return v.Id, true
+			}
+		}
+	default:
+		for _, v := range versions {
+			if v.Id == spec {
+				return v.Id, true
+			}
+		}
+
+		constraint, err := version.NewConstraint(wildcard(spec))
+		if err != nil {
+			return "", false
+		}
+		for _, v := range versions {
+			if parsed, err := version.NewVersion(v.Id); err == nil && constraint.Check(parsed) {
+				return v.Id, true
+			}
+		}
+	}
+	return "", false
+}
+
+func pickBuild(builds []Build, spec string) (Build, bool) {
+	spec = strings.TrimSpace(spec)
+	for _, v := range builds {
+		switch {
+		case spec == "" || strings.EqualFold(spec, "latest"):
+			return v, true
+		case isStableSpec(spec):
+			if v.Stable {
+				return v, true
+			}
+		case v.Id == spec:
+			return v, true
+		}
+	}
+	return Build{}, false
+}
+
+func isStableSpec(spec string) bool {
+	switch strings.ToLower(strings.TrimSpace(spec)) {
+	case "stable", "latest-stable", "latest stable", "release":
+		return true
+	}
+	return false
+}
+
+// wildcard turns 1.20.x into ~> 1.20.0, which go-version understands
+func wildcard(spec string) string {
+	if !strings.HasSuffix(spec, ".x") && !strings.HasSuffix(spec, ".*") {
+		return spec
+	}
+	return "~> " + spec[:len(spec)-2] + ".0"
+}
+
+// sortBuilds puts numbered builds newest first, as not every service lists them that way
+func sortBuilds(builds []Build) {
+	sort.SliceStable(builds, func(i, j int) bool {
+		a, errA := strconv.Atoi(builds[i].Id)
+		b, errB := strconv.Atoi(builds[j].Id)
+		return errA == nil && errB == nil && a > b
+	})
+}
@@ -0,0 +1,52 @@
+package resolveversion
+
+import (
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+)
+
+// Outputs are the names of the variables the resolved values are written to
+type Outputs struct {
+	Version string
+	Build   string
+	Url     string
+	Sha256  string
+	Sha1    string
+}
+
+type ResolveVersion struct {
+	Provider string
+	Version  string
+	Build    string
+	Outputs  Outputs
+}
+
+func (op ResolveVersion) Run(args SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
+	env := args.Environment
+
+	resolved, err := Resolve(args.GetContext(), op.Provider, op.Version, op.Build)
+	if err != nil {
+		env.DisplayToConsole(true, "Could not resolve %s version %s: %s\n", op.Provider, op.Version, err.Error())
+		return SkyPanel.OperationResult{Error: err}
+	}
+
+	if resolved.Id == "" {
+		logging.Info.Printf("Resolved %s version %s", op.Provider, resolved.Version)
+		env.DisplayToConsole(true, "Resolved %s version %s\n", op.Provider, resolved.Version)
+	} else {
+		logging.Info.Printf("Resolved %s version %s build %s", op.Provider, resolved.Version, resolved.Id)
+		env.DisplayToConsole(true, "Resolved %s version %s build %s\n", op.Provider, resolved.Version, resolved.Id)
+	}
+
+	if resolved.Url == "" {
+		env.DisplayToConsole(true, "%s has no server jar to download, only the version and build were resolved\n", op.Provider)
+	}
+
+	return SkyPanel.OperationResult{VariableOverrides: map[string]interface{}{
+		op.Outputs.Version: resolved.Version,
+		op.Outputs.Build:   resolved.Id,
+		op.Outputs.Url:     resolved.Url,
+		op.Outputs.Sha256:  resolved.Sha256,
+		op.Outputs.Sha1:    resolved.Sha1,
+	}}
+}
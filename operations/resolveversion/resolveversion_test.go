@@ -0,0 +1,155 @@
+package resolveversion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickVersion(t *testing.T) {
+	versions := []Version{
+		{Id: "1.21.5-pre1"},
+		{Id: "1.21.4", Stable: true},
+		{Id: "1.20.6", Stable: true},
+		{Id: "1.20.4", Stable: true},
+		{Id: "24w10a"},
+	}
+
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{spec: "", want: "1.21.5-pre1"},
+		{spec: "latest", want: "1.21.5-pre1"},
+		{spec: "stable", want: "1.21.4"},
+		{spec: "latest stable", want: "1.21.4"},
+		{spec: "1.20.4", want: "1.20.4"},
+		{spec: "24w10a", want: "24w10a"},
+		{spec: "1.20.x", want: "1.20.6"},
+		{spec: ">= 1.20, < 1.20.5", want: "1.20.4"},
+		{spec: "~> 1.21.0", want: "1.21.4"},
+	}
+	for _, tt := range tests {
+		got, ok := pickVersion(versions, tt.spec)
+		assert.True(t, ok, tt.spec)
+		assert.Equal(t, tt.want, got, tt.spec)
+	}
+
+	_, ok := pickVersion(versions, "1.19.x")
+	assert.False(t, ok)
+	_, ok = pickVersion(versions, "not a version")
+	assert.False(t, ok)
+}
+
+func TestPickBuild(t *testing.T) {
+	builds := []Build{{Id: "3"}, {Id: "2", Stable: true}, {Id: "1", Stable: true}}
+
+	build, ok := pickBuild(builds, "")
+	assert.True(t, ok)
+	assert.Equal(t, "3", build.Id)
+
+	build, _ = pickBuild(builds, "stable")
+	assert.Equal(t, "2", build.Id)
+
+	build, _ = pickBuild(builds, "1")
+	assert.Equal(t, "1", build.Id)
+
+	_, ok = pickBuild(builds, "4")
+	assert.False(t, ok)
+	_, ok = pickBuild(nil, "")
+	assert.False(t, ok)
+}
+
+func TestResolve(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/paper/projects/velocity/versions":
+			_, _ = w.Write([]byte(`{"versions":[{"version":{"id":"3.3.0-SNAPSHOT"}},{"version":{"id":"3.4.0-SNAPSHOT"}},{"version":{"id":"3.2.0"}}]}`))
+		case "/paper/projects/velocity/versions/3.4.0-SNAPSHOT/builds":
+			_, _ = w.Write([]byte(`[{"id":480,"channel":"BETA","downloads":{"server:default":{"url":"https://example.com/480.jar","checksums":{"sha256":"abc"}}}},` +
+				`{"id":500,"channel":"BETA","downloads":{"server:default":{"url":"https://example.com/500.jar","checksums":{"sha256":"def"}}}}]`))
+		case "/paper/projects/velocity/versions/3.2.0/builds":
+			_, _ = w.Write([]byte(`[{"id":10,"channel":"STABLE","downloads":{}}]`))
+		case "/purpur":
+			_, _ = w.Write([]byte(`{"versions":["1.20.4","1.21.1","1.21"]}`))
+		case "/purpur/1.21.1":
+			_, _ = w.Write([]byte(`{"builds":{"latest":"2329","all":["2300","2329"]}}`))
+		case "/fabric/versions/game", "/quilt/versions/game":
+			_, _ = w.Write([]byte(`[{"version":"24w14a","stable":false},{"version":"1.21.1","stable":true}]`))
+		case "/fabric/versions/installer":
+			_, _ = w.Write([]byte(`[{"version":"1.1.0","stable":false},{"version":"1.0.1","stable":true}]`))
+		case "/fabric/versions/loader/1.21.1", "/quilt/versions/loader/1.21.1":
+			_, _ = w.Write([]byte(`[{"loader":{"version":"0.16.0","stable":false}},{"loader":{"version":"0.15.11","stable":true}}]`))
+		case "/mojang/version_manifest.json":
+			_, _ = w.Write([]byte(`{"versions":[{"id":"24w14a","type":"snapshot","url":"` + server.URL + `/mojang/24w14a.json"},` +
+				`{"id":"1.21.1","type":"release","url":"` + server.URL + `/mojang/1.21.1.json"},{"id":"b1.7.3","type":"old_beta"}]}`))
+		case "/mojang/1.21.1.json":
+			_, _ = w.Write([]byte(`{"downloads":{"server":{"sha1":"abc","url":"https://example.com/server.jar"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oldPaper, oldPurpur, oldFabric, oldQuilt, oldMojang := PaperUrl, PurpurUrl, FabricUrl, QuiltUrl, MojangUrl
+	PaperUrl, PurpurUrl = server.URL+"/paper", server.URL+"/purpur"
+	FabricUrl, QuiltUrl, MojangUrl = server.URL+"/fabric", server.URL+"/quilt", server.URL+"/mojang/version_manifest.json"
+	defer func() {
+		PaperUrl, PurpurUrl, FabricUrl, QuiltUrl, MojangUrl = oldPaper, oldPurpur, oldFabric, oldQuilt, oldMojang
+	}()
+
+	resolved, err := Resolve(context.Background(), "velocity", "latest", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "3.4.0-SNAPSHOT", resolved.Version)
+		assert.Equal(t, "500", resolved.Id)
+		assert.Equal(t, "https://example.com/500.jar", resolved.Url)
+		assert.Equal(t, "def", resolved.Sha256)
+	}
+
+	resolved, err = Resolve(context.Background(), "velocity", "stable", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "3.2.0", resolved.Version)
+		assert.Equal(t, "10", resolved.Id)
+	}
+
+	resolved, err = Resolve(context.Background(), "purpur", "latest", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.21.1", resolved.Version)
+		assert.Equal(t, "2329", resolved.Id)
+		assert.Equal(t, server.URL+"/purpur/1.21.1/2329/download", resolved.Url)
+	}
+
+	resolved, err = Resolve(context.Background(), "fabric", "stable", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.21.1", resolved.Version)
+		assert.Equal(t, "0.15.11", resolved.Id)
+		assert.Equal(t, server.URL+"/fabric/versions/loader/1.21.1/0.15.11/1.0.1/server/jar", resolved.Url)
+	}
+
+	resolved, err = Resolve(context.Background(), "mojang", "stable", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.21.1", resolved.Version)
+		assert.Equal(t, "https://example.com/server.jar", resolved.Url)
+		assert.Equal(t, "abc", resolved.Sha1)
+	}
+
+	//quilt has no server jar, only the version and loader are resolved
+	resolved, err = Resolve(context.Background(), "quilt", "stable", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.21.1", resolved.Version)
+		assert.Equal(t, "0.15.11", resolved.Id)
+		assert.Empty(t, resolved.Url)
+	}
+	_, err = Resolve(context.Background(), "velocity", "latest", "stable")
+	assert.Equal(t, "ErrNoMatchingBuild", SkyPanel.FromError(err).GetCode())
+	_, err = Resolve(context.Background(), "purpur", "1.19.x", "")
+	assert.Equal(t, "ErrNoMatchingVersion", SkyPanel.FromError(err).GetCode())
+	_, err = Resolve(context.Background(), "spigot", "latest", "")
+	assert.Equal(t, "ErrUnknownVersionProvider", SkyPanel.FromError(err).GetCode())
+}
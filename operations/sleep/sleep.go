@@ -1,8 +1,10 @@
 package sleep
 
 import (
-	"github.com/SkyPanel/SkyPanel/v3"
+	"context"
 	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
 )
 
 type Sleep struct {
@@ -10,6 +12,11 @@ type Sleep struct {
 }
 
 func (d Sleep) Run(args SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
-	time.Sleep(d.Duration)
-	return SkyPanel.OperationResult{Error: nil}
+	ctx := args.GetContext()
+	select {
+	case <-time.After(d.Duration):
+		return SkyPanel.OperationResult{Error: nil}
+	case <-ctx.Done():
+		return SkyPanel.OperationResult{Error: context.Cause(ctx)}
+	}
 }
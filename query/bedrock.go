@@ -0,0 +1,62 @@
+package query
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var raknetMagic = []byte{0x00, 0xFF, 0xFF, 0x00, 0xFE, 0xFE, 0xFE, 0xFE, 0xFD, 0xFD, 0xFD, 0xFD, 0x12, 0x34, 0x56, 0x78}
+
+var errBedrockResponse = errors.New("invalid bedrock ping response")
+
+// Bedrock uses the RakNet unconnected ping of Minecraft Bedrock Edition
+// Bedrock does not list player names, so only the counts are known
+func Bedrock(target Target) (Result, error) {
+	conn, err := dialUdp(target)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+
+	request := binary.BigEndian.AppendUint64([]byte{0x01}, uint64(time.Now().UnixMilli()))
+	request = append(request, raknetMagic...)
+	request = binary.BigEndian.AppendUint64(request, 0)
+	data, err := exchange(conn, request)
+	if err != nil {
+		return Result{}, err
+	}
+
+	//type, time, server guid and magic come before the length of the status
+	if len(data) < 35 || data[0] != 0x1C || !bytes.Equal(data[17:33], raknetMagic) {
+		return Result{}, errBedrockResponse
+	}
+	length := int(binary.BigEndian.Uint16(data[33:35]))
+	if len(data) < 35+length {
+		return Result{}, errBedrockResponse
+	}
+	return parseBedrock(string(data[35 : 35+length]))
+}
+
+// parseBedrock reads the status, which looks like MCPE;motd;protocol;version;online;max;server id;level name;...
+func parseBedrock(status string) (Result, error) {
+	parts := strings.Split(status, ";")
+	if len(parts) < 6 {
+		return Result{}, errBedrockResponse
+	}
+
+	result := Result{
+		Name:    parts[1],
+		Version: parts[3],
+		Players: []string{},
+	}
+	result.NumPlayers, _ = strconv.Atoi(parts[4])
+	result.MaxPlayers, _ = strconv.Atoi(parts[5])
+	if len(parts) > 7 {
+		result.Map = parts[7]
+	}
+	return result, nil
+}
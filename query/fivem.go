@@ -0,0 +1,43 @@
+package query
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+type fivemInfo struct {
+	Hostname   string          `json:"hostname"`
+	Clients    int             `json:"clients"`
+	MaxClients json.RawMessage `json:"sv_maxclients"`
+	Map        string          `json:"mapname"`
+}
+
+type fivemPlayer struct {
+	Name string `json:"name"`
+}
+
+// FiveM uses the info endpoints FXServer serves on its game port
+func FiveM(target Target) (Result, error) {
+	var info fivemInfo
+	if err := getJson(target, "/dynamic.json", &info); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Name:       info.Hostname,
+		Map:        info.Map,
+		NumPlayers: info.Clients,
+		Players:    []string{},
+	}
+	//sv_maxclients is a string in some versions
+	result.MaxPlayers, _ = strconv.Atoi(strings.Trim(string(info.MaxClients), `"`))
+
+	var players []fivemPlayer
+	if err := getJson(target, "/players.json", &players); err == nil {
+		for _, v := range players {
+			result.Players = append(result.Players, v.Name)
+		}
+	}
+	return result, nil
+}
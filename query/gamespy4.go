@@ -0,0 +1,83 @@
+package query
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var errGameSpy4Response = errors.New("invalid gamespy4 query response")
+
+// sessionId is sent with every GameSpy4 request, servers only keep the lower 4 bits of each byte
+var sessionId = []byte{0x01, 0x01, 0x01, 0x01}
+
+// GameSpy4 uses the UT3 query, which is also what the Minecraft query port answers to
+func GameSpy4(target Target) (Result, error) {
+	conn, err := dialUdp(target)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+
+	data, err := exchange(conn, append([]byte{0xFE, 0xFD, 0x09}, sessionId...))
+	if err != nil {
+		return Result{}, err
+	}
+	if len(data) < 6 || data[0] != 0x09 {
+		return Result{}, errGameSpy4Response
+	}
+	challenge, err := strconv.ParseInt(strings.TrimRight(string(data[5:]), "\x00"), 10, 32)
+	if err != nil {
+		return Result{}, errGameSpy4Response
+	}
+
+	request := append([]byte{0xFE, 0xFD, 0x00}, sessionId...)
+	request = binary.BigEndian.AppendUint32(request, uint32(int32(challenge)))
+	//padding asks for the full stat instead of the basic one
+	request = append(request, 0x00, 0x00, 0x00, 0x00)
+	data, err = exchange(conn, request)
+	if err != nil {
+		return Result{}, err
+	}
+	//type, session and the constant splitnum padding
+	if len(data) < 16 || data[0] != 0x00 {
+		return Result{}, errGameSpy4Response
+	}
+	return parseGameSpy4(data[16:]), nil
+}
+
+func parseGameSpy4(data []byte) Result {
+	reader := bytes.NewReader(data)
+	values := map[string]string{}
+	for {
+		key := readString(reader)
+		if key == "" {
+			break
+		}
+		values[key] = readString(reader)
+	}
+
+	result := Result{
+		Name:    values["hostname"],
+		Map:     values["map"],
+		Version: values["version"],
+		Players: []string{},
+	}
+	result.NumPlayers, _ = strconv.Atoi(values["numplayers"])
+	result.MaxPlayers, _ = strconv.Atoi(values["maxplayers"])
+
+	//the player section starts with \x01player_\x00\x00
+	if _, err := reader.Seek(10, 1); err != nil {
+		return result
+	}
+	for {
+		name := readString(reader)
+		if name == "" {
+			break
+		}
+		result.Players = append(result.Players, name)
+	}
+	return result
+}
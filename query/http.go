@@ -0,0 +1,33 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+)
+
+// getJson calls a game's HTTP api, giving up after the target's timeout
+func getJson(target Target, path string, result interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), target.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "GET", "http://"+target.address()+path, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Add("User-Agent", SkyPanel.Display+" https://github.com/SkyPanel/SkyPanel")
+
+	response, err := SkyPanel.Http().Do(request)
+	defer utils.CloseResponse(response)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("query responded with %s", response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
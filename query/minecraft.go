@@ -1,36 +1,23 @@
 package query
 
 import (
-	"fmt"
 	"github.com/dreamscached/minequery/v2"
 )
 
-type MinecraftResponse struct {
-	NumPlayers int      `json:"numPlayers"`
-	MaxPlayers int      `json:"maxPlayers"`
-	Version    string   `json:"version"`
-	Players    []string `json:"players"`
-}
-
-func Minecraft(ip string, port int) (MinecraftResponse, error) {
-	if port == 0 {
-		return MinecraftResponse{}, fmt.Errorf("port is required")
-	}
-	if ip == "" || ip == "0.0.0.0" {
-		ip = "127.0.0.1"
-	}
-
-	res, err := minequery.Ping17(ip, port)
+// Minecraft uses the server list ping of Minecraft Java Edition
+func Minecraft(target Target) (Result, error) {
+	pinger := minequery.NewPinger(minequery.WithTimeout(target.Timeout))
+	res, err := pinger.Ping17(target.Host, target.Port)
 	if err != nil {
-		return MinecraftResponse{}, err
+		return Result{}, err
 	}
 
-	var players []string
+	players := []string{}
 	for _, v := range res.SamplePlayers {
 		players = append(players, v.Nickname)
 	}
 
-	return MinecraftResponse{
+	return Result{
 		NumPlayers: res.OnlinePlayers,
 		MaxPlayers: res.MaxPlayers,
 		Version:    res.VersionName,
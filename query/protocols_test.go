@@ -0,0 +1,207 @@
+package query
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startUdpServer answers every packet with what handler returns, nil sends nothing
+func startUdpServer(t *testing.T, handler func(request []byte) []byte) int {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply := handler(append([]byte{}, buf[:n]...)); reply != nil {
+				_, _ = conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func httpTarget(t *testing.T, server *httptest.Server) Target {
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := strconv.Atoi(port)
+	return Target{Host: host, Port: p, Timeout: time.Second}
+}
+
+func cstring(values ...string) []byte {
+	var buf []byte
+	for _, v := range values {
+		buf = append(append(buf, v...), 0)
+	}
+	return buf
+}
+
+func TestSource(t *testing.T) {
+	challenge := []byte{0x0A, 0x0B, 0x0C, 0x0D}
+	port := startUdpServer(t, func(request []byte) []byte {
+		switch {
+		case request[4] == 'T' && !bytes.HasSuffix(request, challenge):
+			return append([]byte{0xFF, 0xFF, 0xFF, 0xFF, 'A'}, challenge...)
+		case request[4] == 'T':
+			reply := append([]byte{0xFF, 0xFF, 0xFF, 0xFF, 'I', 17}, cstring("My Server", "de_dust2", "csgo", "Counter-Strike")...)
+			reply = binary.LittleEndian.AppendUint16(reply, 730)
+			reply = append(reply, 2, 16, 0, 'd', 'l', 0, 1)
+			return append(reply, cstring("1.38.0.0")...)
+		case request[4] == 'U' && !bytes.HasSuffix(request, challenge):
+			return append([]byte{0xFF, 0xFF, 0xFF, 0xFF, 'A'}, challenge...)
+		case request[4] == 'U':
+			reply := []byte{0xFF, 0xFF, 0xFF, 0xFF, 'D', 2}
+			for i, name := range []string{"alice", "bob"} {
+				reply = append(append(reply, byte(i)), cstring(name)...)
+				reply = append(reply, 0, 0, 0, 0, 0, 0, 0, 0)
+			}
+			return reply
+		}
+		return nil
+	})
+
+	res, err := Query("source", Target{Port: port, Timeout: time.Second})
+	if assert.NoError(t, err) {
+		assert.Equal(t, Result{NumPlayers: 2, MaxPlayers: 16, Players: []string{"alice", "bob"}, Map: "de_dust2", Version: "1.38.0.0", Name: "My Server"}, res)
+	}
+}
+
+func TestGameSpy4(t *testing.T) {
+	port := startUdpServer(t, func(request []byte) []byte {
+		session := request[3:7]
+		switch request[2] {
+		case 0x09:
+			return append(append([]byte{0x09}, session...), cstring("-12345")...)
+		case 0x00:
+			if int32(binary.BigEndian.Uint32(request[7:11])) != -12345 {
+				return nil
+			}
+			reply := append(append([]byte{0x00}, session...), []byte("splitnum\x00\x80\x00")...)
+			reply = append(reply, cstring("hostname", "A Minecraft Server", "version", "1.21.4", "numplayers", "2", "maxplayers", "20", "map", "world", "")...)
+			reply = append(reply, []byte("\x01player_\x00\x00")...)
+			return append(reply, cstring("alice", "bob", "")...)
+		}
+		return nil
+	})
+
+	res, err := Query("gamespy4", Target{Host: "0.0.0.0", Port: port, Timeout: time.Second})
+	if assert.NoError(t, err) {
+		assert.Equal(t, Result{NumPlayers: 2, MaxPlayers: 20, Players: []string{"alice", "bob"}, Map: "world", Version: "1.21.4", Name: "A Minecraft Server"}, res)
+	}
+}
+
+func TestBedrock(t *testing.T) {
+	port := startUdpServer(t, func(request []byte) []byte {
+		if request[0] != 0x01 || !bytes.Equal(request[9:25], raknetMagic) {
+			return nil
+		}
+		status := "MCPE;Dedicated Server;766;1.21.50;3;10;13253860892328930865;Bedrock level;Survival;1;19132;19133;"
+		reply := append([]byte{0x1C}, request[1:9]...)
+		reply = binary.BigEndian.AppendUint64(reply, 42)
+		reply = append(reply, raknetMagic...)
+		reply = binary.BigEndian.AppendUint16(reply, uint16(len(status)))
+		return append(reply, status...)
+	})
+
+	res, err := Query("bedrock", Target{Port: port, Timeout: time.Second})
+	if assert.NoError(t, err) {
+		assert.Equal(t, Result{NumPlayers: 3, MaxPlayers: 10, Players: []string{}, Map: "Bedrock level", Version: "1.21.50", Name: "Dedicated Server"}, res)
+	}
+}
+
+func TestFiveM(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dynamic.json":
+			_, _ = w.Write([]byte(`{"clients":2,"gametype":"Freeroam","hostname":"My RP","mapname":"San Andreas","sv_maxclients":"48"}`))
+		case "/players.json":
+			_, _ = w.Write([]byte(`[{"id":1,"name":"alice"},{"id":2,"name":"bob"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	res, err := Query("fivem", httpTarget(t, server))
+	if assert.NoError(t, err) {
+		assert.Equal(t, Result{NumPlayers: 2, MaxPlayers: 48, Players: []string{"alice", "bob"}, Map: "San Andreas", Name: "My RP"}, res)
+	}
+}
+
+func TestTShock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/server/status" || r.URL.Query().Get("players") != "true" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("token") == "old" {
+			_, _ = w.Write([]byte(`{"status":"200","name":"Terraria","world":"Old World","playercount":2,"maxplayers":8,"players":"alice, bob"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"200","name":"Terraria","serverversion":"v1.4.4.9","world":"My World","playercount":1,"maxplayers":8,"players":[{"nickname":"alice","username":"","group":"guest"}]}`))
+	}))
+	defer server.Close()
+
+	target := httpTarget(t, server)
+	res, err := Query("terraria", target)
+	if assert.NoError(t, err) {
+		assert.Equal(t, Result{NumPlayers: 1, MaxPlayers: 8, Players: []string{"alice"}, Map: "My World", Version: "v1.4.4.9", Name: "Terraria"}, res)
+	}
+
+	target.Options = map[string]string{"token": "old"}
+	res, err = Query("tshock", target)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"alice", "bob"}, res.Players)
+	}
+}
+
+func TestMinecraftTimeout(t *testing.T) {
+	//accept the connection but never answer the ping
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	start := time.Now()
+	_, err = Minecraft(Target{Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port, Timeout: 200 * time.Millisecond})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestQueryUnknown(t *testing.T) {
+	assert.False(t, Supported("quake3"))
+	assert.True(t, Supported("Source"))
+
+	_, err := Query("quake3", Target{Port: 27960})
+	assert.Error(t, err)
+	_, err = Query("source", Target{})
+	assert.Error(t, err)
+}
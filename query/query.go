@@ -0,0 +1,110 @@
+package query
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Players is implemented by query responses which know how many players are online
+type Players interface {
+	PlayerCount() (online int, max int)
+}
+
+// Result is what a game answered, in the same shape whichever protocol was used
+type Result struct {
+	NumPlayers int      `json:"numPlayers"`
+	MaxPlayers int      `json:"maxPlayers"`
+	Players    []string `json:"players"`
+	Map        string   `json:"map,omitempty"`
+	Version    string   `json:"version,omitempty"`
+	Name       string   `json:"name,omitempty"`
+} //@name QueryResult
+
+func (r Result) PlayerCount() (int, int) {
+	return r.NumPlayers, r.MaxPlayers
+}
+
+// Target is where to query and any settings the protocol needs, such as a token
+type Target struct {
+	Host    string
+	Port    int
+	Timeout time.Duration
+	Options map[string]string
+}
+
+// Protocol asks a game for its status
+type Protocol func(target Target) (Result, error)
+
+const defaultTimeout = 5 * time.Second
+
+var protocols = map[string]Protocol{}
+
+// Register adds a protocol, replacing any with the same name
+func Register(name string, protocol Protocol) {
+	protocols[strings.ToLower(name)] = protocol
+}
+
+// Supported checks if there is a protocol with the name
+func Supported(name string) bool {
+	_, ok := protocols[strings.ToLower(name)]
+	return ok
+}
+
+// Query asks the game at the target using the named protocol
+func Query(name string, target Target) (Result, error) {
+	protocol, ok := protocols[strings.ToLower(name)]
+	if !ok {
+		return Result{}, fmt.Errorf("unknown query protocol %s", name)
+	}
+	if target.Port == 0 {
+		return Result{}, fmt.Errorf("port is required")
+	}
+	if target.Host == "" || target.Host == "0.0.0.0" {
+		target.Host = "127.0.0.1"
+	}
+	if target.Timeout <= 0 {
+		target.Timeout = defaultTimeout
+	}
+	return protocol(target)
+}
+
+func init() {
+	Register("minecraft", Minecraft)
+	Register("source", Source)
+	Register("a2s", Source)
+	Register("gamespy4", GameSpy4)
+	Register("ut3", GameSpy4)
+	Register("bedrock", Bedrock)
+	Register("fivem", FiveM)
+	Register("terraria", TShock)
+	Register("tshock", TShock)
+}
+
+func (t Target) address() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+}
+
+// exchange sends a UDP packet and waits for the answer
+func exchange(conn net.Conn, request []byte) ([]byte, error) {
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+func dialUdp(target Target) (net.Conn, error) {
+	conn, err := net.DialTimeout("udp", target.address(), target.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(target.Timeout))
+	return conn, nil
+}
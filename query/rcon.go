@@ -0,0 +1,103 @@
+package query
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	rconTypeResponse     = 0
+	rconTypeCommand      = 2
+	rconTypeAuthResponse = 2
+	rconTypeAuth         = 3
+
+	rconMaxPacket = 4096 + 10
+)
+
+var ErrRconAuthFailed = errors.New("rcon authentication failed")
+
+// Rcon runs a command over the Source RCON protocol, which Minecraft and most Source games speak, and returns the reply.
+// Replies split over several packets are not joined, the first one is returned.
+func Rcon(ip string, port int, password, command string, timeout time.Duration) (string, error) {
+	if port == 0 {
+		return "", fmt.Errorf("port is required")
+	}
+	if ip == "" || ip == "0.0.0.0" {
+		ip = "127.0.0.1"
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, strconv.Itoa(port)), timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if err = writeRconPacket(conn, 1, rconTypeAuth, password); err != nil {
+		return "", err
+	}
+	//some servers send an empty response before the auth response
+	for {
+		id, packetType, _, err := readRconPacket(conn)
+		if err != nil {
+			return "", err
+		}
+		if packetType != rconTypeAuthResponse {
+			continue
+		}
+		if id == -1 {
+			return "", ErrRconAuthFailed
+		}
+		break
+	}
+
+	if err = writeRconPacket(conn, 2, rconTypeCommand, command); err != nil {
+		return "", err
+	}
+	for {
+		id, packetType, body, err := readRconPacket(conn)
+		if err != nil {
+			return "", err
+		}
+		if id == 2 && packetType == rconTypeResponse {
+			return body, nil
+		}
+	}
+}
+
+func writeRconPacket(w io.Writer, id, packetType int32, body string) error {
+	buf := &bytes.Buffer{}
+	_ = binary.Write(buf, binary.LittleEndian, int32(len(body)+10))
+	_ = binary.Write(buf, binary.LittleEndian, id)
+	_ = binary.Write(buf, binary.LittleEndian, packetType)
+	buf.WriteString(body)
+	buf.Write([]byte{0, 0})
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func readRconPacket(r io.Reader) (id, packetType int32, body string, err error) {
+	var size int32
+	if err = binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return
+	}
+	if size < 10 || size > rconMaxPacket {
+		err = fmt.Errorf("invalid rcon packet size %d", size)
+		return
+	}
+
+	data := make([]byte, size)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return
+	}
+	id = int32(binary.LittleEndian.Uint32(data[0:4]))
+	packetType = int32(binary.LittleEndian.Uint32(data[4:8]))
+	body = string(bytes.TrimRight(data[8:], "\x00"))
+	return
+}
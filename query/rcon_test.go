@@ -0,0 +1,59 @@
+package query
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startRconServer(t *testing.T, password string) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				for {
+					id, packetType, body, err := readRconPacket(conn)
+					if err != nil {
+						return
+					}
+					switch packetType {
+					case rconTypeAuth:
+						_ = writeRconPacket(conn, id, rconTypeResponse, "")
+						if body != password {
+							id = -1
+						}
+						_ = writeRconPacket(conn, id, rconTypeAuthResponse, "")
+					case rconTypeCommand:
+						_ = writeRconPacket(conn, id, rconTypeResponse, "ran "+body)
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestRcon(t *testing.T) {
+	port := startRconServer(t, "secret")
+
+	reply, err := Rcon("127.0.0.1", port, "secret", "list", time.Second)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ran list", reply)
+	}
+
+	_, err = Rcon("127.0.0.1", port, "wrong", "list", time.Second)
+	assert.ErrorIs(t, err, ErrRconAuthFailed)
+}
@@ -0,0 +1,115 @@
+package query
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+)
+
+var sourceHeader = []byte{0xFF, 0xFF, 0xFF, 0xFF}
+
+var errSourceResponse = errors.New("invalid source query response")
+
+// Source uses the A2S queries of Valve games
+func Source(target Target) (Result, error) {
+	conn, err := dialUdp(target)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+
+	info, err := sourceRequest(conn, append([]byte("TSource Engine Query"), 0), 'I')
+	if err != nil {
+		return Result{}, err
+	}
+
+	reader := bytes.NewReader(info)
+	_, _ = reader.ReadByte() //protocol version
+	result := Result{Players: []string{}}
+	result.Name = readString(reader)
+	result.Map = readString(reader)
+	_ = readString(reader) //folder
+	_ = readString(reader) //game
+	var appId uint16
+	_ = binary.Read(reader, binary.LittleEndian, &appId)
+	players, _ := reader.ReadByte()
+	maxPlayers, err := reader.ReadByte()
+	if err != nil {
+		return Result{}, errSourceResponse
+	}
+	result.NumPlayers = int(players)
+	result.MaxPlayers = int(maxPlayers)
+	//bots, server type, environment, visibility and vac come before the version
+	if _, err = reader.Seek(5, 1); err == nil {
+		result.Version = readString(reader)
+	}
+
+	//player names are optional, some games do not answer this at all
+	list, err := sourceRequest(conn, append([]byte{'U'}, sourceHeader...), 'D')
+	if err != nil {
+		return result, nil
+	}
+	reader = bytes.NewReader(list)
+	count, _ := reader.ReadByte()
+	for i := 0; i < int(count); i++ {
+		if _, err = reader.ReadByte(); err != nil {
+			break
+		}
+		name := readString(reader)
+		//score and duration
+		if _, err = reader.Seek(8, 1); err != nil {
+			break
+		}
+		if name != "" {
+			result.Players = append(result.Players, name)
+		}
+	}
+	return result, nil
+}
+
+// sourceRequest sends a query, answering a challenge if the server asks for one, and returns the body after the expected type
+func sourceRequest(conn net.Conn, request []byte, expected byte) ([]byte, error) {
+	packet := append(append([]byte{}, sourceHeader...), request...)
+	for attempt := 0; attempt < 2; attempt++ {
+		data, err := exchange(conn, packet)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < 5 || !bytes.Equal(data[:4], sourceHeader) {
+			//split responses are only sent for large player lists, which we do not reassemble
+			return nil, errSourceResponse
+		}
+
+		switch data[4] {
+		case expected:
+			return data[5:], nil
+		case 'A':
+			if len(data) < 9 {
+				return nil, errSourceResponse
+			}
+			challenge := data[5:9]
+			if request[0] == 'U' {
+				packet = append(append(append([]byte{}, sourceHeader...), 'U'), challenge...)
+			} else {
+				packet = append(append(append([]byte{}, sourceHeader...), request...), challenge...)
+			}
+		default:
+			return nil, errors.New("unexpected source query response " + strconv.Itoa(int(data[4])))
+		}
+	}
+	return nil, errSourceResponse
+}
+
+// readString reads a null terminated string
+func readString(reader *bytes.Reader) string {
+	var buf []byte
+	for {
+		b, err := reader.ReadByte()
+		if err != nil || b == 0 {
+			return string(buf)
+		}
+		buf = append(buf, b)
+	}
+}
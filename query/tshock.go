@@ -0,0 +1,57 @@
+package query
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+type tshockStatus struct {
+	Name          string          `json:"name"`
+	World         string          `json:"world"`
+	ServerVersion string          `json:"serverversion"`
+	PlayerCount   int             `json:"playercount"`
+	MaxPlayers    int             `json:"maxplayers"`
+	Players       json.RawMessage `json:"players"`
+}
+
+// TShock uses the REST api of TShock for Terraria
+// Newer versions need a token, which is set with the token option
+func TShock(target Target) (Result, error) {
+	path := "/v2/server/status?players=true"
+	if token := target.Options["token"]; token != "" {
+		path += "&token=" + url.QueryEscape(token)
+	}
+
+	var status tshockStatus
+	if err := getJson(target, path, &status); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Name:       status.Name,
+		Map:        status.World,
+		Version:    status.ServerVersion,
+		NumPlayers: status.PlayerCount,
+		MaxPlayers: status.MaxPlayers,
+		Players:    []string{},
+	}
+
+	//players is a list of objects, older versions give a comma separated string
+	var players []struct {
+		Nickname string `json:"nickname"`
+	}
+	var names string
+	if json.Unmarshal(status.Players, &players) == nil {
+		for _, v := range players {
+			result.Players = append(result.Players, v.Nickname)
+		}
+	} else if json.Unmarshal(status.Players, &names) == nil {
+		for _, v := range strings.Split(names, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result.Players = append(result.Players, v)
+			}
+		}
+	}
+	return result, nil
+}
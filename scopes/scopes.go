@@ -8,16 +8,17 @@ type Scope struct {
 } // @name Scopes
 
 var (
-	ScopeAdmin       = registerNonServerScope("admin")
-	ScopeLogin       = registerNonServerScope("login")        //can you log in
-	ScopeOAuth2Auth  = registerNonServerScope("oauth2.auth")  //can you validate user credentials over OAuth2
-	ScopeNodesView   = registerNonServerScope("nodes.view")   //can you globally view nodes
-	ScopeNodesCreate = registerNonServerScope("nodes.create") //can you create nodes
-	ScopeNodesEdit   = registerNonServerScope("nodes.edit")   //can you edit an existing node
-	ScopeNodesDelete = registerNonServerScope("nodes.delete") //can you delete a node
-	ScopeNodesDeploy = registerNonServerScope("nodes.deploy") //can you deploy the node (this has secret info, which is why it's special)
-	ScopeSelfEdit    = registerNonServerScope("self.edit")    //can you manage your own account
-	ScopeSelfClients = registerNonServerScope("self.clients") //can the user create and manage OAuth2 clients for their own account
+	ScopeAdmin        = registerNonServerScope("admin")
+	ScopeLogin        = registerNonServerScope("login")         //can you log in
+	ScopeOAuth2Auth   = registerNonServerScope("oauth2.auth")   //can you validate user credentials over OAuth2
+	ScopeNodesView    = registerNonServerScope("nodes.view")    //can you globally view nodes
+	ScopeNodesCreate  = registerNonServerScope("nodes.create")  //can you create nodes
+	ScopeNodesEdit    = registerNonServerScope("nodes.edit")    //can you edit an existing node
+	ScopeNodesDelete  = registerNonServerScope("nodes.delete")  //can you delete a node
+	ScopeNodesDeploy  = registerNonServerScope("nodes.deploy")  //can you deploy the node (this has secret info, which is why it's special)
+	ScopeNodesMetrics = registerNonServerScope("nodes.metrics") //can you scrape the metrics of a node
+	ScopeSelfEdit     = registerNonServerScope("self.edit")     //can you manage your own account
+	ScopeSelfClients  = registerNonServerScope("self.clients")  //can the user create and manage OAuth2 clients for their own account
 
 	ScopeServerCreate         = registerNonServerScope("server.create")
 	ScopeServerView           = registerServerScope("server.view")
@@ -61,6 +62,8 @@ var (
 	ScopeServerBackupCreate  = registerServerScope("server.backup.create")
 	ScopeServerBackupRestore = registerServerScope("server.backup.restore")
 	ScopeServerBackupDelete  = registerServerScope("server.backup.delete")
+	ScopeServerCrashView     = registerServerScope("server.crashes.view")
+	ScopeServerCrashDelete   = registerServerScope("server.crashes.delete")
 
 	ScopeSettingsEdit = registerNonServerScope("settings.edit")
 
@@ -76,6 +79,10 @@ var (
 	ScopeUserPermsEdit  = registerNonServerScope("users.perms.edit")
 
 	ScopeUptimeView = registerNonServerScope("uptime.view")
+	ScopeUptimeEdit = registerNonServerScope("uptime.edit")
+
+	ScopeAlertsView = registerNonServerScope("alerts.view")
+	ScopeAlertsEdit = registerNonServerScope("alerts.edit")
 
 	ScopePanel = registerNonServerScope("panel")
 )
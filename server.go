@@ -13,6 +13,8 @@ type Server struct {
 	Groups                []Group                   `json:"groups,omitempty"`
 	Installation          []ConditionalMetadataType `json:"install"`
 	Uninstallation        []ConditionalMetadataType `json:"uninstall"`
+	InstallTimeout        string                    `json:"installTimeout,omitempty"`  //longest the whole install may take, such as 1h, empty means no limit
+	InstallSnapshot       bool                      `json:"installSnapshot,omitempty"` //snapshot the server folder before installing, and put it back if the install fails
 	Execution             Execution                 `json:"run"`
 	Environment           MetadataType              `json:"environment"`
 	SupportedEnvironments []MetadataType            `json:"supportedEnvironments,omitempty"`
@@ -20,6 +22,10 @@ type Server struct {
 	Stats                 MetadataType              `json:"stats,omitempty"`
 	Query                 MetadataType              `json:"query,omitempty"`
 	KeepAlive             KeepAlive                 `json:"keepAlive,omitempty"`
+	DiskQuota             int64                     `json:"diskQuota,omitempty"` //in MiB, 0 means no quota
+	CrashReport           CrashReport               `json:"crashReport,omitempty"`
+	HealthCheck           HealthCheck               `json:"healthCheck,omitempty"`
+	Deploy                *GitDeploy                `json:"deploy,omitempty"` //repository the deploy endpoint and webhook pull from
 } //@name ServerDefinition
 
 type Execution struct {
@@ -64,6 +70,24 @@ type KeepAlive struct {
 	Command   string `json:"command"`
 } //@name KeepAlive
 
+type CrashReport struct {
+	Files        []string `json:"files,omitempty"`        //globs relative to the server folder, such as crash-reports/*.txt
+	ConsoleLines int      `json:"consoleLines,omitempty"` //0 uses the default
+} //@name CrashReportDefinition
+
+type HealthCheck struct {
+	Type      string `json:"type,omitempty"`      //query, tcp, rcon or console, empty disables the check
+	Interval  string `json:"interval,omitempty"`  //time between checks, such as 30s
+	Timeout   string `json:"timeout,omitempty"`   //how long a check may take
+	Grace     string `json:"grace,omitempty"`     //time after starting before the first check, so the server can load
+	Threshold int    `json:"threshold,omitempty"` //failed checks in a row before the server is restarted
+	Host      string `json:"host,omitempty"`      //tcp and rcon, defaults to ${ip}
+	Port      string `json:"port,omitempty"`      //tcp and rcon, defaults to ${port}
+	Password  string `json:"password,omitempty"`  //rcon
+	Command   string `json:"command,omitempty"`   //rcon command, or console command which makes the server print the heartbeat
+	Expect    string `json:"expect,omitempty"`    //regex the rcon reply or the console output must match
+} //@name HealthCheck
+
 func (s *Server) CopyFrom(replacement *Server) {
 	s.Variables = replacement.Variables
 	s.Type = replacement.Type
@@ -71,11 +95,17 @@ func (s *Server) CopyFrom(replacement *Server) {
 	s.Display = replacement.Display
 	s.Installation = replacement.Installation
 	s.Uninstallation = replacement.Uninstallation
+	s.InstallTimeout = replacement.InstallTimeout
+	s.InstallSnapshot = replacement.InstallSnapshot
 	s.Environment = replacement.Environment
 	s.Requirements = replacement.Requirements
 	s.SupportedEnvironments = replacement.SupportedEnvironments
 	s.Groups = replacement.Groups
 	s.Stats = replacement.Stats
+	s.DiskQuota = replacement.DiskQuota
+	s.CrashReport = replacement.CrashReport
+	s.HealthCheck = replacement.HealthCheck
+	s.Deploy = replacement.Deploy
 }
 
 func (s *Server) DataToMap() map[string]interface{} {
@@ -89,6 +119,8 @@ func (s *Server) DataToMap() map[string]interface{} {
 }
 
 type DaemonServer interface {
+	Id() string
+
 	GetFileServer() files.FileServer
 
 	Extract(source, destination string) error
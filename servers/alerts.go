@@ -0,0 +1,67 @@
+package servers
+
+import (
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/alerts"
+	"github.com/SkyPanel/SkyPanel/v3/database"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/services"
+)
+
+var alertTracker = alerts.NewTracker()
+
+// evaluateAlertRules runs the global rules and the rules of the server against its latest stats
+func evaluateAlertRules(server *Server, isRunning bool, stats *SkyPanel.ServerStats) {
+	db, err := database.GetConnection()
+	if err != nil {
+		return
+	}
+
+	rs := &services.AlertRule{DB: db}
+	rules, err := rs.GetEnabled()
+	if err != nil {
+		logging.Error.Printf("Error loading alert rules: %s", err)
+		return
+	}
+
+	known := make(map[uint]bool, len(rules))
+	for _, v := range rules {
+		known[v.ID] = true
+	}
+	alertTracker.Forget(func(ruleId uint) bool {
+		return known[ruleId]
+	})
+
+	status := alerts.Status{
+		ServerId:   server.Id(),
+		Running:    isRunning,
+		Installing: server.GetEnvironment().IsInstalling(),
+		Crashes:    server.CrashCount(),
+	}
+	vars := alerts.VariablesFor(status, stats)
+	now := time.Now()
+
+	for _, rule := range rules {
+		if rule.ServerID != nil && *rule.ServerID != server.Id() {
+			continue
+		}
+
+		event, err := alertTracker.Evaluate(rule.Rule(), server.Id(), vars, now)
+		if err != nil {
+			server.Log(logging.Error, "Error evaluating alert rule %d (%s): %s", rule.ID, rule.Name, err)
+			continue
+		}
+
+		switch event {
+		case alerts.EventFiring:
+			server.Log(logging.Info, "Alert rule %s is firing", rule.Name)
+			server.notifyRule(rule)
+		case alerts.EventRecovered:
+			if rule.NotifyRecovery {
+				server.notifyRuleRecovery(rule)
+			}
+		}
+	}
+}
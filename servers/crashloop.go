@@ -0,0 +1,135 @@
+package servers
+
+import (
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+)
+
+// recordCrash records a crash and updates CrashCounter with the crashes inside the window
+func (p *Server) recordCrash(at time.Time) {
+	p.crashLock.Lock()
+	defer p.crashLock.Unlock()
+
+	p.crashTimes = append(p.crashTimes, at)
+	p.pruneCrashTimes(at)
+}
+
+// pruneCrashTimes forgets the crashes which fell out of the window, crashLock has to be held
+func (p *Server) pruneCrashTimes(now time.Time) {
+	window := time.Duration(config.CrashWindow.Value()) * time.Second
+	if window > 0 {
+		i := 0
+		for i < len(p.crashTimes) && now.Sub(p.crashTimes[i]) > window {
+			i++
+		}
+		p.crashTimes = p.crashTimes[i:]
+	}
+	p.CrashCounter = len(p.crashTimes)
+	if p.CrashCounter <= config.CrashLimit.Value() {
+		p.crashLooping = false
+	}
+}
+
+// scheduleCrashRestart restarts the server after a delay which doubles with each crash in the window.
+// With more crashes than the limit it stops restarting it and marks it as crash looping
+func (p *Server) scheduleCrashRestart() {
+	p.crashLock.Lock()
+	defer p.crashLock.Unlock()
+
+	if p.restartTimer != nil {
+		p.restartTimer.Stop()
+		p.restartTimer = nil
+	}
+
+	if p.CrashCounter > config.CrashLimit.Value() {
+		p.crashLooping = true
+		p.nextRestart = time.Time{}
+		p.Log(logging.Error, "Server crashed %d times, not restarting it until the crashes are reset", p.CrashCounter)
+		p.RunningEnvironment.DisplayToConsole(true, "Server is crash-looping, it will not be restarted automatically\n")
+		_ = p.RunningEnvironment.StatusTracker.WriteMessage(SkyPanel.Transmission{
+			Message: SkyPanel.ServerRunning{CrashLooping: true},
+			Type:    SkyPanel.MessageTypeStatus,
+		})
+		return
+	}
+
+	delay := crashBackoff(p.CrashCounter)
+	p.nextRestart = time.Now().Add(delay)
+	p.restartTimer = time.AfterFunc(delay, func() {
+		p.crashLock.Lock()
+		p.restartTimer = nil
+		p.nextRestart = time.Time{}
+		p.crashLock.Unlock()
+		StartViaService(p)
+	})
+	p.RunningEnvironment.DisplayToConsole(true, "Restarting server in %s\n", delay)
+}
+
+// crashBackoff is the delay before restarting after the given number of crashes in the window
+func crashBackoff(crashes int) time.Duration {
+	delay := time.Duration(config.CrashBackoff.Value()) * time.Second
+	limit := time.Duration(config.CrashBackoffMax.Value()) * time.Second
+	for i := 1; i < crashes && (limit <= 0 || delay < limit); i++ {
+		delay *= 2
+	}
+	if limit > 0 && delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+// cancelCrashRestart cancels the pending restart, so stopping the server does not start it again
+func (p *Server) cancelCrashRestart() {
+	p.crashLock.Lock()
+	defer p.crashLock.Unlock()
+
+	if p.restartTimer != nil {
+		p.restartTimer.Stop()
+		p.restartTimer = nil
+	}
+	p.nextRestart = time.Time{}
+}
+
+// ResetCrashes forgets the earlier crashes, so the server is restarted on its own again
+func (p *Server) ResetCrashes() {
+	p.cancelCrashRestart()
+
+	p.crashLock.Lock()
+	defer p.crashLock.Unlock()
+
+	p.crashTimes = nil
+	p.CrashCounter = 0
+	p.crashLooping = false
+}
+
+// RecentCrashes returns how many crashes there were inside the window
+func (p *Server) RecentCrashes() int {
+	p.crashLock.Lock()
+	defer p.crashLock.Unlock()
+
+	p.pruneCrashTimes(time.Now())
+	return p.CrashCounter
+}
+
+func (p *Server) IsCrashLooping() bool {
+	p.crashLock.Lock()
+	defer p.crashLock.Unlock()
+
+	p.pruneCrashTimes(time.Now())
+	return p.crashLooping
+}
+
+// NextRestart returns when the server is restarted after a crash, nil if no restart is pending
+func (p *Server) NextRestart() *time.Time {
+	p.crashLock.Lock()
+	defer p.crashLock.Unlock()
+
+	if p.nextRestart.IsZero() {
+		return nil
+	}
+	next := p.nextRestart
+	return &next
+}
@@ -0,0 +1,57 @@
+package servers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrashBackoff(t *testing.T) {
+	_ = config.CrashBackoff.Set(5, false)
+	_ = config.CrashBackoffMax.Set(30, false)
+
+	assert.Equal(t, 5*time.Second, crashBackoff(1))
+	assert.Equal(t, 10*time.Second, crashBackoff(2))
+	assert.Equal(t, 20*time.Second, crashBackoff(3))
+	assert.Equal(t, 30*time.Second, crashBackoff(4))
+	assert.Equal(t, 30*time.Second, crashBackoff(50))
+}
+
+func TestCrashLoop(t *testing.T) {
+	_ = config.CrashLimit.Set(2, false)
+	_ = config.CrashWindow.Set(3600, false)
+	_ = config.CrashBackoff.Set(3600, false)
+	_ = config.CrashBackoffMax.Set(0, false)
+
+	p := &Server{
+		Server: SkyPanel.Server{Identifier: "crashloop"},
+		RunningEnvironment: &SkyPanel.Environment{
+			ConsoleBuffer:  SkyPanel.CreateCache(),
+			ConsoleTracker: SkyPanel.CreateTracker(),
+			StatusTracker:  SkyPanel.CreateTracker(),
+		},
+	}
+
+	//crashes from long ago do not count
+	p.recordCrash(time.Now().Add(-48 * time.Hour))
+	p.recordCrash(time.Now().Add(-47 * time.Hour))
+	p.recordCrash(time.Now())
+	assert.Equal(t, 1, p.RecentCrashes())
+
+	p.scheduleCrashRestart()
+	assert.False(t, p.IsCrashLooping())
+	assert.NotNil(t, p.NextRestart())
+
+	p.recordCrash(time.Now())
+	p.recordCrash(time.Now())
+	p.scheduleCrashRestart()
+	assert.True(t, p.IsCrashLooping())
+	assert.Nil(t, p.NextRestart())
+
+	p.ResetCrashes()
+	assert.False(t, p.IsCrashLooping())
+	assert.Equal(t, 0, p.RecentCrashes())
+}
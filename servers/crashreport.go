@@ -0,0 +1,347 @@
+package servers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+)
+
+// defaultCrashConsoleLines is how many console lines are kept when the template does not say
+const defaultCrashConsoleLines = 100
+
+// maxCrashFiles and maxCrashFileSize limit what is copied of the diagnostic files,
+// only the end of bigger files is kept
+const maxCrashFiles = 10
+const maxCrashFileSize = 1024 * 1024
+
+const crashReportFile = "report.json"
+const crashFilesFolder = "files"
+
+type CrashReport struct {
+	Id       string                `json:"id"`
+	ServerId string                `json:"serverId"`
+	ExitCode int                   `json:"exitCode"`
+	Reason   string                `json:"reason,omitempty"` //set when the daemon stopped the server itself, such as a failed health check
+	Time     time.Time             `json:"time"`
+	Uptime   int64                 `json:"uptime"` //seconds the process ran before the crash
+	Console  []string              `json:"console,omitempty"`
+	Stats    *SkyPanel.ServerStats `json:"stats,omitempty"`
+	Files    []CrashReportFile     `json:"files,omitempty"`
+} //@name CrashReport
+
+type CrashReportFile struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"` //where the file was in the server folder
+	Size      int64  `json:"size"`
+	Truncated bool   `json:"truncated,omitempty"`
+} //@name CrashReportFile
+
+// captureCrashReport saves what is known about a crash: the console, the last stats
+// and the diagnostic files the template lists
+func (p *Server) captureCrashReport(exitCode int, reason string) *CrashReport {
+	now := time.Now()
+	report := &CrashReport{
+		Id:       strconv.FormatInt(now.UnixMilli(), 10),
+		ServerId: p.Id(),
+		ExitCode: exitCode,
+		Reason:   reason,
+		Time:     now,
+		Console:  p.crashConsole(),
+	}
+	if !p.startedAt.IsZero() {
+		report.Uptime = int64(now.Sub(p.startedAt).Seconds())
+	}
+
+	stateTrackingLock.Lock()
+	if state, exists := serverStateTracking[p.Id()]; exists && state.lastStats != nil {
+		stats := *state.lastStats
+		report.Stats = &stats
+	}
+	stateTrackingLock.Unlock()
+
+	folder := filepath.Join(p.getCrashReportsDirectory(), report.Id)
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		p.Log(logging.Error, "Error saving crash report: %s", err)
+		return report
+	}
+
+	report.Files = p.copyCrashFiles(filepath.Join(folder, crashFilesFolder))
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(folder, crashReportFile), data, 0644)
+	}
+	if err != nil {
+		p.Log(logging.Error, "Error saving crash report: %s", err)
+	}
+
+	p.pruneCrashReports()
+	return report
+}
+
+func (p *Server) crashConsole() []string {
+	if p.RunningEnvironment == nil || p.RunningEnvironment.ConsoleBuffer == nil {
+		return nil
+	}
+
+	limit := p.CrashReport.ConsoleLines
+	if limit <= 0 {
+		limit = defaultCrashConsoleLines
+	}
+
+	console, _ := p.RunningEnvironment.GetConsole()
+	lines := strings.Split(strings.TrimRight(string(console), "\r\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	if len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+	return lines
+}
+
+// copyCrashFiles copies the files matching the patterns of the template.
+// Only files changed since the process started are copied, so files of earlier crashes are not repeated
+func (p *Server) copyCrashFiles(target string) []CrashReportFile {
+	if len(p.CrashReport.Files) == 0 {
+		return nil
+	}
+
+	fileServer := p.GetFileServer()
+	if fileServer == nil {
+		return nil
+	}
+
+	results := make([]CrashReportFile, 0)
+	names := make(map[string]bool)
+	for _, pattern := range p.CrashReport.Files {
+		matches, err := fs.Glob(fileServer, path.Clean(filepath.ToSlash(pattern)))
+		if err != nil {
+			p.Log(logging.Error, "Invalid crash report pattern %s: %s", pattern, err)
+			continue
+		}
+
+		for _, match := range matches {
+			if len(results) >= maxCrashFiles {
+				return results
+			}
+
+			info, err := fileServer.Stat(match)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			if !p.startedAt.IsZero() && info.ModTime().Before(p.startedAt) {
+				continue
+			}
+
+			name := path.Base(match)
+			if names[name] {
+				continue
+			}
+
+			file, err := p.copyCrashFile(match, info.Size(), filepath.Join(target, name))
+			if err != nil {
+				p.Log(logging.Error, "Error copying %s to the crash report: %s", match, err)
+				continue
+			}
+			names[name] = true
+			results = append(results, file)
+		}
+	}
+	return results
+}
+
+func (p *Server) copyCrashFile(source string, size int64, target string) (CrashReportFile, error) {
+	result := CrashReportFile{Name: filepath.Base(target), Path: source, Size: size}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return result, err
+	}
+
+	in, err := p.GetFileServer().OpenFile(source, os.O_RDONLY, 0644)
+	if err != nil {
+		return result, err
+	}
+	defer utils.Close(in)
+
+	if size > maxCrashFileSize {
+		if _, err = in.Seek(size-maxCrashFileSize, io.SeekStart); err != nil {
+			return result, err
+		}
+		result.Truncated = true
+	}
+
+	data, err := io.ReadAll(io.LimitReader(in, maxCrashFileSize))
+	if err != nil {
+		return result, err
+	}
+	if result.Truncated {
+		data = trimPartialStart(data)
+	}
+	result.Size = int64(len(data))
+
+	err = os.WriteFile(target, data, 0644)
+	return result, err
+}
+
+// trimPartialStart drops the cut line at the start of the end of a file,
+// when there is no line break it only drops the bytes of a cut character
+func trimPartialStart(data []byte) []byte {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return data[i+1:]
+	}
+	for len(data) > 0 && !utf8.RuneStart(data[0]) {
+		data = data[1:]
+	}
+	return data
+}
+
+// pruneCrashReports removes the oldest reports when there are more than configured
+func (p *Server) pruneCrashReports() {
+	limit := config.CrashReportsMax.Value()
+	if limit <= 0 {
+		return
+	}
+
+	ids, err := p.crashReportIds()
+	if err != nil || len(ids) <= limit {
+		return
+	}
+
+	for _, id := range ids[limit:] {
+		if err = os.RemoveAll(filepath.Join(p.getCrashReportsDirectory(), id)); err != nil {
+			p.Log(logging.Error, "Error removing old crash report %s: %s", id, err)
+		}
+	}
+}
+
+// crashReportIds returns the ids of the saved reports, newest first
+func (p *Server) crashReportIds() ([]string, error) {
+	entries, err := os.ReadDir(p.getCrashReportsDirectory())
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, v := range entries {
+		if v.IsDir() && isCrashReportId(v.Name()) {
+			ids = append(ids, v.Name())
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.ParseInt(ids[i], 10, 64)
+		b, _ := strconv.ParseInt(ids[j], 10, 64)
+		return a > b
+	})
+	return ids, nil
+}
+
+// GetCrashReports returns the crash reports newest first, without their console
+func (p *Server) GetCrashReports() ([]*CrashReport, error) {
+	ids, err := p.crashReportIds()
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*CrashReport, 0, len(ids))
+	for _, id := range ids {
+		report, err := p.GetCrashReport(id)
+		if err != nil {
+			p.Log(logging.Error, "Error reading crash report %s: %s", id, err)
+			continue
+		}
+		report.Console = nil
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (p *Server) GetCrashReport(id string) (*CrashReport, error) {
+	if !isCrashReportId(id) {
+		return nil, SkyPanel.ErrCrashReportNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(p.getCrashReportsDirectory(), id, crashReportFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, SkyPanel.ErrCrashReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CrashReport{}
+	err = json.Unmarshal(data, report)
+	return report, err
+}
+
+// GetCrashReportFile opens one of the diagnostic files copied into a report
+func (p *Server) GetCrashReportFile(id, name string) (*FileData, error) {
+	report, err := p.GetCrashReport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range report.Files {
+		if v.Name != name {
+			continue
+		}
+
+		file, err := os.Open(filepath.Join(p.getCrashReportsDirectory(), id, crashFilesFolder, v.Name))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, SkyPanel.ErrFileNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			utils.Close(file)
+			return nil, err
+		}
+		return &FileData{Contents: file, ContentLength: info.Size(), Name: v.Name}, nil
+	}
+	return nil, SkyPanel.ErrFileNotFound
+}
+
+func (p *Server) DeleteCrashReport(id string) error {
+	if _, err := p.GetCrashReport(id); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(p.getCrashReportsDirectory(), id))
+}
+
+func (p *Server) deleteCrashReports() error {
+	return os.RemoveAll(p.getCrashReportsDirectory())
+}
+
+func (p *Server) getCrashReportsDirectory() string {
+	return filepath.Join(config.CrashReportsFolder.Value(), p.Id())
+}
+
+func isCrashReportId(id string) bool {
+	_, err := strconv.ParseUint(id, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,117 @@
+package servers
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrashReport(t *testing.T) {
+	_ = config.CrashReportsFolder.Set(t.TempDir(), false)
+	_ = config.CrashReportsMax.Set(2, false)
+
+	serverDir := t.TempDir()
+	fileServer, err := files.NewFileServer(serverDir, os.Getuid(), os.Getgid())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer fileServer.Close()
+
+	old := filepath.Join(serverDir, "hs_err_pid1.log")
+	if !assert.NoError(t, os.WriteFile(old, []byte("old crash"), 0644)) {
+		return
+	}
+	_ = os.Chtimes(old, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour))
+
+	if !assert.NoError(t, os.MkdirAll(filepath.Join(serverDir, "crash-reports"), 0755)) {
+		return
+	}
+	if !assert.NoError(t, os.WriteFile(filepath.Join(serverDir, "crash-reports", "crash-1.txt"), []byte("the crash"), 0644)) {
+		return
+	}
+
+	console := SkyPanel.CreateCache()
+	_, _ = console.Write([]byte("line 1\nline 2\nline 3\n"))
+
+	p := &Server{
+		Server: SkyPanel.Server{
+			Identifier:  "crashtest",
+			CrashReport: SkyPanel.CrashReport{Files: []string{"crash-reports/*.txt", "hs_err_pid*.log"}, ConsoleLines: 2},
+		},
+		RunningEnvironment: &SkyPanel.Environment{ConsoleBuffer: console},
+		fileServer:         fileServer,
+		startedAt:          time.Now().Add(-time.Minute),
+	}
+
+	report := p.captureCrashReport(137, "")
+	assert.Equal(t, 137, report.ExitCode)
+	assert.Equal(t, []string{"line 2", "line 3"}, report.Console)
+	assert.GreaterOrEqual(t, report.Uptime, int64(60))
+	if assert.Len(t, report.Files, 1) {
+		assert.Equal(t, "crash-1.txt", report.Files[0].Name)
+		assert.Equal(t, "crash-reports/crash-1.txt", report.Files[0].Path)
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		saved, err := p.GetCrashReport(report.Id)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, report.Console, saved.Console)
+
+		data, err := p.GetCrashReportFile(report.Id, "crash-1.txt")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer data.Contents.Close()
+		contents, _ := io.ReadAll(data.Contents)
+		assert.Equal(t, "the crash", string(contents))
+
+		_, err = p.GetCrashReportFile(report.Id, "../report.json")
+		assert.ErrorIs(t, err, SkyPanel.ErrFileNotFound)
+
+		_, err = p.GetCrashReport("../crashtest")
+		assert.ErrorIs(t, err, SkyPanel.ErrCrashReportNotFound)
+	})
+
+	t.Run("Prune", func(t *testing.T) {
+		for range 2 {
+			time.Sleep(2 * time.Millisecond)
+			p.captureCrashReport(1, "")
+		}
+
+		reports, err := p.GetCrashReports()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, reports, 2)
+		for _, v := range reports {
+			assert.NotEqual(t, report.Id, v.Id)
+			assert.Nil(t, v.Console)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		reports, _ := p.GetCrashReports()
+		if !assert.NotEmpty(t, reports) {
+			return
+		}
+		assert.NoError(t, p.DeleteCrashReport(reports[0].Id))
+		assert.ErrorIs(t, p.DeleteCrashReport(reports[0].Id), SkyPanel.ErrCrashReportNotFound)
+	})
+}
+
+func TestTrimPartialStart(t *testing.T) {
+	assert.Equal(t, "line 2\nline 3\n", string(trimPartialStart([]byte("e 1\nline 2\nline 3\n"))))
+	//the first byte of ñ was cut off, only its second byte is left
+	assert.Equal(t, "abc", string(trimPartialStart([]byte("ñabc")[1:])))
+	assert.Equal(t, "ñabc", string(trimPartialStart([]byte("ñabc"))))
+	assert.Empty(t, trimPartialStart([]byte("no break\n")))
+}
@@ -0,0 +1,27 @@
+package servers
+
+import (
+	"context"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/gitdeploy"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+)
+
+// RunDeploy pulls the git deployment of the server into its folder
+func (p *Server) RunDeploy(ctx context.Context) (gitdeploy.Result, error) {
+	if p.Server.Deploy == nil || p.Server.Deploy.Repo == "" {
+		return gitdeploy.Result{}, SkyPanel.ErrDeployNotConfigured
+	}
+	deploy := *p.Server.Deploy
+
+	p.RunningEnvironment.DisplayToConsole(true, "Deploying %s\n", deploy.Repo)
+	result, err := gitdeploy.Deploy(ctx, p.GetFileServer(), p.Id(), deploy)
+	if err != nil {
+		p.Log(logging.Error, "Error deploying %s: %s", deploy.Repo, err)
+		p.RunningEnvironment.DisplayToConsole(true, "Deploy failed: %s\n", err.Error())
+		return result, err
+	}
+	p.RunningEnvironment.DisplayToConsole(true, "Deployed commit %s, %d files changed and %d removed\n", result.Commit, len(result.Changed), len(result.Removed))
+	return result, nil
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
+	"github.com/shirou/gopsutil/process"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/SkyPanel/SkyPanel/v3"
 	"github.com/SkyPanel/SkyPanel/v3/config"
@@ -25,6 +26,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -40,6 +42,14 @@ type Docker struct {
 	Labels        map[string]string    `json:"labels,omitempty"`
 	Config        container.Config     `json:"config,omitempty"`
 
+	Limits         SkyPanel.ResourceLimits `json:"limits,omitempty"`
+	MemorySwap     int64                   `json:"memorySwap,omitempty"`
+	PortVariables  []PortVariable          `json:"portVariables,omitempty"`
+	Networks       []string                `json:"networks,omitempty"`
+	ReadOnlyRootFs bool                    `json:"readOnlyRootFs,omitempty"`
+	Tmpfs          map[string]string       `json:"tmpfs,omitempty"`
+	Volumes        []Volume                `json:"volumes,omitempty"`
+
 	connection       types.HijackedResponse
 	cli              *client.Client
 	downloadingImage bool
@@ -48,6 +58,8 @@ type Docker struct {
 	lastStatTime     time.Time
 	//disableStdin        bool
 	disableSpecialStats bool
+	networkRates        SkyPanel.RateCounter
+	ioRates             SkyPanel.RateCounter
 }
 
 func (d *Docker) ExecuteAsyncImpl(environment *SkyPanel.Environment, steps SkyPanel.ExecutionData) error {
@@ -224,6 +236,31 @@ func (d *Docker) GetStatsImpl(environment *SkyPanel.Environment) (*SkyPanel.Serv
 		Cpu:    calculateCPUPercent(data),
 	}
 
+	d.collectIOStats(data, stats)
+
+	//the processes of the container are only visible when docker runs on this machine
+	var pid int
+	var oomKilled bool
+	if info, err := dockerClient.ContainerInspect(ctx, environment.ServerId); err == nil && info.State != nil {
+		pid, oomKilled = info.State.Pid, info.State.OOMKilled
+	}
+	stats.OpenFiles = openFiles(pid)
+
+	if d.Limits.IsSet() {
+		stats.Limits = &SkyPanel.LimitStats{
+			MemoryCurrent:      data.MemoryStats.Usage,
+			CpuQuota:           d.Limits.CpuQuota,
+			CpuThrottledMicros: data.CPUStats.ThrottlingData.ThrottledTime / 1000,
+			PidsCurrent:        data.PidsStats.Current,
+			PidsMax:            data.PidsStats.Limit,
+			IOWeight:           d.Limits.IOWeight,
+			OomKills:           oomKills(pid, oomKilled),
+		}
+		if d.Limits.MemoryMax > 0 {
+			stats.Limits.MemoryMax = data.MemoryStats.Limit
+		}
+	}
+
 	if !d.disableSpecialStats && environment.Server.Stats.Type == "jcmd" {
 		cmd, _ := environment.Server.Stats.Metadata["cmd"].(string)
 		if cmd == "" {
@@ -358,7 +395,7 @@ func (d *Docker) PullImage(environment *SkyPanel.Environment, ctx context.Contex
 		return err
 	}
 
-	w := &ImageWriter{Parent: environment.ConsoleTracker}
+	w := &ImageWriter{Parent: environment.ConsoleTracker, Environment: environment}
 	_, err = io.Copy(w, r)
 
 	if err != nil {
@@ -500,6 +537,9 @@ func (d *Docker) createContainer(environment *SkyPanel.Environment, data SkyPane
 	}
 
 	for k, v := range d.Binds {
+		if err = validateBind(k); err != nil {
+			return err
+		}
 		bindDirs = append(bindDirs, convertToBind(k)+":"+v)
 	}
 
@@ -507,13 +547,49 @@ func (d *Docker) createContainer(environment *SkyPanel.Environment, data SkyPane
 
 	hostConfig := &baseConfig
 	hostConfig.AutoRemove = true
+
+	for _, v := range hostConfig.Binds {
+		if err = validateBind(strings.SplitN(v, ":", 2)[0]); err != nil {
+			return err
+		}
+	}
+
+	networks := utils.ReplaceTokensInArr(d.Networks, data.Variables)
 	if hostConfig.NetworkMode == "" {
 		hostConfig.NetworkMode = container.NetworkMode(utils.ReplaceTokens(d.Network, data.Variables))
 	}
+	if hostConfig.NetworkMode == "" && len(networks) > 0 {
+		hostConfig.NetworkMode = container.NetworkMode(networks[0])
+	}
+	//nodes which disallow host access fall back to the docker default network instead
+	if hostConfig.NetworkMode == "" && !config.DockerDisallowHost.Value() {
+		hostConfig.NetworkMode = "host"
+	}
+	if hostConfig.NetworkMode.IsHost() && len(networks) > 0 {
+		return errors.New("networks cannot be joined while using the host network")
+	}
+
+	if err = validateHostAccess(hostConfig, networks); err != nil {
+		return err
+	}
+
+	if err = d.applyResources(hostConfig); err != nil {
+		return err
+	}
+
+	if err = d.applyStorage(hostConfig, data.Variables); err != nil {
+		return err
+	}
 
 	hostConfig.Binds = append(hostConfig.Binds, bindDirs...)
 
-	_, hostConfig.PortBindings, err = nat.ParsePortSpecs(utils.ReplaceTokensInArr(d.Ports, data.Variables))
+	portSpecs, err := d.portSpecs(data.Variables)
+	if err != nil {
+		return err
+	}
+	portSpecs = append(utils.ReplaceTokensInArr(d.Ports, data.Variables), portSpecs...)
+
+	_, hostConfig.PortBindings, err = nat.ParsePortSpecs(portSpecs)
 	if err != nil {
 		return err
 	}
@@ -543,7 +619,22 @@ func (d *Docker) createContainer(environment *SkyPanel.Environment, data SkyPane
 
 	//for now, default to linux across the board. This resolves problems that Windows has when you use it and docker
 	_, err = d.cli.ContainerCreate(ctx, containerConfig, hostConfig, networkConfig, &v1.Platform{OS: "linux"}, environment.ServerId)
-	return err
+	if err != nil {
+		return err
+	}
+
+	//the container is created on its main network, any other network is joined before it starts
+	for _, v := range networks {
+		if container.NetworkMode(v) == hostConfig.NetworkMode {
+			continue
+		}
+		environment.Log(logging.Debug, "Connecting container to network %s", v)
+		if err = d.cli.NetworkConnect(ctx, v, environment.ServerId, &network.EndpointSettings{}); err != nil {
+			_ = d.cli.ContainerRemove(ctx, environment.ServerId, container.RemoveOptions{Force: true})
+			return err
+		}
+	}
+	return nil
 }
 
 func (d *Docker) SendCodeImpl(environment *SkyPanel.Environment, code int) error {
@@ -612,12 +703,121 @@ func (d *Docker) handleClose(environment *SkyPanel.Environment, client *client.C
 
 	_ = environment.Console.Close()
 	d.disableSpecialStats = false
+	d.networkRates.Reset()
+	d.ioRates.Reset()
 
 	if callback != nil {
 		callback(exitCode)
 	}
 }
 
+// collectIOStats fills in the network and block IO counters of the container, along with their rates
+func (d *Docker) collectIOStats(v *container.StatsResponse, stats *SkyPanel.ServerStats) {
+	if len(v.Networks) > 0 {
+		network := &SkyPanel.NetworkStats{}
+		for _, n := range v.Networks {
+			network.RxBytes += n.RxBytes
+			network.TxBytes += n.TxBytes
+		}
+		rates := d.networkRates.Rates(network.RxBytes, network.TxBytes)
+		network.RxRate, network.TxRate = rates[0], rates[1]
+		stats.Network = network
+	}
+
+	diskIO := &SkyPanel.DiskIOStats{}
+	for _, entry := range v.BlkioStats.IoServiceBytesRecursive {
+		//cgroup v1 reports "Read", cgroup v2 reports "read"
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			diskIO.ReadBytes += entry.Value
+		case "write":
+			diskIO.WriteBytes += entry.Value
+		}
+	}
+	rates := d.ioRates.Rates(diskIO.ReadBytes, diskIO.WriteBytes)
+	diskIO.ReadRate, diskIO.WriteRate = rates[0], rates[1]
+	stats.DiskIO = diskIO
+
+	//the pids count includes every thread
+	stats.Threads = int32(v.PidsStats.Current)
+}
+
+// openFiles counts the file descriptors of the processes in the container, as docker does not report them
+// It gives 0 when the processes can not be seen, such as with a remote or rootless docker
+func openFiles(pid int) int32 {
+	if pid == 0 {
+		return 0
+	}
+	main, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return 0
+	}
+
+	var count int32
+	processes := []*process.Process{main}
+	for i := 0; i < len(processes); i++ {
+		if fds, err := processes[i].NumFDs(); err == nil {
+			count += fds
+		}
+		if children, err := processes[i].Children(); err == nil {
+			processes = append(processes, children...)
+		}
+	}
+	return count
+}
+
+// oomKills reads how many processes the kernel killed in the container for going over its memory limit
+// The memory failcnt docker reports only counts hitting the limit, and is always 0 on cgroup v2.
+// When the cgroup can not be read, the container at least tells if its main process was killed.
+func oomKills(pid int, oomKilled bool) uint64 {
+	if pid != 0 {
+		if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid)); err == nil {
+			if file := oomEventsFile(string(data)); file != "" {
+				if events, err := os.ReadFile(file); err == nil {
+					if kills, ok := parseOomKills(string(events)); ok {
+						return kills
+					}
+				}
+			}
+		}
+	}
+	if oomKilled {
+		return 1
+	}
+	return 0
+}
+
+// oomEventsFile finds the file with the oom kill count from the cgroups of a process,
+// memory.events on cgroup v2 and memory.oom_control of the memory controller on cgroup v1
+func oomEventsFile(cgroups string) string {
+	var unified string
+	for _, line := range strings.Split(strings.TrimSpace(cgroups), "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if slices.Contains(strings.Split(parts[1], ","), "memory") {
+			return filepath.Join("/sys/fs/cgroup/memory", parts[2], "memory.oom_control")
+		}
+		if parts[0] == "0" && parts[1] == "" {
+			unified = filepath.Join("/sys/fs/cgroup", parts[2], "memory.events")
+		}
+	}
+	return unified
+}
+
+// parseOomKills reads the oom_kill line of memory.events or memory.oom_control
+func parseOomKills(data string) (uint64, bool) {
+	for _, line := range strings.Split(data, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), " ")
+		if found && key == "oom_kill" {
+			kills, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+			return kills, err == nil
+		}
+	}
+	return 0, false
+}
+
 func calculateCPUPercent(v *container.StatsResponse) float64 {
 	//this math is from https://docs.docker.com/reference/api/engine/version/v1.45/#tag/Container/operation/ContainerStats
 	cpuDelta := v.CPUStats.CPUUsage.TotalUsage - v.PreCPUStats.CPUUsage.TotalUsage
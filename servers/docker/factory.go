@@ -11,7 +11,6 @@ type EnvironmentFactory struct {
 func (ef EnvironmentFactory) Create() SkyPanel.EnvironmentImpl {
 	return &Docker{
 		ImageName: "SkyPanel/generic",
-		Network:   "host",
 		Ports:     make([]string, 0),
 		Binds:     make(map[string]string),
 		Labels:    make(map[string]string),
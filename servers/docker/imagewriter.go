@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
 )
 
 type ImageDownload struct {
@@ -22,7 +24,9 @@ type ProgressDetail struct {
 
 type ImageWriter struct {
 	io.Writer
-	Parent io.Writer
+	Parent      io.Writer
+	Environment *SkyPanel.Environment //gets the bytes pulled as progress, if set
+	layers      map[string]ProgressDetail
 }
 
 func (w *ImageWriter) Write(data []byte) (n int, err error) {
@@ -53,6 +57,8 @@ func (w *ImageWriter) Write(data []byte) (n int, err error) {
 			return
 		}
 
+		w.trackProgress(imageDownload)
+
 		message := fmt.Sprintf("%s %s %s", imageDownload.Status, imageDownload.Id, strings.ReplaceAll(imageDownload.Progress, "\u003e", ""))
 		message = strings.TrimSpace(message)
 		_, err = w.Parent.Write([]byte(message + "\n"))
@@ -64,3 +70,32 @@ func (w *ImageWriter) Write(data []byte) (n int, err error) {
 
 	return
 }
+
+// trackProgress sums the download progress of every layer, as docker reports each one on its own
+func (w *ImageWriter) trackProgress(imageDownload ImageDownload) {
+	if w.Environment == nil || imageDownload.Id == "" {
+		return
+	}
+	if w.layers == nil {
+		w.layers = make(map[string]ProgressDetail)
+	}
+
+	switch {
+	case imageDownload.Status == "Downloading" && imageDownload.ProgressDetail.Total > 0:
+		w.layers[imageDownload.Id] = imageDownload.ProgressDetail
+	case imageDownload.Status == "Download complete":
+		if layer, ok := w.layers[imageDownload.Id]; ok {
+			layer.Current = layer.Total
+			w.layers[imageDownload.Id] = layer
+		}
+	default:
+		return
+	}
+
+	var current, total int64
+	for _, layer := range w.layers {
+		current += layer.Current
+		total += layer.Total
+	}
+	w.Environment.UpdateProgress(current, total)
+}
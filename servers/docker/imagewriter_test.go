@@ -0,0 +1,34 @@
+package docker
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+)
+
+func TestImageWriterProgress(t *testing.T) {
+	env := &SkyPanel.Environment{}
+	var console bytes.Buffer
+	w := &ImageWriter{Parent: &console, Environment: env}
+
+	_, err := w.Write([]byte(`{"status":"Pulling fs layer","id":"a"}
+{"status":"Downloading","progressDetail":{"current":10,"total":100},"id":"a"}
+{"status":"Downloading","progressDetail":{"current":5,"total":50},"id":"b"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress := env.GetProgress(); progress == nil || progress.Current != 15 || progress.Size != 150 {
+		t.Errorf("progress = %+v, want 15 of 150", progress)
+	}
+
+	_, _ = w.Write([]byte(`{"status":"Download complete","id":"a"}` + "\n"))
+	if progress := env.GetProgress(); progress.Current != 105 {
+		t.Errorf("progress after layer a completed = %d, want 105", progress.Current)
+	}
+
+	if !bytes.Contains(console.Bytes(), []byte("Downloading a")) {
+		t.Errorf("console output missing layer status: %q", console.String())
+	}
+}
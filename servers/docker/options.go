@@ -0,0 +1,221 @@
+package docker
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/spf13/cast"
+)
+
+// PortVariable binds the port held in a server variable, so templates do not need to build port specs by hand
+type PortVariable struct {
+	Variable string `json:"variable"`
+	//Protocol is tcp, udp or both, defaults to tcp
+	Protocol string `json:"protocol,omitempty"`
+	//ContainerPort defaults to the same port as the host
+	ContainerPort string `json:"containerPort,omitempty"`
+	HostIp        string `json:"hostIp,omitempty"`
+} //@name DockerPortVariable
+
+// Volume is an extra volume, either a named docker volume or a host path.
+// The source must be in docker.volumeAllowlist.
+type Volume struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"readOnly,omitempty"`
+} //@name DockerVolume
+
+// portSpecs converts the port variables into specs nat.ParsePortSpecs understands
+func (d *Docker) portSpecs(variables map[string]interface{}) ([]string, error) {
+	specs := make([]string, 0)
+	for _, v := range d.PortVariables {
+		value, exists := variables[v.Variable]
+		if !exists {
+			return nil, SkyPanel.ErrFieldRequired(v.Variable)
+		}
+		hostPort := cast.ToString(value)
+		if hostPort == "" {
+			return nil, SkyPanel.ErrFieldRequired(v.Variable)
+		}
+
+		containerPort := v.ContainerPort
+		if containerPort == "" {
+			containerPort = hostPort
+		}
+		containerPort = utils.ReplaceTokens(containerPort, variables)
+
+		var protocols []string
+		switch v.Protocol {
+		case "", "tcp":
+			protocols = []string{"tcp"}
+		case "udp":
+			protocols = []string{"udp"}
+		case "both":
+			protocols = []string{"tcp", "udp"}
+		default:
+			return nil, SkyPanel.ErrFieldNotValidOption("protocol", "tcp", "udp", "both")
+		}
+
+		for _, protocol := range protocols {
+			spec := hostPort + ":" + containerPort + "/" + protocol
+			if v.HostIp != "" {
+				spec = utils.ReplaceTokens(v.HostIp, variables) + ":" + spec
+			}
+			specs = append(specs, spec)
+		}
+	}
+	return specs, nil
+}
+
+// applyResources copies the limits from the definition into the host config
+func (d *Docker) applyResources(hostConfig *container.HostConfig) error {
+	if d.MemorySwap != 0 && d.Limits.MemoryMax <= 0 {
+		return errors.New("memorySwap requires limits.memoryMax to be set")
+	}
+
+	if d.Limits.MemoryMax > 0 {
+		hostConfig.Memory = d.Limits.MemoryBytes()
+		switch {
+		case d.MemorySwap < 0:
+			hostConfig.MemorySwap = -1
+		case d.MemorySwap > 0:
+			//docker counts the swap limit as memory and swap together
+			hostConfig.MemorySwap = hostConfig.Memory + d.MemorySwap*1024*1024
+		default:
+			hostConfig.MemorySwap = hostConfig.Memory
+		}
+	}
+	if d.Limits.CpuQuota > 0 {
+		hostConfig.NanoCPUs = int64(d.Limits.CpuQuota * 1e9)
+	}
+	if d.Limits.PidsMax > 0 {
+		pids := d.Limits.PidsMax
+		hostConfig.PidsLimit = &pids
+	}
+	if d.Limits.IOWeight > 0 {
+		//docker only accepts the older blkio range
+		weight := d.Limits.IOWeight
+		if weight < 10 {
+			weight = 10
+		} else if weight > 1000 {
+			weight = 1000
+		}
+		hostConfig.BlkioWeight = weight
+	}
+	return nil
+}
+
+// applyStorage sets the read-only root, tmpfs mounts and extra volumes
+func (d *Docker) applyStorage(hostConfig *container.HostConfig, variables map[string]interface{}) error {
+	if d.ReadOnlyRootFs {
+		hostConfig.ReadonlyRootfs = true
+	}
+
+	if len(d.Tmpfs) > 0 && hostConfig.Tmpfs == nil {
+		hostConfig.Tmpfs = make(map[string]string)
+	}
+	for k, v := range d.Tmpfs {
+		hostConfig.Tmpfs[utils.ReplaceTokens(k, variables)] = v
+	}
+
+	for _, v := range d.Volumes {
+		source := utils.ReplaceTokens(v.Source, variables)
+		target := utils.ReplaceTokens(v.Target, variables)
+		if !isVolumeAllowed(source) {
+			return SkyPanel.ErrVolumeNotAllowed(source)
+		}
+		if !strings.HasPrefix(target, "/") {
+			return SkyPanel.ErrPathNotAbs(target)
+		}
+
+		m := mount.Mount{Source: source, Target: target, ReadOnly: v.ReadOnly, Type: mount.TypeVolume}
+		if filepath.IsAbs(source) {
+			m.Type = mount.TypeBind
+			m.Source = convertToBind(source)
+		}
+		hostConfig.Mounts = append(hostConfig.Mounts, m)
+	}
+	return nil
+}
+
+// validateHostAccess refuses anything which reaches outside the container when docker.disallowHost is set,
+// as those nodes only allow docker so the host stays isolated from what servers run
+func validateHostAccess(hostConfig *container.HostConfig, networks []string) error {
+	if !config.DockerDisallowHost.Value() {
+		return nil
+	}
+
+	if hostConfig.NetworkMode.IsHost() {
+		return SkyPanel.ErrDockerHostAccess("networkName")
+	}
+	for _, v := range networks {
+		if container.NetworkMode(v).IsHost() {
+			return SkyPanel.ErrDockerHostAccess("networks")
+		}
+	}
+	if hostConfig.Privileged {
+		return SkyPanel.ErrDockerHostAccess("hostConfig.Privileged")
+	}
+	if hostConfig.PidMode.IsHost() {
+		return SkyPanel.ErrDockerHostAccess("hostConfig.PidMode")
+	}
+	if hostConfig.IpcMode.IsHost() {
+		return SkyPanel.ErrDockerHostAccess("hostConfig.IpcMode")
+	}
+	if hostConfig.UTSMode.IsHost() {
+		return SkyPanel.ErrDockerHostAccess("hostConfig.UTSMode")
+	}
+	if hostConfig.UsernsMode.IsHost() {
+		return SkyPanel.ErrDockerHostAccess("hostConfig.UsernsMode")
+	}
+	if len(hostConfig.CapAdd) > 0 {
+		return SkyPanel.ErrDockerHostAccess("hostConfig.CapAdd")
+	}
+	if len(hostConfig.Devices) > 0 {
+		return SkyPanel.ErrDockerHostAccess("hostConfig.Devices")
+	}
+	for _, v := range hostConfig.Mounts {
+		if v.Type == mount.TypeBind && !isVolumeAllowed(v.Source) {
+			return SkyPanel.ErrVolumeNotAllowed(v.Source)
+		}
+	}
+	return nil
+}
+
+// validateBind checks a host path bind from the definition, these are only limited on nodes which disallow host access
+func validateBind(source string) error {
+	if config.DockerDisallowHost.Value() && !isVolumeAllowed(source) {
+		return SkyPanel.ErrVolumeNotAllowed(source)
+	}
+	return nil
+}
+
+// isVolumeAllowed checks a volume source against the allowlist.
+// Host paths must be inside an allowed path, named volumes must be listed by name.
+func isVolumeAllowed(source string) bool {
+	if source == "" {
+		return false
+	}
+	isPath := filepath.IsAbs(source) || strings.HasPrefix(source, "/")
+
+	for _, allowed := range config.DockerVolumeAllowlist.Value() {
+		if isPath {
+			if !filepath.IsAbs(allowed) && !strings.HasPrefix(allowed, "/") {
+				continue
+			}
+			rel, err := filepath.Rel(filepath.Clean(allowed), filepath.Clean(source))
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return true
+			}
+		} else if allowed == source {
+			return true
+		}
+	}
+	return false
+}
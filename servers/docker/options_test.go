@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SkyPanel/SkyPanel/v3/config"
+)
+
+func TestIsVolumeAllowed(t *testing.T) {
+	_ = config.DockerVolumeAllowlist.Set([]string{"/srv/shared", "maps"}, false)
+	defer config.DockerVolumeAllowlist.Set([]string{}, false)
+
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{source: "/srv/shared", want: true},
+		{source: "/srv/shared/world", want: true},
+		{source: "/srv/shared/../secret", want: false},
+		{source: "/srv/sharedother", want: false},
+		{source: "maps", want: true},
+		{source: "other", want: false},
+		{source: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			if got := isVolumeAllowed(tt.source); got != tt.want {
+				t.Errorf("isVolumeAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPortSpecs(t *testing.T) {
+	variables := map[string]interface{}{"port": 25565, "query": "27015"}
+
+	tests := []struct {
+		name    string
+		ports   []PortVariable
+		want    []string
+		wantErr bool
+	}{
+		{name: "default tcp", ports: []PortVariable{{Variable: "port"}}, want: []string{"25565:25565/tcp"}},
+		{name: "both protocols", ports: []PortVariable{{Variable: "query", Protocol: "both"}}, want: []string{"27015:27015/tcp", "27015:27015/udp"}},
+		{name: "fixed container port", ports: []PortVariable{{Variable: "port", ContainerPort: "25565", HostIp: "127.0.0.1"}}, want: []string{"127.0.0.1:25565:25565/tcp"}},
+		{name: "missing variable", ports: []PortVariable{{Variable: "rcon"}}, wantErr: true},
+		{name: "invalid protocol", ports: []PortVariable{{Variable: "port", Protocol: "sctp"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Docker{PortVariables: tt.ports}
+			got, err := d.portSpecs(variables)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("portSpecs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("portSpecs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
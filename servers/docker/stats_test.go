@@ -0,0 +1,79 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestCollectIOStats(t *testing.T) {
+	d := &Docker{}
+	v := &container.StatsResponse{
+		Networks: map[string]container.NetworkStats{
+			"eth0": {RxBytes: 1000, TxBytes: 500},
+			"eth1": {RxBytes: 24, TxBytes: 12},
+		},
+		BlkioStats: container.BlkioStats{
+			IoServiceBytesRecursive: []container.BlkioStatEntry{
+				{Op: "Read", Value: 4096},
+				{Op: "write", Value: 8192},
+				{Op: "Total", Value: 12288},
+			},
+		},
+		PidsStats: container.PidsStats{Current: 42},
+	}
+
+	stats := &SkyPanel.ServerStats{}
+	d.collectIOStats(v, stats)
+
+	if stats.Network == nil || stats.Network.RxBytes != 1024 || stats.Network.TxBytes != 512 {
+		t.Errorf("network = %+v, want 1024 received and 512 sent", stats.Network)
+	}
+	if stats.DiskIO == nil || stats.DiskIO.ReadBytes != 4096 || stats.DiskIO.WriteBytes != 8192 {
+		t.Errorf("disk io = %+v, want 4096 read and 8192 written", stats.DiskIO)
+	}
+	if stats.Threads != 42 {
+		t.Errorf("threads = %d, want 42", stats.Threads)
+	}
+
+	//without networks, such as with host networking, there is nothing to report
+	stats = &SkyPanel.ServerStats{}
+	d.collectIOStats(&container.StatsResponse{}, stats)
+	if stats.Network != nil {
+		t.Errorf("network = %+v, want nil", stats.Network)
+	}
+}
+
+func TestOomEventsFile(t *testing.T) {
+	tests := []struct {
+		cgroups string
+		want    string
+	}{
+		{cgroups: "0::/system.slice/docker-abc.scope\n", want: "/sys/fs/cgroup/system.slice/docker-abc.scope/memory.events"},
+		{cgroups: "12:pids:/docker/abc\n4:memory:/docker/abc\n1:name=systemd:/docker/abc\n0::/docker/abc\n", want: "/sys/fs/cgroup/memory/docker/abc/memory.oom_control"},
+		{cgroups: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := oomEventsFile(tt.cgroups); got != tt.want {
+			t.Errorf("oomEventsFile(%q) = %q, want %q", tt.cgroups, got, tt.want)
+		}
+	}
+}
+
+func TestParseOomKills(t *testing.T) {
+	kills, ok := parseOomKills("low 0\nhigh 0\nmax 12\noom 3\noom_kill 2\noom_group_kill 0\n")
+	if !ok || kills != 2 {
+		t.Errorf("parseOomKills(memory.events) = %d, %v, want 2", kills, ok)
+	}
+	kills, ok = parseOomKills("oom_kill_disable 0\nunder_oom 0\noom_kill 5\n")
+	if !ok || kills != 5 {
+		t.Errorf("parseOomKills(memory.oom_control) = %d, %v, want 5", kills, ok)
+	}
+	if _, ok = parseOomKills("oom_kill_disable 0\nunder_oom 0\n"); ok {
+		t.Error("parseOomKills without oom_kill should not be ok")
+	}
+	if oomKills(0, true) != 1 {
+		t.Error("oomKills should fall back to the container state")
+	}
+}
@@ -0,0 +1,46 @@
+package servers
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+)
+
+// createSnapshot archives the server folder so a failed install can be undone
+// An archive is used instead of hardlinks, as operations which rewrite a file in place would change the snapshot too
+func (p *Server) createSnapshot() (string, error) {
+	folder := filepath.Join(config.CacheFolder.Value(), "snapshots")
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return "", err
+	}
+
+	snapshot := filepath.Join(folder, p.Id()+".tar.gz")
+	_ = os.Remove(snapshot)
+
+	p.RunningEnvironment.DisplayToConsole(true, "Creating snapshot of server before installing\n")
+	if err := files.Compress(nil, snapshot, []string{p.GetFileServer().Prefix()}); err != nil {
+		_ = os.Remove(snapshot)
+		return "", err
+	}
+	return snapshot, nil
+}
+
+// restoreSnapshot puts the server folder back as it was before the install
+func (p *Server) restoreSnapshot(snapshot string) {
+	p.RunningEnvironment.DisplayToConsole(true, "Restoring server to how it was before installing\n")
+	if err := p.replaceFiles(snapshot); err != nil {
+		p.Log(logging.Error, "Error restoring install snapshot: %s", err)
+		p.RunningEnvironment.DisplayToConsole(true, "Failed to restore snapshot: %s\n", err)
+		return
+	}
+	p.RunningEnvironment.DisplayToConsole(true, "Server restored\n")
+}
+
+func (p *Server) deleteSnapshot(snapshot string) {
+	if err := os.Remove(snapshot); err != nil && !os.IsNotExist(err) {
+		p.Log(logging.Error, "Error deleting install snapshot: %s", err)
+	}
+}
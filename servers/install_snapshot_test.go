@@ -0,0 +1,153 @@
+package servers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallSnapshot(t *testing.T) {
+	_ = config.CacheFolder.Set(t.TempDir(), false)
+
+	root := t.TempDir()
+	fileServer, err := files.NewFileServer(root, os.Getuid(), os.Getgid())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer fileServer.Close()
+
+	_ = os.MkdirAll(filepath.Join(root, "world"), 0755)
+	_ = os.WriteFile(filepath.Join(root, "world", "level.dat"), []byte("level"), 0644)
+	_ = os.WriteFile(filepath.Join(root, "server.properties"), []byte("motd=before"), 0644)
+
+	writeFile := func(target, text string) SkyPanel.ConditionalMetadataType {
+		return SkyPanel.ConditionalMetadataType{MetadataType: SkyPanel.MetadataType{
+			Type:     "writefile",
+			Metadata: map[string]interface{}{"target": target, "text": text},
+		}}
+	}
+
+	p := &Server{
+		Server: SkyPanel.Server{
+			Identifier:      "snapshottest",
+			InstallSnapshot: true,
+			Installation: []SkyPanel.ConditionalMetadataType{
+				writeFile("server.properties", "motd=after"),
+				writeFile("new.txt", "new"),
+				{MetadataType: SkyPanel.MetadataType{Type: "missing"}},
+			},
+		},
+		RunningEnvironment: &SkyPanel.Environment{
+			RootDirectory:  root,
+			Implementation: &fakeProcess{},
+			ConsoleBuffer:  SkyPanel.CreateCache(),
+			ConsoleTracker: SkyPanel.CreateTracker(),
+			StatusTracker:  SkyPanel.CreateTracker(),
+		},
+		fileServer: fileServer,
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		p.Installation[1].If = "false"
+		defer func() { p.Installation[1].If = "" }()
+
+		_, err := p.PlanInstall()
+		assert.Equal(t, SkyPanel.ErrMissingFactory, err)
+
+		installation := p.Installation
+		p.Installation = installation[:2]
+		defer func() { p.Installation = installation }()
+
+		plan, err := p.PlanInstall()
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, plan, 2)
+		assert.True(t, plan[0].Run)
+		assert.Equal(t, "motd=after", plan[0].Arguments["text"])
+		assert.False(t, plan[1].Run)
+
+		content, _ := os.ReadFile(filepath.Join(root, "server.properties"))
+		assert.Equal(t, "motd=before", string(content))
+	})
+
+	t.Run("RestoredOnFailure", func(t *testing.T) {
+		assert.Error(t, p.Install())
+
+		content, _ := os.ReadFile(filepath.Join(root, "server.properties"))
+		assert.Equal(t, "motd=before", string(content))
+		content, _ = os.ReadFile(filepath.Join(root, "world", "level.dat"))
+		assert.Equal(t, "level", string(content))
+		assert.NoFileExists(t, filepath.Join(root, "new.txt"))
+		assert.NoFileExists(t, filepath.Join(config.CacheFolder.Value(), "snapshots", "snapshottest.tar.gz"))
+		assert.False(t, p.RunningEnvironment.IsInstalling())
+	})
+
+	t.Run("NotRestoredWhileStepRuns", func(t *testing.T) {
+		release := make(chan struct{})
+		commandMapping["stuck"] = stuckFactory{release: release, target: filepath.Join(root, "late.txt")}
+		defer delete(commandMapping, "stuck")
+
+		oldGrace := cancelGrace
+		cancelGrace = 10 * time.Millisecond
+		defer func() { cancelGrace = oldGrace }()
+
+		installation := p.Installation
+		p.Installation = []SkyPanel.ConditionalMetadataType{
+			writeFile("server.properties", "motd=after"),
+			{MetadataType: SkyPanel.MetadataType{Type: "stuck"}},
+		}
+		p.InstallTimeout = "50ms"
+		defer func() {
+			p.Installation = installation
+			p.InstallTimeout = ""
+		}()
+
+		err := p.Install()
+		assert.Equal(t, "ErrOperationTimeout", SkyPanel.FromError(err).GetCode())
+		assert.False(t, p.RunningEnvironment.IsInstalling())
+
+		//the folder is left as the steps made it, and the snapshot is kept
+		content, _ := os.ReadFile(filepath.Join(root, "server.properties"))
+		assert.Equal(t, "motd=after", string(content))
+		assert.FileExists(t, filepath.Join(config.CacheFolder.Value(), "snapshots", "snapshottest.tar.gz"))
+		console, _ := p.RunningEnvironment.GetConsole()
+		assert.Contains(t, string(console), "not restored")
+
+		close(release)
+		assert.Eventually(t, func() bool {
+			_, err := os.Stat(filepath.Join(root, "late.txt"))
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+// stuckOperation ignores its context and writes a file once released, like a step which cannot be stopped
+type stuckOperation struct {
+	release chan struct{}
+	target  string
+}
+
+func (s stuckOperation) Run(SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
+	<-s.release
+	return SkyPanel.OperationResult{Error: os.WriteFile(s.target, []byte("late"), 0644)}
+}
+
+type stuckFactory struct {
+	release chan struct{}
+	target  string
+}
+
+func (f stuckFactory) Create(SkyPanel.CreateOperation) (SkyPanel.Operation, error) {
+	return stuckOperation{release: f.release, target: f.target}, nil
+}
+
+func (f stuckFactory) Key() string {
+	return "stuck"
+}
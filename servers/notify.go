@@ -0,0 +1,107 @@
+package servers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/notifications"
+	"github.com/SkyPanel/SkyPanel/v3/services"
+)
+
+// displayName devuelve el nombre visible del servidor, o su ID si no tiene
+func (p *Server) displayName() string {
+	if p.Server.Display != "" {
+		return p.Server.Display
+	}
+	return p.Id()
+}
+
+// notify envía un evento del servidor a todos los canales suscritos
+func (p *Server) notify(event, severity, title, message string, fields ...notifications.Field) {
+	services.Notify(&notifications.Notification{
+		Event:      event,
+		Severity:   severity,
+		Title:      title,
+		Message:    message,
+		ServerId:   p.Id(),
+		ServerName: p.displayName(),
+		Fields:     fields,
+	})
+}
+
+func (p *Server) notifyOnline() {
+	p.notify(notifications.EventServerOnline, notifications.SeveritySuccess,
+		"✅ Servidor Conectado", fmt.Sprintf("El servidor %s está ahora online.", p.displayName()))
+}
+
+func (p *Server) notifyOffline() {
+	p.notify(notifications.EventServerOffline, notifications.SeverityWarning,
+		"⚠️ Servidor Desconectado", fmt.Sprintf("El servidor %s se ha desconectado o está offline.", p.displayName()))
+}
+
+// crashConsoleFieldLines y crashConsoleFieldSize limitan la consola que se adjunta al aviso de caída,
+// el informe completo queda en la API
+const crashConsoleFieldLines = 10
+const crashConsoleFieldSize = 900
+
+func (p *Server) notifyCrash(report *CrashReport) {
+	fields := []notifications.Field{
+		{Name: "Código de salida", Value: fmt.Sprint(report.ExitCode)},
+		{Name: "Caídas", Value: fmt.Sprint(p.CrashCount())},
+		{Name: "Tiempo activo", Value: (time.Duration(report.Uptime) * time.Second).String()},
+		{Name: "Informe", Value: report.Id},
+	}
+
+	console := report.Console
+	if len(console) > crashConsoleFieldLines {
+		console = console[len(console)-crashConsoleFieldLines:]
+	}
+	if tail := strings.Join(console, "\n"); tail != "" {
+		if len(tail) > crashConsoleFieldSize {
+			tail = tail[len(tail)-crashConsoleFieldSize:]
+		}
+		fields = append(fields, notifications.Field{Name: "Consola", Value: tail})
+	}
+
+	p.notify(notifications.EventServerCrash, notifications.SeverityCritical,
+		"💥 Servidor Caído", fmt.Sprintf("El servidor %s se cerró inesperadamente.", p.displayName()),
+		fields...)
+}
+
+func (p *Server) notifyUnhealthy(report *CrashReport) {
+	p.notify(notifications.EventServerHung, notifications.SeverityCritical,
+		"🧊 Servidor Colgado", fmt.Sprintf("El servidor %s dejó de responder y se va a reiniciar.", p.displayName()),
+		notifications.Field{Name: "Comprobación", Value: report.Reason},
+		notifications.Field{Name: "Tiempo activo", Value: (time.Duration(report.Uptime) * time.Second).String()},
+		notifications.Field{Name: "Informe", Value: report.Id})
+}
+
+func (p *Server) notifyBackup(success bool) {
+	if success {
+		p.notify(notifications.EventBackupSuccess, notifications.SeveritySuccess,
+			"✅ Backup Completado", fmt.Sprintf("El backup del servidor %s se completó exitosamente.", p.displayName()))
+	} else {
+		p.notify(notifications.EventBackupFailed, notifications.SeverityCritical,
+			"❌ Backup Fallido", fmt.Sprintf("El backup del servidor %s falló durante la creación.", p.displayName()))
+	}
+}
+
+func (p *Server) notifyDiskWarning(used, limit int64) {
+	p.notify(notifications.EventDiskWarning, notifications.SeverityWarning,
+		"💾 Disco casi lleno", fmt.Sprintf("El servidor %s está usando %.1f%% de su cuota de disco.", p.displayName(), float64(used)/float64(limit)*100),
+		notifications.Field{Name: "Usado", Value: fmt.Sprintf("%d MiB", used/1024/1024)},
+		notifications.Field{Name: "Límite", Value: fmt.Sprintf("%d MiB", limit/1024/1024)})
+}
+
+func (p *Server) notifyRule(rule *models.AlertRule) {
+	p.notify(notifications.EventAlertFiring, rule.Severity,
+		"⚠️ "+rule.Name, fmt.Sprintf("La regla %s se activó en el servidor %s.", rule.Name, p.displayName()),
+		notifications.Field{Name: "Condición", Value: rule.Expression})
+}
+
+func (p *Server) notifyRuleRecovery(rule *models.AlertRule) {
+	p.notify(notifications.EventAlertResolved, notifications.SeveritySuccess,
+		"✅ "+rule.Name+" resuelta", fmt.Sprintf("La regla %s ya no se cumple en el servidor %s.", rule.Name, p.displayName()))
+}
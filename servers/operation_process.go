@@ -1,6 +1,10 @@
 package servers
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/SkyPanel/SkyPanel/v3"
 	"github.com/SkyPanel/SkyPanel/v3/conditions"
 	"github.com/SkyPanel/SkyPanel/v3/logging"
@@ -11,11 +15,14 @@ import (
 	"github.com/SkyPanel/SkyPanel/v3/operations/curseforge"
 	"github.com/SkyPanel/SkyPanel/v3/operations/dockerpull"
 	"github.com/SkyPanel/SkyPanel/v3/operations/download"
+	"github.com/SkyPanel/SkyPanel/v3/operations/editconfig"
 	"github.com/SkyPanel/SkyPanel/v3/operations/extract"
 	"github.com/SkyPanel/SkyPanel/v3/operations/fabricdl"
 	"github.com/SkyPanel/SkyPanel/v3/operations/forgedl"
+	"github.com/SkyPanel/SkyPanel/v3/operations/gitdeploy"
 	"github.com/SkyPanel/SkyPanel/v3/operations/javadl"
 	"github.com/SkyPanel/SkyPanel/v3/operations/mkdir"
+	"github.com/SkyPanel/SkyPanel/v3/operations/modrinth"
 	"github.com/SkyPanel/SkyPanel/v3/operations/mojangdl"
 	"github.com/SkyPanel/SkyPanel/v3/operations/move"
 	"github.com/SkyPanel/SkyPanel/v3/operations/neoforgedl"
@@ -23,6 +30,7 @@ import (
 	"github.com/SkyPanel/SkyPanel/v3/operations/paperdl"
 	"github.com/SkyPanel/SkyPanel/v3/operations/resolveforgeversion"
 	"github.com/SkyPanel/SkyPanel/v3/operations/resolveneoforgeversion"
+	"github.com/SkyPanel/SkyPanel/v3/operations/resolveversion"
 	"github.com/SkyPanel/SkyPanel/v3/operations/sleep"
 	"github.com/SkyPanel/SkyPanel/v3/operations/spongedl"
 	"github.com/SkyPanel/SkyPanel/v3/operations/stdin"
@@ -32,6 +40,9 @@ import (
 	"github.com/spf13/cast"
 )
 
+// how long an operation gets to stop once it is cancelled, before the process moves on without it
+var cancelGrace = 10 * time.Second
+
 var commandMapping = make(map[string]SkyPanel.OperationFactory)
 var factories = []SkyPanel.OperationFactory{
 	alterfile.Factory,
@@ -41,11 +52,14 @@ var factories = []SkyPanel.OperationFactory{
 	curseforge.Factory,
 	dockerpull.Factory,
 	download.Factory,
+	editconfig.Factory,
 	extract.Factory,
 	fabricdl.Factory,
 	forgedl.Factory,
+	gitdeploy.Factory,
 	javadl.Factory,
 	mkdir.Factory,
+	modrinth.Factory,
 	mojangdl.Factory,
 	move.Factory,
 	neoforgedl.Factory,
@@ -53,6 +67,7 @@ var factories = []SkyPanel.OperationFactory{
 	paperdl.Factory,
 	resolveforgeversion.Factory,
 	resolveneoforgeversion.Factory,
+	resolveversion.Factory,
 	sleep.Factory,
 	spongedl.Factory,
 	stdin.Factory,
@@ -75,38 +90,12 @@ func GenerateProcess(directions []SkyPanel.ConditionalMetadataType, environment
 	dataMap["rootDir"] = environment.GetRootDirectory()
 	operationList := make(OperationProcess, 0)
 	for _, mapping := range directions {
-		mapCopy := make(map[string]interface{})
-
-		//replace tokens
-		for k, v := range mapping.Metadata {
-			switch r := v.(type) {
-			case string:
-				{
-					mapCopy[k] = utils.ReplaceTokens(r, dataMap)
-				}
-			case []string:
-				{
-					mapCopy[k] = utils.ReplaceTokensInArr(r, dataMap)
-				}
-			case map[string]string:
-				{
-					mapCopy[k] = utils.ReplaceTokensInMap(r, dataMap)
-				}
-			case []interface{}:
-				{
-					//if we can convert this to a string list, we can work with it
-					temp := cast.ToStringSlice(r)
-					if len(temp) == len(r) {
-						mapCopy[k] = utils.ReplaceTokensInArr(temp, dataMap)
-					} else {
-						mapCopy[k] = v
-					}
-				}
-			default:
-				mapCopy[k] = v
-			}
+		timeout, err := parseTimeout(mapping.Metadata["timeout"])
+		if err != nil {
+			return nil, SkyPanel.ErrFactoryError(mapping.Type, err)
 		}
 
+		mapCopy := replaceArgs(mapping.Metadata, dataMap)
 		envMap := utils.ReplaceTokensInMap(env, dataMap)
 
 		opCreate := SkyPanel.CreateOperation{
@@ -115,21 +104,79 @@ func GenerateProcess(directions []SkyPanel.ConditionalMetadataType, environment
 			DataMap:              dataMap,
 		}
 
-		task := &OperationTask{Type: mapping.Type, Operation: opCreate, Condition: mapping.If}
+		task := &OperationTask{Type: mapping.Type, Operation: opCreate, Condition: mapping.If, Timeout: timeout, metadata: mapping.Metadata, env: env}
 		operationList = append(operationList, task)
 	}
 	return operationList, nil
 }
 
+// replaceArgs fills in the variables used in the arguments of an operation
+func replaceArgs(metadata map[string]interface{}, dataMap map[string]interface{}) map[string]interface{} {
+	mapCopy := make(map[string]interface{})
+	for k, v := range metadata {
+		if k == "timeout" {
+			continue
+		}
+		switch r := v.(type) {
+		case string:
+			{
+				mapCopy[k] = utils.ReplaceTokens(r, dataMap)
+			}
+		case []string:
+			{
+				mapCopy[k] = utils.ReplaceTokensInArr(r, dataMap)
+			}
+		case map[string]string:
+			{
+				mapCopy[k] = utils.ReplaceTokensInMap(r, dataMap)
+			}
+		case []interface{}:
+			{
+				//if we can convert this to a string list, we can work with it
+				temp := cast.ToStringSlice(r)
+				if len(temp) == len(r) {
+					mapCopy[k] = utils.ReplaceTokensInArr(temp, dataMap)
+				} else {
+					mapCopy[k] = v
+				}
+			}
+		default:
+			mapCopy[k] = v
+		}
+	}
+	return mapCopy
+}
+
 type OperationProcess []*OperationTask
 
 type OperationTask struct {
 	Operation SkyPanel.CreateOperation
 	Condition string
 	Type      string
+	Timeout   time.Duration
+
+	//arguments before variables were filled in, kept so variables set by earlier steps can be used
+	metadata map[string]interface{}
+	env      map[string]string
+
+	//set when the operation did not stop after being cancelled, it may still be changing files
+	leftRunning bool
 }
 
-func (p *OperationProcess) Run(server *Server) error {
+// setVariables updates the variables the task uses and fills them in again
+func (t *OperationTask) setVariables(values map[string]interface{}) {
+	for k, v := range values {
+		t.Operation.DataMap[k] = v
+	}
+	if t.metadata == nil {
+		return
+	}
+	t.Operation.OperationArgs = replaceArgs(t.metadata, t.Operation.DataMap)
+	t.Operation.EnvironmentVariables = utils.ReplaceTokensInMap(t.env, t.Operation.DataMap)
+}
+
+// Run runs each operation in order, stopping at the first error or once ctx is done
+func (p *OperationProcess) Run(ctx context.Context, server *Server) error {
 	if len(*p) == 0 {
 		return nil
 	}
@@ -138,50 +185,208 @@ func (p *OperationProcess) Run(server *Server) error {
 		conditions.VariableSuccess: true,
 	}
 
+	env := server.RunningEnvironment
 	var firstError error
-	for _, v := range *p {
+	for i, v := range *p {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		progress := SkyPanel.OperationProgress{Step: i + 1, Total: len(*p), Operation: v.Type}
+
 		shouldRun, err := server.RunCondition(v.Condition, extraData)
 		if err != nil {
 			return err
 		}
 
-		if shouldRun {
-			factory := commandMapping[v.Type]
-			if factory == nil {
-				return SkyPanel.ErrMissingFactory
-			}
-			op, err := factory.Create(v.Operation)
-			if err != nil {
-				return SkyPanel.ErrFactoryError(v.Type, err)
-			}
+		if !shouldRun {
+			progress.State = SkyPanel.ProgressSkipped
+			env.SetProgress(&progress)
+			continue
+		}
 
-			result := op.Run(SkyPanel.RunOperatorArgs{
-				Environment: server.RunningEnvironment,
-				Server:      server,
-			})
-
-			if result.Error != nil {
-				logging.Error.Printf("Error running command: %s", result.Error.Error())
-				//TODO: Implement success checking more accurately here
-				/*if firstError == nil {
-					firstError = result.Error
-					return result.Error
-				}
-				//extraData[conditions.VariableSuccess] = false
-				*/
+		factory := commandMapping[v.Type]
+		if factory == nil {
+			return SkyPanel.ErrMissingFactory
+		}
+		op, err := factory.Create(v.Operation)
+		if err != nil {
+			return SkyPanel.ErrFactoryError(v.Type, err)
+		}
+
+		progress.State = SkyPanel.ProgressRunning
+		env.SetProgress(&progress)
+
+		result := v.run(ctx, op, server)
+
+		//keep the bytes the operation reported, so the last event still shows the size
+		if last := env.GetProgress(); last != nil {
+			progress.Current, progress.Size = last.Current, last.Size
+		}
+
+		if result.Error != nil {
+			progress.State = SkyPanel.ProgressFailed
+			progress.Error = result.Error.Error()
+			env.SetProgress(&progress)
+
+			logging.Error.Printf("Error running command: %s", result.Error.Error())
+			//TODO: Implement success checking more accurately here
+			/*if firstError == nil {
+				firstError = result.Error
 				return result.Error
-			} else {
-				extraData[conditions.VariableSuccess] = true
 			}
+			//extraData[conditions.VariableSuccess] = false
+			*/
+			return result.Error
+		} else {
+			extraData[conditions.VariableSuccess] = true
+		}
 
-			if result.VariableOverrides != nil {
-				for k, val := range result.VariableOverrides {
-					variable := server.Variables[k]
-					variable.Value = val
-					server.Variables[k] = variable
-				}
+		progress.State = SkyPanel.ProgressDone
+		env.SetProgress(&progress)
+
+		if result.VariableOverrides != nil {
+			for k, val := range result.VariableOverrides {
+				variable := server.Variables[k]
+				variable.Value = val
+				server.Variables[k] = variable
+			}
+			for _, next := range (*p)[i+1:] {
+				next.setVariables(result.VariableOverrides)
 			}
 		}
 	}
 	return firstError
 }
+
+// leftRunning tells if a step did not stop after being cancelled
+func (p *OperationProcess) leftRunning() bool {
+	for _, v := range *p {
+		if v.leftRunning {
+			return true
+		}
+	}
+	return false
+}
+
+type PlannedOperation struct {
+	Step      int                    `json:"step"`
+	Type      string                 `json:"type"`
+	If        string                 `json:"if,omitempty"`
+	Run       bool                   `json:"run"` //false when its condition is not met
+	Timeout   string                 `json:"timeout,omitempty"`
+	Arguments map[string]interface{} `json:"arguments"` //after token replacement
+} //@name PlannedOperation
+
+// Plan resolves what Run would do, without running anything
+// Conditions are checked as if every step before them succeeded
+func (p *OperationProcess) Plan(server *Server) ([]PlannedOperation, error) {
+	extraData := map[string]interface{}{
+		conditions.VariableSuccess: true,
+	}
+
+	plan := make([]PlannedOperation, 0, len(*p))
+	for i, v := range *p {
+		if commandMapping[v.Type] == nil {
+			return nil, SkyPanel.ErrMissingFactory
+		}
+
+		shouldRun, err := server.RunCondition(v.Condition, extraData)
+		if err != nil {
+			return nil, err
+		}
+
+		planned := PlannedOperation{
+			Step:      i + 1,
+			Type:      v.Type,
+			If:        v.Condition,
+			Run:       shouldRun,
+			Arguments: v.Operation.OperationArgs,
+		}
+		if v.Timeout > 0 {
+			planned.Timeout = v.Timeout.String()
+		}
+		plan = append(plan, planned)
+	}
+	return plan, nil
+}
+
+// run runs the operation until it finishes or its context is done
+// Operations which do not watch the context, such as a command, are stopped by killing what they started
+func (t *OperationTask) run(ctx context.Context, op SkyPanel.Operation, server *Server) SkyPanel.OperationResult {
+	if t.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, t.Timeout, SkyPanel.ErrOperationTimeout(t.Type, t.Timeout))
+		defer cancel()
+	}
+
+	env := server.RunningEnvironment
+	wasRunning, _ := env.IsRunning()
+
+	done := make(chan SkyPanel.OperationResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- SkyPanel.OperationResult{Error: fmt.Errorf("%s panicked: %v", t.Type, r)}
+			}
+		}()
+		done <- op.Run(SkyPanel.RunOperatorArgs{
+			Environment: env,
+			Server:      server,
+			Context:     ctx,
+		})
+	}()
+
+	finished := false
+	select {
+	case result := <-done:
+		//an operation giving up on a cancelled context may still have left its process behind
+		if ctx.Err() == nil {
+			return result
+		}
+		finished = true
+	case <-ctx.Done():
+	}
+
+	err := context.Cause(ctx)
+	logging.Info.Printf("[%s] Stopping %s: %s", server.Id(), t.Type, err.Error())
+	env.DisplayToConsole(true, "%s\n", err.Error())
+
+	//only kill a process this operation started, tasks run while the server itself is up
+	if running, _ := env.IsRunning(); running && !wasRunning {
+		if killErr := env.Kill(); killErr != nil {
+			logging.Error.Printf("[%s] Error killing %s: %s", server.Id(), t.Type, killErr.Error())
+		}
+	}
+
+	if finished {
+		return SkyPanel.OperationResult{Error: err}
+	}
+	select {
+	case <-done:
+	case <-time.After(cancelGrace):
+		logging.Error.Printf("[%s] %s did not stop within %s, leaving it behind", server.Id(), t.Type, cancelGrace)
+		t.leftRunning = true
+	}
+	return SkyPanel.OperationResult{Error: err}
+}
+
+// parseTimeout reads a timeout given as a duration such as 10m, or as a number of seconds
+func parseTimeout(value interface{}) (time.Duration, error) {
+	if value == nil {
+		return 0, nil
+	}
+	if str, ok := value.(string); ok {
+		if str == "" {
+			return 0, nil
+		}
+		if d, err := time.ParseDuration(str); err == nil {
+			return d, nil
+		}
+	}
+	seconds, err := cast.ToFloat64E(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %v", value)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
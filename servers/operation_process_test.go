@@ -0,0 +1,210 @@
+package servers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProcess stands in for an environment running a process which only stops when killed
+type fakeProcess struct {
+	SkyPanel.EnvironmentImpl
+	lock    sync.Mutex
+	running bool
+	stopped chan struct{}
+}
+
+func (f *fakeProcess) IsRunningImpl(*SkyPanel.Environment) (bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.running, nil
+}
+
+func (f *fakeProcess) KillImpl(*SkyPanel.Environment) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.running {
+		f.running = false
+		close(f.stopped)
+	}
+	return nil
+}
+
+func (f *fakeProcess) start() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.running = true
+	f.stopped = make(chan struct{})
+}
+
+// hangOperation starts a process and waits for it, ignoring its context like the command operation would
+type hangOperation struct {
+	process *fakeProcess
+}
+
+func (h hangOperation) Run(SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
+	h.process.start()
+	<-h.process.stopped
+	return SkyPanel.OperationResult{}
+}
+
+type hangFactory struct {
+	process *fakeProcess
+}
+
+func (f hangFactory) Create(SkyPanel.CreateOperation) (SkyPanel.Operation, error) {
+	return hangOperation{process: f.process}, nil
+}
+
+func (f hangFactory) Key() string {
+	return "hang"
+}
+
+// setOperation sets variables like the resolve operations do, and records the arguments it was created with
+type setOperation struct {
+	values map[string]interface{}
+}
+
+func (s setOperation) Run(SkyPanel.RunOperatorArgs) SkyPanel.OperationResult {
+	return SkyPanel.OperationResult{VariableOverrides: s.values}
+}
+
+type setFactory struct {
+	created *[]map[string]interface{}
+}
+
+func (f setFactory) Create(op SkyPanel.CreateOperation) (SkyPanel.Operation, error) {
+	*f.created = append(*f.created, op.OperationArgs)
+	return setOperation{values: map[string]interface{}{"resolved": op.OperationArgs["value"]}}, nil
+}
+
+func (f setFactory) Key() string {
+	return "set"
+}
+
+func TestOperationProcess(t *testing.T) {
+	process := &fakeProcess{}
+	commandMapping["hang"] = hangFactory{process: process}
+	defer delete(commandMapping, "hang")
+
+	p := &Server{
+		RunningEnvironment: &SkyPanel.Environment{
+			Implementation: process,
+			ConsoleBuffer:  SkyPanel.CreateCache(),
+			ConsoleTracker: SkyPanel.CreateTracker(),
+		},
+	}
+
+	generate := func(steps ...SkyPanel.ConditionalMetadataType) OperationProcess {
+		ops, err := GenerateProcess(steps, p.RunningEnvironment, map[string]interface{}{}, map[string]string{})
+		assert.NoError(t, err)
+		return ops
+	}
+	step := func(opType string, metadata map[string]interface{}) SkyPanel.ConditionalMetadataType {
+		return SkyPanel.ConditionalMetadataType{MetadataType: SkyPanel.MetadataType{Type: opType, Metadata: metadata}}
+	}
+
+	t.Run("TimeoutIsNotPassedOn", func(t *testing.T) {
+		ops := generate(step("sleep", map[string]interface{}{"duration": "1s", "timeout": "2m"}))
+		assert.Equal(t, 2*time.Minute, ops[0].Timeout)
+		assert.NotContains(t, ops[0].Operation.OperationArgs, "timeout")
+
+		_, err := GenerateProcess([]SkyPanel.ConditionalMetadataType{step("sleep", map[string]interface{}{"timeout": "soon"})}, p.RunningEnvironment, nil, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("VariablesFromEarlierSteps", func(t *testing.T) {
+		var created []map[string]interface{}
+		commandMapping["set"] = setFactory{created: &created}
+		defer delete(commandMapping, "set")
+		p.Variables = map[string]SkyPanel.Variable{}
+
+		ops := generate(
+			step("set", map[string]interface{}{"value": "1.21.4"}),
+			step("set", map[string]interface{}{"value": "${resolved}", "list": []interface{}{"v${resolved}"}}),
+		)
+		assert.NoError(t, ops.Run(context.Background(), p))
+		assert.Equal(t, "1.21.4", created[1]["value"])
+		assert.Equal(t, []string{"v1.21.4"}, created[1]["list"])
+		assert.Equal(t, "1.21.4", p.Variables["resolved"].Value)
+	})
+
+	t.Run("Progress", func(t *testing.T) {
+		skipped := step("sleep", map[string]interface{}{"duration": "1ms"})
+		skipped.If = "false"
+		ops := generate(step("sleep", map[string]interface{}{"duration": "1ms"}), skipped)
+		assert.NoError(t, ops.Run(context.Background(), p))
+		assert.Equal(t, &SkyPanel.OperationProgress{Step: 2, Total: 2, Operation: "sleep", State: SkyPanel.ProgressSkipped}, p.RunningEnvironment.GetProgress())
+
+		ops = generate(step("hang", map[string]interface{}{"timeout": "10ms"}))
+		assert.Error(t, ops.Run(context.Background(), p))
+		progress := p.RunningEnvironment.GetProgress()
+		assert.Equal(t, SkyPanel.ProgressFailed, progress.State)
+		assert.Equal(t, "hang", progress.Operation)
+		assert.NotEmpty(t, progress.Error)
+	})
+
+	t.Run("OperationTimeoutKillsProcess", func(t *testing.T) {
+		ops := generate(step("hang", map[string]interface{}{"timeout": 0.05}))
+		err := ops.Run(context.Background(), p)
+		assert.Equal(t, "ErrOperationTimeout", SkyPanel.FromError(err).GetCode())
+
+		running, _ := p.RunningEnvironment.IsRunning()
+		assert.False(t, running)
+	})
+
+	t.Run("CancelStopsProcess", func(t *testing.T) {
+		ops := generate(
+			step("sleep", map[string]interface{}{"duration": "1h"}),
+			step("hang", nil),
+		)
+		ctx, cancel := context.WithCancelCause(context.Background())
+		time.AfterFunc(50*time.Millisecond, func() {
+			cancel(SkyPanel.ErrOperationCancelled)
+		})
+
+		started := time.Now()
+		err := ops.Run(ctx, p)
+		assert.Equal(t, "ErrOperationCancelled", SkyPanel.FromError(err).GetCode())
+		assert.Less(t, time.Since(started), time.Second)
+	})
+}
+
+func TestCancelInstall(t *testing.T) {
+	p := &Server{}
+	assert.Equal(t, "ErrNotInstalling", SkyPanel.FromError(p.CancelInstall()).GetCode())
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	p.setInstallCancel(cancel)
+	assert.NoError(t, p.CancelInstall())
+	assert.Equal(t, SkyPanel.ErrOperationCancelled, context.Cause(ctx))
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: nil, want: 0},
+		{value: "", want: 0},
+		{value: "90s", want: 90 * time.Second},
+		{value: 30, want: 30 * time.Second},
+		{value: "45", want: 45 * time.Second},
+		{value: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeout(tt.value)
+		if tt.wantErr {
+			assert.Error(t, err)
+			continue
+		}
+		if assert.NoError(t, err) {
+			assert.Equal(t, tt.want, got)
+		}
+	}
+}
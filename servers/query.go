@@ -0,0 +1,91 @@
+package servers
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/query"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+	"github.com/spf13/cast"
+)
+
+var ErrQueryNotSupported = errors.New("server does not support querying")
+var ErrQueryHostNotAllowed = errors.New("query host is not an address of this node")
+
+// QueryGame asks the game for information such as its players, using the protocol set in the query settings of the server.
+// The query settings may set host, port and token, which can use variables such as ${queryport}.
+// Without them the ip and port variables of the server are used.
+// The host can only point to the node itself, so a server definition cannot send queries to other machines.
+// The result is keyed by the protocol that answered.
+func (p *Server) QueryGame() (map[string]interface{}, error) {
+	return p.queryGame(0)
+}
+
+// queryGame queries the game giving up after timeout, zero uses the default of the query package
+func (p *Server) queryGame(timeout time.Duration) (map[string]interface{}, error) {
+	name := p.Server.Query.Type
+	if !query.Supported(name) {
+		return nil, ErrQueryNotSupported
+	}
+
+	data := p.DataToMap()
+	target := query.Target{
+		Host:    cast.ToString(data["ip"]),
+		Port:    cast.ToInt(data["port"]),
+		Timeout: timeout,
+		Options: map[string]string{},
+	}
+	for k, v := range p.Server.Query.Metadata {
+		value := utils.ReplaceTokens(cast.ToString(v), data)
+		switch k {
+		case "host":
+			if value != "" {
+				host, err := localHost(value)
+				if err != nil {
+					return nil, err
+				}
+				target.Host = host
+			}
+		case "port":
+			if port := cast.ToInt(value); port != 0 {
+				target.Port = port
+			}
+		default:
+			target.Options[k] = value
+		}
+	}
+	if target.Host == "" {
+		return nil, ErrQueryNotSupported
+	}
+
+	res, err := query.Query(name, target)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{name: res}, nil
+}
+
+// localHost resolves host and returns its address when it is loopback, unspecified or one of the node's interfaces
+func localHost(host string) (string, error) {
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return "", err
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsUnspecified() {
+			return ip.String(), nil
+		}
+		for _, addr := range addrs {
+			if network, ok := addr.(*net.IPNet); ok && network.IP.Equal(ip) {
+				return ip.String(), nil
+			}
+		}
+	}
+	return "", ErrQueryHostNotAllowed
+}
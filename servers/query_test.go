@@ -0,0 +1,33 @@
+package servers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalHost(t *testing.T) {
+	host, err := localHost("127.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", host)
+
+	host, err = localHost("0.0.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0.0.0", host)
+
+	addrs, err := net.InterfaceAddrs()
+	if assert.NoError(t, err) {
+		for _, addr := range addrs {
+			if network, ok := addr.(*net.IPNet); ok && network.IP.To4() != nil && !network.IP.IsLoopback() {
+				host, err = localHost(network.IP.String())
+				assert.NoError(t, err)
+				assert.Equal(t, network.IP.String(), host)
+			}
+		}
+	}
+
+	//documentation range, never an address of the node
+	_, err = localHost("192.0.2.1")
+	assert.ErrorIs(t, err, ErrQueryHostNotAllowed)
+}
@@ -1,6 +1,7 @@
 package servers
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/go-co-op/gocron/v2"
 	"github.com/SkyPanel/SkyPanel/v3"
@@ -193,7 +194,22 @@ func _executeTask(serverId string, id string) {
 			return
 		}
 
-		err = process.Run(p)
+		var timeout time.Duration
+		timeout, err = parseTimeout(task.Timeout)
+		if err != nil {
+			logging.Error.Printf("Error setting up tasks: %s", err)
+			p.RunningEnvironment.DisplayToConsole(true, "Failed to setup tasks\n")
+			return
+		}
+
+		ctx := context.Background()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeoutCause(ctx, timeout, SkyPanel.ErrOperationTimeout(task.Name, timeout))
+			defer cancel()
+		}
+
+		err = process.Run(ctx, p)
 		if err != nil {
 			logging.Error.Printf("Error setting up tasks: %s", err)
 			p.RunningEnvironment.DisplayToConsole(true, "Failed to setup tasks\n")
@@ -2,6 +2,7 @@ package servers
 
 import (
 	"container/list"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,13 +14,16 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/SkyPanel/SkyPanel/v3/conditions"
 	"github.com/SkyPanel/SkyPanel/v3/config"
 	"github.com/SkyPanel/SkyPanel/v3/database"
 	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/history"
 	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/models"
 	"github.com/SkyPanel/SkyPanel/v3/services"
 	"github.com/SkyPanel/SkyPanel/v3/utils"
 	"github.com/SkyPanel/SkyPanel/v3"
@@ -40,10 +44,25 @@ type Server struct {
 	stopChan           chan bool
 	waitForConsole     sync.Locker
 	fileServer         files.FileServer
+	diskQuota          *files.Quota
+	crashes            atomic.Int64
+	lastBackup         atomic.Pointer[BackupResult]
 	backingUp          bool
 	restoring          bool
 	keepAlive          *time.Ticker
 	keepAliveChan      chan bool
+	startedAt          time.Time
+	crashLock          sync.Mutex
+	crashTimes         []time.Time
+	crashLooping       bool
+	restartTimer       *time.Timer
+	nextRestart        time.Time
+	watchdogLock       sync.Mutex
+	watchdog           *watchdog
+	killedByWatchdog   atomic.Bool
+	downCause          atomic.Value
+	installLock        sync.Mutex
+	cancelInstall      context.CancelCauseFunc
 }
 
 var queue *list.List
@@ -58,7 +77,16 @@ var stateTrackingLock = sync.RWMutex{}
 type serverState struct {
 	wasRunning bool
 	lastStats  *SkyPanel.ServerStats
-	lastAlert  map[string]time.Time // Para evitar spam de alertas
+}
+
+// diskScanInterval is how often the disk usage is counted again from scratch
+const diskScanInterval = 10 * time.Minute
+
+// BackupResult describes how the last backup of a server went
+type BackupResult struct {
+	Started  time.Time
+	Duration time.Duration
+	Success  bool
 }
 
 var ErrServerTypeRequired = errors.New("server type is required")
@@ -515,7 +543,13 @@ func SendStatsForServers() {
 		wg.Add(1)
 		go func(p *Server) {
 			defer wg.Done()
-			stats, err := p.GetEnvironment().GetStats()
+			if p.diskQuota != nil {
+				p.diskQuota.RecalculateIfOlder(diskScanInterval, func(err error) {
+					p.Log(logging.Error, "Error calculating disk usage: %s", err)
+				})
+			}
+
+			stats, err := p.GetStats()
 			if err != nil {
 				return
 			}
@@ -525,6 +559,8 @@ func SendStatsForServers() {
 				Type:    SkyPanel.MessageTypeStats,
 			})
 
+			history.Record(p.Id(), stats)
+
 			// Monitorear para alertas
 			go checkServerAlerts(p, stats)
 
@@ -535,6 +571,12 @@ func SendStatsForServers() {
 	wg.Wait()
 }
 
+// DownCause dice por qué se paró el servidor la última vez, vacío si no se sabe o si está arrancado
+func (p *Server) DownCause() string {
+	cause, _ := p.downCause.Load().(string)
+	return cause
+}
+
 func trackUptime(server *Server) {
 	isRunning, err := server.IsRunning()
 	if err != nil {
@@ -547,7 +589,7 @@ func trackUptime(server *Server) {
 	}
 
 	us := &services.Uptime{DB: db}
-	err = us.TrackStatus(server.Id(), isRunning)
+	err = us.TrackStatus(server.Id(), isRunning, server.DownCause())
 	if err != nil {
 		logging.Error.Printf("[%s] Error tracking uptime: %s", server.Id(), err)
 	}
@@ -566,67 +608,22 @@ func checkServerAlerts(server *Server, stats *SkyPanel.ServerStats) {
 		state = &serverState{
 			wasRunning: isRunning,
 			lastStats:  stats,
-			lastAlert:  make(map[string]time.Time),
 		}
 		serverStateTracking[serverID] = state
 		return // Primera vez, no enviar alertas
 	}
 
-	serverName := server.Server.Display
-	if serverName == "" {
-		serverName = serverID
-	}
-
 	// Verificar cambio de estado online/offline
 	if state.wasRunning != isRunning {
-		ds := services.GetDiscordService()
 		if isRunning {
-			_ = ds.SendServerOnlineAlert(serverName, serverID)
+			server.notifyOnline()
 		} else {
-			_ = ds.SendServerOfflineAlert(serverName, serverID)
+			server.notifyOffline()
 		}
 		state.wasRunning = isRunning
 	}
 
-	// Verificar uso alto de recursos (solo si está online)
-	if isRunning && stats != nil {
-		now := time.Now()
-
-		// CPU > 80%
-		if stats.Cpu > 80.0 {
-			alertKey := "cpu_high"
-			lastAlert, hasAlerted := state.lastAlert[alertKey]
-			if !hasAlerted || now.Sub(lastAlert) > 5*time.Minute {
-				ds := services.GetDiscordService()
-				_ = ds.SendResourceAlert(serverName, serverID, "CPU", stats.Cpu, 80.0)
-				state.lastAlert[alertKey] = now
-			}
-		}
-
-		// RAM > 90%
-		// El campo Memory puede estar en bytes o porcentaje dependiendo del entorno
-		// Para Docker generalmente es porcentaje (0-100), para TTY puede ser bytes
-		// Si Memory > 100, probablemente está en bytes y no podemos calcular porcentaje fácilmente
-		// Solo alertar si Memory está en formato de porcentaje (0-100)
-		if stats.Memory > 90.0 && stats.Memory <= 100.0 {
-			// Es porcentaje
-			alertKey := "memory_high"
-			lastAlert, hasAlerted := state.lastAlert[alertKey]
-			if !hasAlerted || now.Sub(lastAlert) > 5*time.Minute {
-				ds := services.GetDiscordService()
-				_ = ds.SendResourceAlert(serverName, serverID, "Memoria", stats.Memory, 90.0)
-				state.lastAlert[alertKey] = now
-			}
-		}
-
-		// Limpiar alertas antiguas si el recurso ya no está alto
-		if stats.Cpu <= 80.0 {
-			delete(state.lastAlert, "cpu_high")
-		}
-		if stats.Memory <= 90.0 {
-			delete(state.lastAlert, "memory_high")
-		}
-	}
+	evaluateAlertRules(server, isRunning, stats)
 
 	// Actualizar stats anteriores
 	state.lastStats = stats
@@ -690,7 +687,7 @@ func (p *Server) Start() error {
 		return err
 	}
 
-	err = process.Run(p)
+	err = process.Run(context.Background(), p)
 	if err != nil {
 		p.Log(logging.Error, "Error running pre-execution steps: %s", err)
 		p.RunningEnvironment.DisplayToConsole(true, "Error running pre execute\n")
@@ -750,6 +747,7 @@ func (p *Server) Start() error {
 	data := p.DataToMap()
 
 	commandLine := utils.ReplaceTokens(command.Command, data)
+	p.startedAt = time.Now()
 	err = p.RunningEnvironment.ExecuteAsync(SkyPanel.ExecutionData{
 		Command:     commandLine,
 		Environment: utils.ReplaceTokensInMap(p.Execution.EnvironmentVariables, data),
@@ -764,6 +762,9 @@ func (p *Server) Start() error {
 		return err
 	}
 
+	p.downCause.Store("")
+	p.startWatchdog()
+
 	//keepalive!
 	if p.KeepAlive.Frequency != "" && p.KeepAlive.Command != "" {
 		dur, err := time.ParseDuration(p.KeepAlive.Frequency)
@@ -797,6 +798,10 @@ func (p *Server) Start() error {
 // Stop Stops the program.
 // This will also stop the environment it is ran in.
 func (p *Server) Stop() error {
+	p.cancelCrashRestart()
+	p.stopWatchdog()
+	p.downCause.Store(models.DowntimeStopped)
+
 	var err error
 	if r, err := p.IsRunning(); !r || err != nil {
 		return err
@@ -820,6 +825,9 @@ func (p *Server) Stop() error {
 // Kill Kills the program.
 // This will also stop the environment it is ran in.
 func (p *Server) Kill() (err error) {
+	p.cancelCrashRestart()
+	p.stopWatchdog()
+	p.downCause.Store(models.DowntimeStopped)
 	p.Log(logging.Info, "Killing server %s", p.Id())
 	err = p.RunningEnvironment.Kill()
 	if err != nil {
@@ -869,7 +877,7 @@ func (p *Server) Destroy() (err error) {
 		return
 	}
 
-	err = process.Run(p)
+	err = process.Run(context.Background(), p)
 	if err != nil {
 		p.Log(logging.Error, "Error uninstalling server: %s", err)
 		p.RunningEnvironment.DisplayToConsole(true, "Failed to uninstall server\n")
@@ -891,8 +899,24 @@ func (p *Server) Install() error {
 		return err
 	}
 
+	timeout, err := parseTimeout(p.InstallTimeout)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, timeout, SkyPanel.ErrOperationTimeout("install", timeout))
+		defer cancelTimeout()
+	}
+
+	p.GetEnvironment().SetProgress(nil)
 	p.GetEnvironment().SetInstalling(true)
+	p.setInstallCancel(cancel)
 	defer p.GetEnvironment().SetInstalling(false)
+	defer p.setInstallCancel(nil)
 
 	p.Log(logging.Info, "Installing server %s", p.Id())
 	r, err := p.IsRunning()
@@ -932,10 +956,38 @@ func (p *Server) Install() error {
 			return err
 		}
 
-		err = process.Run(p)
+		var snapshot string
+		if p.InstallSnapshot {
+			snapshot, err = p.createSnapshot()
+			if err != nil {
+				p.Log(logging.Error, "Error creating install snapshot: %s", err)
+				p.RunningEnvironment.DisplayToConsole(true, "Failed to snapshot server before installing\n")
+				return err
+			}
+			defer func() {
+				if snapshot != "" {
+					p.deleteSnapshot(snapshot)
+				}
+			}()
+		}
+
+		err = process.Run(ctx, p)
 		if err != nil {
 			p.Log(logging.Error, "Error installing server: %s", err)
-			p.RunningEnvironment.DisplayToConsole(true, "Failed to install server\n")
+			if ctx.Err() != nil {
+				p.RunningEnvironment.DisplayToConsole(true, "Install cancelled\n")
+			} else {
+				p.RunningEnvironment.DisplayToConsole(true, "Failed to install server\n")
+			}
+			if snapshot != "" && process.leftRunning() {
+				//restoring while a step still writes to the folder would leave it half restored
+				p.Log(logging.Error, "Not restoring install snapshot as a step is still running, it is kept at %s", snapshot)
+				p.RunningEnvironment.DisplayToConsole(true, "A step is still running, so the server was not restored from the snapshot\n")
+				//keep the snapshot so it can still be restored by hand
+				snapshot = ""
+			} else if snapshot != "" {
+				p.restoreSnapshot(snapshot)
+			}
 			return err
 		}
 	}
@@ -944,6 +996,34 @@ func (p *Server) Install() error {
 	return nil
 }
 
+// PlanInstall resolves the install steps of the server without running them
+func (p *Server) PlanInstall() ([]PlannedOperation, error) {
+	process, err := GenerateProcess(p.Installation, p.RunningEnvironment, p.DataToMap(), p.Execution.EnvironmentVariables)
+	if err != nil {
+		return nil, err
+	}
+	return process.Plan(p)
+}
+
+// CancelInstall stops the running install, the operation being run is killed if it does not stop by itself
+func (p *Server) CancelInstall() error {
+	p.installLock.Lock()
+	defer p.installLock.Unlock()
+
+	if p.cancelInstall == nil {
+		return SkyPanel.ErrNotInstalling
+	}
+	p.Log(logging.Info, "Cancelling install of server %s", p.Id())
+	p.cancelInstall(SkyPanel.ErrOperationCancelled)
+	return nil
+}
+
+func (p *Server) setInstallCancel(cancel context.CancelCauseFunc) {
+	p.installLock.Lock()
+	defer p.installLock.Unlock()
+	p.cancelInstall = cancel
+}
+
 func (p *Server) IsRunning() (bool, error) {
 	return p.RunningEnvironment.IsRunning()
 }
@@ -1043,10 +1123,23 @@ func (p *Server) afterExit(exitCode int) {
 		p.keepAlive.Stop()
 		p.keepAliveChan <- true
 	}
+	p.stopWatchdog()
 
-	graceful := exitCode == p.Execution.ExpectedExitCode
+	//the watchdog already saved a report and sent its own event
+	hung := p.killedByWatchdog.Swap(false)
+	graceful := exitCode == p.Execution.ExpectedExitCode && !hung
 	if graceful {
-		p.CrashCounter = 0
+		p.ResetCrashes()
+		if p.DownCause() == "" {
+			p.downCause.Store(models.DowntimeStopped)
+		}
+	} else {
+		p.downCause.Store(models.DowntimeCrash)
+		p.crashes.Add(1)
+		p.recordCrash(time.Now())
+		if !hung {
+			p.notifyCrash(p.captureCrashReport(exitCode, ""))
+		}
 	}
 
 	mapping := p.DataToMap()
@@ -1062,7 +1155,7 @@ func (p *Server) afterExit(exitCode int) {
 	p.RunningEnvironment.DisplayToConsole(true, "Running post-execution steps\n")
 	p.Log(logging.Info, "Running post execution steps: %s", p.Id())
 
-	err = processes.Run(p)
+	err = processes.Run(context.Background(), p)
 	if err != nil {
 		p.Log(logging.Error, "Error running post processing for server: %s", err)
 		p.RunningEnvironment.DisplayToConsole(true, "Failed to run post-execution steps\n")
@@ -1071,9 +1164,8 @@ func (p *Server) afterExit(exitCode int) {
 
 	if graceful && p.Execution.AutoRestartFromGraceful {
 		StartViaService(p)
-	} else if !graceful && p.Execution.AutoRestartFromCrash && p.CrashCounter < config.CrashLimit.Value() {
-		p.CrashCounter++
-		StartViaService(p)
+	} else if !graceful && (p.Execution.AutoRestartFromCrash || hung) {
+		p.scheduleCrashRestart()
 	}
 }
 
@@ -1138,7 +1230,17 @@ func (p *Server) ArchiveItems(sourceFiles []string, destination string) error {
 }
 
 func (p *Server) Extract(source, destination string) error {
-	return files.Extract(p.GetFileServer(), source, destination, "*", false, nil)
+	size, err := p.archiveSize(filepath.Join(p.GetFileServer().Prefix(), source))
+	if err != nil {
+		return err
+	}
+	if err = p.diskQuota.Check(size); err != nil {
+		return SkyPanel.ErrDiskQuotaExceeded
+	}
+
+	err = files.Extract(p.GetFileServer(), source, destination, "*", false, nil)
+	p.recalculateDiskUsage()
+	return err
 }
 
 func (p *Server) StartBackup() (string, error) {
@@ -1148,24 +1250,17 @@ func (p *Server) StartBackup() (string, error) {
 
 	p.backingUp = true
 	c := make(chan bool)
-	serverName := p.Server.Display
-	if serverName == "" {
-		serverName = p.Id()
-	}
+	started := time.Now()
 	go func(d chan bool) {
 		r := <-d
 		p.backingUp = false
+		p.lastBackup.Store(&BackupResult{Started: started, Duration: time.Since(started), Success: r})
 		if r {
 			p.RunningEnvironment.DisplayToConsole(true, "Backup complete")
-			// Enviar alerta de backup exitoso
-			ds := services.GetDiscordService()
-			_ = ds.SendBackupAlert(serverName, p.Id(), "Completado exitosamente", true)
 		} else {
 			p.RunningEnvironment.DisplayToConsole(true, "Backup failed")
-			// Enviar alerta de backup fallido
-			ds := services.GetDiscordService()
-			_ = ds.SendBackupAlert(serverName, p.Id(), "Falló durante la creación", false)
 		}
+		p.notifyBackup(r)
 	}(c)
 
 	p.RunningEnvironment.DisplayToConsole(true, "Backing up server")
@@ -1189,16 +1284,14 @@ func (p *Server) StartBackup() (string, error) {
 	backupFile := path.Join(backupDirectory, backupFileName)
 
 	go func(file string, d chan bool) {
-		defer func() {
-			d <- true
-		}()
 		sourceFiles := []string{filepath.Join(p.GetFileServer().Prefix())}
 
-		err = files.Compress(nil, file, sourceFiles)
+		err := files.Compress(nil, file, sourceFiles)
 		if err != nil {
 			p.Log(logging.Error, "Error creating backup file: %s", err)
 			p.RunningEnvironment.DisplayToConsole(true, "Failed to create backup file")
 		}
+		d <- err == nil
 	}(backupFile, c)
 
 	return backupFileName, nil
@@ -1247,45 +1340,66 @@ func (p *Server) StartRestore(fileName string) error {
 		return err
 	}
 
+	//everything is deleted before restoring, so the backup only has to fit in the quota by itself
+	size, err := p.archiveSize(backupFile)
+	if err != nil {
+		c <- false
+		return err
+	}
+	if limit := p.diskQuota.Limit(); limit > 0 && size > limit {
+		c <- false
+		return SkyPanel.ErrDiskQuotaExceeded
+	}
+
 	go func(source string, d chan bool) {
 		defer func() {
 			d <- true
 		}()
 
-		//Check if any files exist, as remove all errors if its empty
-		existingFiles, err := p.GetFileServer().Glob("*")
+		err := p.replaceFiles(source)
 		if err != nil {
-			p.Log(logging.Error, "Error globbing files: %s", err)
-			return
+			p.RunningEnvironment.DisplayToConsole(true, "Failed to restore files: %s", err)
 		}
+	}(backupFile, c)
 
-		for _, existingFile := range existingFiles {
-			file, err := p.GetFileServer().Stat(existingFile)
-			if err != nil {
-				p.Log(logging.Error, "Error deleting files: %s", err)
-				return
-			}
+	return nil
+}
 
-			if file.IsDir() {
-				err = p.GetFileServer().RemoveAll(existingFile)
-			} else {
-				err = p.GetFileServer().Remove(existingFile)
-			}
+// replaceFiles deletes everything in the server folder and extracts the archive in its place
+func (p *Server) replaceFiles(source string) error {
+	defer p.recalculateDiskUsage()
 
-			if err != nil {
-				p.Log(logging.Error, "Error deleting files: %s", err)
-				return
-			}
+	//Check if any files exist, as remove all errors if its empty
+	existingFiles, err := p.GetFileServer().Glob("*")
+	if err != nil {
+		p.Log(logging.Error, "Error globbing files: %s", err)
+		return err
+	}
+
+	for _, existingFile := range existingFiles {
+		file, err := p.GetFileServer().Stat(existingFile)
+		if err != nil {
+			p.Log(logging.Error, "Error deleting files: %s", err)
+			return err
+		}
+
+		if file.IsDir() {
+			err = p.GetFileServer().RemoveAll(existingFile)
+		} else {
+			err = p.GetFileServer().Remove(existingFile)
 		}
 
-		err = files.Extract(nil, source, p.GetFileServer().Prefix(), "*", true, nil)
 		if err != nil {
-			p.Log(logging.Error, "Error restoring files: %s", err)
-			p.RunningEnvironment.DisplayToConsole(true, "Failed to restore files: %s", err)
+			p.Log(logging.Error, "Error deleting files: %s", err)
+			return err
 		}
-	}(backupFile, c)
+	}
 
-	return nil
+	err = files.Extract(nil, source, p.GetFileServer().Prefix(), "*", true, nil)
+	if err != nil {
+		p.Log(logging.Error, "Error restoring files: %s", err)
+	}
+	return err
 }
 
 func (p *Server) GetBackup(fileName string) (*FileData, error) {
@@ -1359,6 +1473,69 @@ func (p *Server) SetFileServer(fs files.FileServer) {
 	p.fileServer = fs
 }
 
+func (p *Server) GetDiskQuota() *files.Quota {
+	return p.diskQuota
+}
+
+// GetStats gets the stats from the environment, adding the disk usage of the server
+func (p *Server) GetStats() (*SkyPanel.ServerStats, error) {
+	stats, err := p.GetEnvironment().GetStats()
+	if err != nil {
+		return nil, err
+	}
+
+	//environments hand out their cached stats, so do not modify them
+	result := *stats
+	result.Disk = p.GetDiskUsage()
+	return &result, nil
+}
+
+func (p *Server) GetDiskUsage() *SkyPanel.DiskUsage {
+	if p.diskQuota == nil {
+		return nil
+	}
+	return &SkyPanel.DiskUsage{
+		Used:    p.diskQuota.Used(),
+		Quota:   p.diskQuota.Limit(),
+		Percent: p.diskQuota.Percent(),
+	}
+}
+
+func (p *Server) archiveSize(archive string) (int64, error) {
+	if p.diskQuota == nil || p.diskQuota.Limit() <= 0 {
+		return 0, nil
+	}
+	return files.UncompressedSize(archive, nil)
+}
+
+func (p *Server) recalculateDiskUsage() {
+	if p.diskQuota == nil {
+		return
+	}
+	if err := p.diskQuota.Recalculate(); err != nil {
+		p.Log(logging.Error, "Error calculating disk usage: %s", err)
+	}
+}
+
+func (p *Server) diskQuotaWarning(used, limit int64) {
+	percent := float64(used) / float64(limit) * 100
+	p.Log(logging.Info, "Disk usage at %.0f%% of the quota (%d of %d bytes)", percent, used, limit)
+	if p.RunningEnvironment != nil {
+		p.RunningEnvironment.DisplayToConsole(true, "Warning: disk usage is at %.0f%% of the quota (%d MiB of %d MiB)\n", percent, used/1024/1024, limit/1024/1024)
+	}
+	p.notifyDiskWarning(used, limit)
+}
+
+// CrashCount is how many times the server exited unexpectedly since the daemon started
+func (p *Server) CrashCount() int64 {
+	return p.crashes.Load()
+}
+
+// LastBackup returns the result of the last backup taken since the daemon started, or nil if there was none
+func (p *Server) LastBackup() *BackupResult {
+	return p.lastBackup.Load()
+}
+
 func (p *Server) IsBackingUp() bool {
 	return p.backingUp
 }
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/addons"
 	"github.com/SkyPanel/SkyPanel/v3/config"
 	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/gitdeploy"
+	"github.com/SkyPanel/SkyPanel/v3/history"
 	"github.com/SkyPanel/SkyPanel/v3/logging"
 	"os"
 	"path/filepath"
@@ -112,6 +115,9 @@ func LoadFromData(id string, source []byte) (*Server, error) {
 	}
 	data.SetFileServer(fs)
 
+	data.diskQuota = files.NewQuota(data.RunningEnvironment.GetRootDirectory(), data.Server.DiskQuota*1024*1024)
+	data.diskQuota.OnWarning = data.diskQuotaWarning
+
 	data.Scheduler.Start()
 
 	return data, nil
@@ -202,6 +208,13 @@ func Delete(id string) (err error) {
 	if err != nil {
 		logging.Error.Printf("Error removing server: %s", err)
 	}
+	history.DeleteServer(program.Id())
+	addons.DeleteServer(program.Id())
+	gitdeploy.DeleteServer(program.Id())
+	if err := program.deleteCrashReports(); err != nil {
+		logging.Error.Printf("Error removing crash reports: %s", err)
+	}
+	alertTracker.ForgetServer(program.Id())
 	allServers = append(allServers[:index], allServers[index+1:]...)
 	return
 }
@@ -241,6 +254,7 @@ func Reload(id string) (err error) {
 
 	program.RunningEnvironment = newVersion.RunningEnvironment
 	program.Server = newVersion.Server
+	program.diskQuota.SetLimit(program.Server.DiskQuota * 1024 * 1024)
 
 	program.Scheduler.Stop()
 	logging.Debug.Println("Rebuilding scheduler")
@@ -0,0 +1,229 @@
+package tty
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+)
+
+const cgroupMount = "/sys/fs/cgroup"
+const cpuPeriod = 100000
+
+var ErrCgroupsUnavailable = errors.New("cgroup v2 is not available, resource limits cannot be applied")
+
+var cgroupRoot string
+var cgroupRootErr error
+var cgroupRootOnce sync.Once
+
+type cgroup struct {
+	path   string
+	fd     *os.File
+	limits SkyPanel.ResourceLimits
+}
+
+// getCgroupRoot finds the cgroup the server cgroups are created under, preparing it the first time.
+// Unless configured, this is the cgroup the daemon itself was started in, which has to be delegated to us (e.g. Delegate=yes in systemd).
+func getCgroupRoot() (string, error) {
+	cgroupRootOnce.Do(func() {
+		cgroupRoot, cgroupRootErr = prepareCgroupRoot()
+		if cgroupRootErr != nil {
+			logging.Error.Printf("Unable to prepare cgroups for resource limits: %s", cgroupRootErr)
+		}
+	})
+	return cgroupRoot, cgroupRootErr
+}
+
+func prepareCgroupRoot() (string, error) {
+	if _, err := os.Stat(filepath.Join(cgroupMount, "cgroup.controllers")); err != nil {
+		return "", ErrCgroupsUnavailable
+	}
+
+	root := config.CgroupRoot.Value()
+	if root == "" {
+		data, err := os.ReadFile("/proc/self/cgroup")
+		if err != nil {
+			return "", err
+		}
+		//the unified hierarchy is the line "0::/path"
+		for _, line := range strings.Split(string(data), "\n") {
+			if path, ok := strings.CutPrefix(line, "0::"); ok {
+				root = filepath.Join(cgroupMount, path)
+				break
+			}
+		}
+		if root == "" {
+			return "", ErrCgroupsUnavailable
+		}
+
+		//a cgroup with processes cannot hand controllers to its children, so move ourselves into a leaf
+		if err = moveProcesses(root, filepath.Join(root, "daemon")); err != nil {
+			return "", err
+		}
+	}
+
+	available, err := os.ReadFile(filepath.Join(root, "cgroup.controllers"))
+	if err != nil {
+		return "", err
+	}
+	var enable []string
+	for _, v := range strings.Fields(string(available)) {
+		switch v {
+		case "cpu", "memory", "pids", "io":
+			enable = append(enable, "+"+v)
+		}
+	}
+	if len(enable) > 0 {
+		if err = os.WriteFile(filepath.Join(root, "cgroup.subtree_control"), []byte(strings.Join(enable, " ")), 0644); err != nil {
+			return "", fmt.Errorf("could not enable cgroup controllers in %s: %w", root, err)
+		}
+	}
+	return root, nil
+}
+
+func moveProcesses(from, to string) error {
+	data, err := os.ReadFile(filepath.Join(from, "cgroup.procs"))
+	if err != nil {
+		return err
+	}
+	pids := strings.Fields(string(data))
+	if len(pids) == 0 {
+		return nil
+	}
+
+	if err = os.MkdirAll(to, 0755); err != nil {
+		return err
+	}
+	for _, pid := range pids {
+		//processes may have exited since we listed them
+		if err = os.WriteFile(filepath.Join(to, "cgroup.procs"), []byte(pid), 0644); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logging.Debug.Printf("Could not move process %s to %s: %s", pid, to, err)
+		}
+	}
+	return nil
+}
+
+// createCgroup creates the cgroup for a server and writes its limits
+func createCgroup(serverId string, limits SkyPanel.ResourceLimits) (*cgroup, error) {
+	root, err := getCgroupRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	cg := &cgroup{path: filepath.Join(root, "server-"+serverId), limits: limits}
+	//a previous run may have left it behind
+	cg.destroy()
+
+	if err = os.Mkdir(cg.path, 0755); err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{}
+	if limits.MemoryMax > 0 {
+		files["memory.max"] = strconv.FormatInt(limits.MemoryBytes(), 10)
+	}
+	if limits.CpuQuota > 0 {
+		files["cpu.max"] = fmt.Sprintf("%d %d", int64(limits.CpuQuota*cpuPeriod), cpuPeriod)
+	}
+	if limits.PidsMax > 0 {
+		files["pids.max"] = strconv.FormatInt(limits.PidsMax, 10)
+	}
+	if limits.IOWeight > 0 {
+		files["io.weight"] = fmt.Sprintf("default %d", limits.IOWeight)
+	}
+	for file, value := range files {
+		if err = os.WriteFile(filepath.Join(cg.path, file), []byte(value), 0644); err != nil {
+			cg.destroy()
+			return nil, fmt.Errorf("could not set %s: %w", file, err)
+		}
+	}
+
+	cg.fd, err = os.Open(cg.path)
+	if err != nil {
+		cg.destroy()
+		return nil, err
+	}
+	return cg, nil
+}
+
+// stats reads the current usage of the cgroup
+func (cg *cgroup) stats() *SkyPanel.LimitStats {
+	stats := &SkyPanel.LimitStats{
+		CpuQuota: cg.limits.CpuQuota,
+		IOWeight: cg.limits.IOWeight,
+	}
+	stats.MemoryCurrent, _ = readCgroupValue(filepath.Join(cg.path, "memory.current"))
+	stats.MemoryMax, _ = readCgroupValue(filepath.Join(cg.path, "memory.max"))
+	stats.PidsCurrent, _ = readCgroupValue(filepath.Join(cg.path, "pids.current"))
+	stats.PidsMax, _ = readCgroupValue(filepath.Join(cg.path, "pids.max"))
+
+	if cpu, err := readCgroupKeyed(filepath.Join(cg.path, "cpu.stat")); err == nil {
+		stats.CpuThrottledMicros = cpu["throttled_usec"]
+	}
+	if events, err := readCgroupKeyed(filepath.Join(cg.path, "memory.events")); err == nil {
+		stats.OomKills = events["oom_kill"]
+	}
+	return stats
+}
+
+// destroy kills anything left in the cgroup and removes it
+func (cg *cgroup) destroy() {
+	if cg.fd != nil {
+		_ = cg.fd.Close()
+		cg.fd = nil
+	}
+	if _, err := os.Stat(cg.path); err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(cg.path, "cgroup.kill"), []byte("1"), 0644)
+	if err := os.Remove(cg.path); err != nil {
+		logging.Debug.Printf("Failed to remove cgroup %s: %s", cg.path, err.Error())
+	}
+}
+
+// readCgroupValue reads a single value file, where "max" means no limit and is returned as 0
+func readCgroupValue(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return parseCgroupValue(string(data))
+}
+
+func parseCgroupValue(data string) (uint64, error) {
+	data = strings.TrimSpace(data)
+	if data == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(data, 10, 64)
+}
+
+// readCgroupKeyed reads a flat keyed file like cpu.stat, one "key value" per line
+func readCgroupKeyed(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	result := map[string]uint64{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		if v, err := parseCgroupValue(value); err == nil {
+			result[key] = v
+		}
+	}
+	return result, scanner.Err()
+}
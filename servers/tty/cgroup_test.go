@@ -0,0 +1,46 @@
+package tty
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCgroupValue(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    uint64
+		wantErr bool
+	}{
+		{data: "max\n", want: 0},
+		{data: "1073741824\n", want: 1073741824},
+		{data: "150000 100000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			got, err := parseCgroupValue(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCgroupValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseCgroupValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCgroupKeyed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cpu.stat")
+	data := "usage_usec 5000\nnr_periods 10\nnr_throttled 2\nthrottled_usec 1234\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readCgroupKeyed(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["throttled_usec"] != 1234 || got["nr_throttled"] != 2 {
+		t.Errorf("readCgroupKeyed() = %v", got)
+	}
+}
@@ -29,16 +29,20 @@ type tty struct {
 	lastStatTime time.Time
 	//disableStdin        bool
 	disableSpecialStats bool
+	cgroup              *cgroup
+	ioRates             SkyPanel.RateCounter
 
-	DisableUnshare bool     `json:"disableUnshare"`
-	Mounts         []string `json:"mounts"`
+	DisableUnshare bool                    `json:"disableUnshare"`
+	Mounts         []string                `json:"mounts"`
+	Limits         SkyPanel.ResourceLimits `json:"limits"`
 }
 
 func (t *tty) ExecuteAsyncImpl(environment *SkyPanel.Environment, steps SkyPanel.ExecutionData) (err error) {
 	environment.Wait.Add(1)
 
-	pr, err := t.createCmd(environment.GetRootDirectory(), steps.Command)
+	pr, err := t.createCmd(environment.ServerId, environment.GetRootDirectory(), steps.Command)
 	if err != nil {
+		environment.Wait.Done()
 		return err
 	}
 
@@ -81,6 +85,7 @@ func (t *tty) ExecuteAsyncImpl(environment *SkyPanel.Environment, steps SkyPanel
 
 	processTty, err := pty.Start(pr)
 	if err != nil {
+		t.removeCgroup()
 		environment.Wait.Done()
 		return
 	}
@@ -150,6 +155,13 @@ func (t *tty) GetStatsImpl(environment *SkyPanel.Environment) (*SkyPanel.ServerS
 		Memory: cast.ToFloat64(memMap.RSS),
 	}
 
+	if t.cgroup != nil {
+		stats.Limits = t.cgroup.stats()
+	}
+
+	t.collectProcessStats(pr, stats)
+	//network stays empty, the counters in /proc are those of the whole network namespace and tty shares the one of the node
+
 	if !t.disableSpecialStats && environment.Server.Stats.Type == "jcmd" {
 		var socket *net.UnixConn
 		if socket, err = t.initiateJCMD(); err == nil && socket != nil {
@@ -181,6 +193,38 @@ func (t *tty) GetStatsImpl(environment *SkyPanel.Environment) (*SkyPanel.ServerS
 	return stats, nil
 }
 
+// collectProcessStats adds up the disk IO, open files and threads of the process and everything it started,
+// as the main process is usually only a wrapper around the actual server
+func (t *tty) collectProcessStats(main *process.Process, stats *SkyPanel.ServerStats) {
+	var readBytes, writeBytes uint64
+
+	processes := []*process.Process{main}
+	for i := 0; i < len(processes); i++ {
+		pr := processes[i]
+		if counters, err := pr.IOCounters(); err == nil {
+			readBytes += counters.ReadBytes
+			writeBytes += counters.WriteBytes
+		}
+		if fds, err := pr.NumFDs(); err == nil {
+			stats.OpenFiles += fds
+		}
+		if threads, err := pr.NumThreads(); err == nil {
+			stats.Threads += threads
+		}
+		if children, err := pr.Children(); err == nil {
+			processes = append(processes, children...)
+		}
+	}
+
+	rates := t.ioRates.Rates(readBytes, writeBytes)
+	stats.DiskIO = &SkyPanel.DiskIOStats{
+		ReadBytes:  readBytes,
+		WriteBytes: writeBytes,
+		ReadRate:   rates[0],
+		WriteRate:  rates[1],
+	}
+}
+
 func (t *tty) SendCodeImpl(environment *SkyPanel.Environment, code int) error {
 	running, err := environment.IsRunning()
 
@@ -243,6 +287,7 @@ func (t *tty) handleClose(environment *SkyPanel.Environment, callback func(exitC
 	}
 
 	t.statLocker.Lock()
+	t.removeCgroup()
 	t.statLocker.Unlock()
 
 	//if we are using unshare AND we're in tmp, we can nuke the workspace at this point
@@ -267,6 +312,7 @@ func (t *tty) handleClose(environment *SkyPanel.Environment, callback func(exitC
 
 	//t.disableStdin = false
 	t.disableSpecialStats = false
+	t.ioRates.Reset()
 
 	if callback != nil {
 		callback(exitCode)
@@ -348,7 +394,13 @@ var cmdList = []string{
 	"mount --rbind /proc proc",
 }
 
-func (t *tty) createCmd(workDir, cmd string) (pr *exec.Cmd, err error) {
+func (t *tty) createCmd(serverId, workDir, cmd string) (pr *exec.Cmd, err error) {
+	defer func() {
+		if err == nil {
+			err = t.applyLimits(serverId, pr)
+		}
+	}()
+
 	if t.DisableUnshare || config.SecurityDisableUnshare.Value() {
 		c, args := utils.SplitArguments(cmd)
 		pr = exec.Command(c, args...)
@@ -452,6 +504,29 @@ func (t *tty) createCmd(workDir, cmd string) (pr *exec.Cmd, err error) {
 	return
 }
 
+// applyLimits places the process in its own cgroup when the server has resource limits.
+// The child is cloned directly into the cgroup, so it never runs unconstrained.
+func (t *tty) applyLimits(serverId string, pr *exec.Cmd) (err error) {
+	if !t.Limits.IsSet() {
+		return
+	}
+
+	t.cgroup, err = createCgroup(serverId, t.Limits)
+	if err != nil {
+		return
+	}
+	pr.SysProcAttr.UseCgroupFD = true
+	pr.SysProcAttr.CgroupFD = int(t.cgroup.fd.Fd())
+	return
+}
+
+func (t *tty) removeCgroup() {
+	if t.cgroup != nil {
+		t.cgroup.destroy()
+		t.cgroup = nil
+	}
+}
+
 func removeRoot(path string) string {
 	return strings.TrimPrefix(path, "/")
 }
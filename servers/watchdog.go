@@ -0,0 +1,257 @@
+package servers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/query"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+	"github.com/spf13/cast"
+)
+
+const (
+	HealthCheckQuery   = "query"
+	HealthCheckTcp     = "tcp"
+	HealthCheckRcon    = "rcon"
+	HealthCheckConsole = "console"
+)
+
+// defaults of the watchdog when the template does not set them
+const (
+	defaultHealthInterval  = 30 * time.Second
+	defaultHealthTimeout   = 10 * time.Second
+	defaultHealthGrace     = 2 * time.Minute
+	defaultHealthThreshold = 3
+)
+
+var ErrHealthCheckUnknown = errors.New("unknown health check type")
+var ErrHealthCheckNoMatch = errors.New("reply did not match the expected pattern")
+
+// watchdog checks every interval that the server answers, using the healthCheck of the template
+type watchdog struct {
+	server    *Server
+	check     SkyPanel.HealthCheck
+	interval  time.Duration
+	timeout   time.Duration
+	grace     time.Duration
+	threshold int
+	expect    *regexp.Regexp
+	stop      chan bool
+
+	failures     int
+	consoleEpoch int64
+}
+
+func newWatchdog(p *Server) (*watchdog, error) {
+	check := p.HealthCheck
+	w := &watchdog{
+		server:    p,
+		check:     check,
+		interval:  defaultHealthInterval,
+		timeout:   defaultHealthTimeout,
+		grace:     defaultHealthGrace,
+		threshold: defaultHealthThreshold,
+		stop:      make(chan bool),
+	}
+
+	switch check.Type {
+	case HealthCheckQuery, HealthCheckTcp, HealthCheckRcon, HealthCheckConsole:
+	default:
+		return nil, ErrHealthCheckUnknown
+	}
+
+	for _, v := range []struct {
+		value  string
+		target *time.Duration
+	}{{check.Interval, &w.interval}, {check.Timeout, &w.timeout}, {check.Grace, &w.grace}} {
+		if v.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v.value)
+		if err != nil {
+			return nil, err
+		}
+		*v.target = d
+	}
+	if w.interval <= 0 {
+		w.interval = defaultHealthInterval
+	}
+	if check.Threshold > 0 {
+		w.threshold = check.Threshold
+	}
+
+	if check.Expect != "" {
+		var err error
+		if w.expect, err = regexp.Compile(check.Expect); err != nil {
+			return nil, err
+		}
+	} else if check.Type == HealthCheckConsole {
+		return nil, errors.New("console health checks need an expect pattern")
+	}
+
+	return w, nil
+}
+
+// startWatchdog starts the watchdog for the process which was just started, if the template has one
+func (p *Server) startWatchdog() {
+	p.stopWatchdog()
+	if p.HealthCheck.Type == "" {
+		return
+	}
+
+	w, err := newWatchdog(p)
+	if err != nil {
+		p.RunningEnvironment.DisplayToConsole(true, "Failed to enable health check: %s\n", err)
+		return
+	}
+
+	p.watchdogLock.Lock()
+	p.watchdog = w
+	p.watchdogLock.Unlock()
+	go w.run()
+}
+
+func (p *Server) stopWatchdog() {
+	p.watchdogLock.Lock()
+	defer p.watchdogLock.Unlock()
+
+	if p.watchdog != nil {
+		close(p.watchdog.stop)
+		p.watchdog = nil
+	}
+}
+
+func (w *watchdog) run() {
+	select {
+	case <-w.stop:
+		return
+	case <-time.After(w.grace):
+	}
+
+	w.consoleEpoch = time.Now().UnixMicro()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if running, _ := w.server.IsRunning(); !running {
+				continue
+			}
+
+			err := w.probe()
+			if err == nil {
+				w.failures = 0
+				continue
+			}
+
+			w.failures++
+			w.server.Log(logging.Info, "Health check failed (%d/%d): %s", w.failures, w.threshold, err)
+			if w.failures >= w.threshold {
+				w.server.restartUnhealthy(err)
+				return
+			}
+		}
+	}
+}
+
+// probe runs one check. Every type is given the configured timeout,
+// so the check ends on its own instead of hanging in the background
+func (w *watchdog) probe() error {
+	data := w.server.DataToMap()
+
+	switch w.check.Type {
+	case HealthCheckQuery:
+		_, err := w.server.queryGame(w.timeout)
+		if isTimeout(err) {
+			return fmt.Errorf("no answer after %s", w.timeout)
+		}
+		return err
+	case HealthCheckTcp:
+		host, port := w.address(data)
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), w.timeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case HealthCheckRcon:
+		host, port := w.address(data)
+		password := utils.ReplaceTokens(w.check.Password, data)
+		reply, err := query.Rcon(host, port, password, utils.ReplaceTokens(w.check.Command, data), w.timeout)
+		if err != nil {
+			return err
+		}
+		if w.expect != nil && !w.expect.MatchString(reply) {
+			return ErrHealthCheckNoMatch
+		}
+		return nil
+	case HealthCheckConsole:
+		if w.check.Command != "" {
+			if err := w.server.RunningEnvironment.ExecuteInMainProcess(utils.ReplaceTokens(w.check.Command, data)); err != nil {
+				return err
+			}
+		}
+		return w.waitForHeartbeat()
+	}
+	return ErrHealthCheckUnknown
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// waitForHeartbeat looks for the pattern in what the console printed since the last check
+func (w *watchdog) waitForHeartbeat() error {
+	deadline := time.Now().Add(w.timeout)
+	for {
+		console, epoch := w.server.RunningEnvironment.GetConsoleFrom(w.consoleEpoch)
+		if w.expect.Match(console) {
+			w.consoleEpoch = epoch
+			return nil
+		}
+		if w.check.Command == "" || time.Now().After(deadline) {
+			w.consoleEpoch = epoch
+			return ErrHealthCheckNoMatch
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func (w *watchdog) address(data map[string]interface{}) (string, int) {
+	host := cast.ToString(data["ip"])
+	if w.check.Host != "" {
+		host = utils.ReplaceTokens(w.check.Host, data)
+	}
+	if host == "" || host == "0.0.0.0" {
+		host = "127.0.0.1"
+	}
+
+	port := cast.ToInt(data["port"])
+	if w.check.Port != "" {
+		port = cast.ToInt(utils.ReplaceTokens(w.check.Port, data))
+	}
+	return host, port
+}
+
+// restartUnhealthy saves a report of the hang, kills the process and leaves it to afterExit to start it again
+func (p *Server) restartUnhealthy(reason error) {
+	p.Log(logging.Error, "Server stopped responding, restarting it: %s", reason)
+	p.RunningEnvironment.DisplayToConsole(true, "Server stopped responding (%s), restarting it\n", reason)
+
+	p.notifyUnhealthy(p.captureCrashReport(-1, reason.Error()))
+
+	p.killedByWatchdog.Store(true)
+	if err := p.Kill(); err != nil {
+		p.killedByWatchdog.Store(false)
+	}
+}
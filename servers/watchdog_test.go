@@ -0,0 +1,114 @@
+package servers
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWatchdog(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   SkyPanel.HealthCheck
+		wantErr bool
+	}{
+		{name: "defaults", check: SkyPanel.HealthCheck{Type: HealthCheckTcp}},
+		{name: "unknown type", check: SkyPanel.HealthCheck{Type: "ping"}, wantErr: true},
+		{name: "invalid interval", check: SkyPanel.HealthCheck{Type: HealthCheckQuery, Interval: "soon"}, wantErr: true},
+		{name: "console without pattern", check: SkyPanel.HealthCheck{Type: HealthCheckConsole}, wantErr: true},
+		{name: "invalid pattern", check: SkyPanel.HealthCheck{Type: HealthCheckRcon, Expect: "("}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Server{Server: SkyPanel.Server{HealthCheck: tt.check}}
+			w, err := newWatchdog(p)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, defaultHealthInterval, w.interval)
+				assert.Equal(t, defaultHealthThreshold, w.threshold)
+			}
+		})
+	}
+}
+
+func TestWatchdogProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	console := SkyPanel.CreateCache()
+	p := &Server{
+		Server: SkyPanel.Server{
+			Variables: map[string]SkyPanel.Variable{
+				"ip":   {Value: "127.0.0.1"},
+				"port": {Value: port},
+			},
+		},
+		RunningEnvironment: &SkyPanel.Environment{ConsoleBuffer: console},
+	}
+
+	t.Run("Tcp", func(t *testing.T) {
+		p.HealthCheck = SkyPanel.HealthCheck{Type: HealthCheckTcp, Timeout: "1s"}
+		w, err := newWatchdog(p)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, w.probe())
+
+		_ = listener.Close()
+		assert.Error(t, w.probe())
+	})
+
+	t.Run("QueryTimeout", func(t *testing.T) {
+		//accept the connection but never answer the ping
+		silent, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer silent.Close()
+		go func() {
+			for {
+				conn, err := silent.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+			}
+		}()
+
+		p.Query = SkyPanel.MetadataType{Type: "minecraft", Metadata: map[string]interface{}{"port": silent.Addr().(*net.TCPAddr).Port}}
+		p.HealthCheck = SkyPanel.HealthCheck{Type: HealthCheckQuery, Timeout: "200ms"}
+		w, err := newWatchdog(p)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		start := time.Now()
+		assert.Error(t, w.probe())
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("Console", func(t *testing.T) {
+		p.HealthCheck = SkyPanel.HealthCheck{Type: HealthCheckConsole, Expect: "heartbeat"}
+		w, err := newWatchdog(p)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.ErrorIs(t, w.probe(), ErrHealthCheckNoMatch)
+
+		_, _ = console.Write([]byte("heartbeat\n"))
+		assert.NoError(t, w.probe())
+
+		//the same line does not count twice
+		assert.ErrorIs(t, w.probe(), ErrHealthCheckNoMatch)
+	})
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"gorm.io/gorm"
+)
+
+type AlertRule struct {
+	DB *gorm.DB
+}
+
+// alertRuleCacheTime is how long the enabled rules are kept in memory before they are read again
+const alertRuleCacheTime = time.Minute
+
+var alertRuleCache []*models.AlertRule
+var alertRuleCacheTimestamp time.Time
+var alertRuleCacheLock sync.Mutex
+
+func (as *AlertRule) Get(id uint) (*models.AlertRule, error) {
+	rule := &models.AlertRule{}
+	err := as.DB.First(rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// List gets the rules of a server, or every rule when serverId is empty
+func (as *AlertRule) List(serverId string) ([]*models.AlertRule, error) {
+	rules := make([]*models.AlertRule, 0)
+	query := as.DB.Order("id ASC")
+	if serverId != "" {
+		query = query.Where("server_id = ?", serverId)
+	}
+	err := query.Find(&rules).Error
+	return rules, err
+}
+
+func (as *AlertRule) Create(rule *models.AlertRule) error {
+	rule.ID = 0
+	err := as.DB.Create(rule).Error
+	invalidateAlertRules()
+	return err
+}
+
+func (as *AlertRule) Update(rule *models.AlertRule) error {
+	existing, err := as.Get(rule.ID)
+	if err != nil {
+		return err
+	}
+	rule.CreatedAt = existing.CreatedAt
+	err = as.DB.Save(rule).Error
+	invalidateAlertRules()
+	return err
+}
+
+func (as *AlertRule) Delete(id uint) error {
+	if _, err := as.Get(id); err != nil {
+		return err
+	}
+	err := as.DB.Delete(&models.AlertRule{}, id).Error
+	invalidateAlertRules()
+	return err
+}
+
+// GetEnabled gets every enabled rule, from memory when they were read recently
+func (as *AlertRule) GetEnabled() ([]*models.AlertRule, error) {
+	alertRuleCacheLock.Lock()
+	defer alertRuleCacheLock.Unlock()
+
+	if alertRuleCache != nil && time.Since(alertRuleCacheTimestamp) < alertRuleCacheTime {
+		return alertRuleCache, nil
+	}
+
+	rules := make([]*models.AlertRule, 0)
+	err := as.DB.Where("enabled = ?", true).Order("id ASC").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	alertRuleCache = rules
+	alertRuleCacheTimestamp = time.Now()
+	return rules, nil
+}
+
+func invalidateAlertRules() {
+	alertRuleCacheLock.Lock()
+	defer alertRuleCacheLock.Unlock()
+	alertRuleCache = nil
+}
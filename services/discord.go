@@ -114,66 +114,6 @@ func (ds *DiscordService) SendAlert(title, description string, fields []DiscordE
 	return ds.SendWebhook(title, description, 0xFF0000, fields) // Rojo para alertas
 }
 
-// SendServerOfflineAlert envía alerta cuando un servidor se desconecta
-func (ds *DiscordService) SendServerOfflineAlert(serverName, serverID string) error {
-	fields := []DiscordEmbedField{
-		{Name: "Servidor", Value: serverName, Inline: true},
-		{Name: "ID", Value: serverID, Inline: true},
-		{Name: "Estado", Value: "🔴 Offline", Inline: true},
-	}
-	return ds.SendAlert("⚠️ Servidor Desconectado", fmt.Sprintf("El servidor **%s** se ha desconectado o está offline.", serverName), fields)
-}
-
-// SendServerOnlineAlert envía alerta cuando un servidor se conecta
-func (ds *DiscordService) SendServerOnlineAlert(serverName, serverID string) error {
-	fields := []DiscordEmbedField{
-		{Name: "Servidor", Value: serverName, Inline: true},
-		{Name: "ID", Value: serverID, Inline: true},
-		{Name: "Estado", Value: "🟢 Online", Inline: true},
-	}
-	return ds.SendWebhook("✅ Servidor Conectado", fmt.Sprintf("El servidor **%s** está ahora online.", serverName), 0x00FF00, fields) // Verde
-}
-
-// SendResourceAlert envía alerta cuando el uso de recursos es alto
-func (ds *DiscordService) SendResourceAlert(serverName, serverID, resourceType string, currentValue, threshold float64) error {
-	fields := []DiscordEmbedField{
-		{Name: "Servidor", Value: serverName, Inline: true},
-		{Name: "ID", Value: serverID, Inline: true},
-		{Name: "Recurso", Value: resourceType, Inline: true},
-		{Name: "Uso Actual", Value: fmt.Sprintf("%.1f%%", currentValue), Inline: true},
-		{Name: "Umbral", Value: fmt.Sprintf("%.1f%%", threshold), Inline: true},
-	}
-
-	title := fmt.Sprintf("⚠️ Alto Uso de %s", resourceType)
-	description := fmt.Sprintf("El servidor **%s** está usando **%.1f%%** de %s (umbral: %.1f%%)", serverName, currentValue, resourceType, threshold)
-
-	return ds.SendAlert(title, description, fields)
-}
-
-// SendBackupAlert envía alerta sobre backups
-func (ds *DiscordService) SendBackupAlert(serverName, serverID, status string, isSuccess bool) error {
-	fields := []DiscordEmbedField{
-		{Name: "Servidor", Value: serverName, Inline: true},
-		{Name: "ID", Value: serverID, Inline: true},
-		{Name: "Estado", Value: status, Inline: true},
-	}
-
-	var title, description string
-	var color int
-
-	if isSuccess {
-		title = "✅ Backup Completado"
-		description = fmt.Sprintf("El backup del servidor **%s** se completó exitosamente.", serverName)
-		color = 0x00FF00 // Verde
-	} else {
-		title = "❌ Backup Fallido"
-		description = fmt.Sprintf("El backup del servidor **%s** falló: %s", serverName, status)
-		color = 0xFF0000 // Rojo
-	}
-
-	return ds.SendWebhook(title, description, color, fields)
-}
-
 // SendSystemStatus envía un resumen del estado completo del sistema
 func (ds *DiscordService) SendSystemStatus(servers []ServerInfo) error {
 	webhookURL := config.DiscordWebhookSystem.Value()
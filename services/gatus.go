@@ -21,15 +21,21 @@ import (
 var gatusConfigInstance *gatusConfig.Config
 var gatusRunning bool
 
-// StartGatus inicia Gatus como servicio independiente en puerto interno
-func StartGatus() error {
-	// Obtener la ruta del directorio de configuración
-	// Usar la misma carpeta base que los servidores
+// gatusNodeGroup es el grupo de Gatus de los endpoints generados para los nodos
+const gatusNodeGroup = "Nodos SkyPanel"
+
+// getGatusConfigPath da la ruta de config.yaml de Gatus, en la misma carpeta base que los servidores
+func getGatusConfigPath() string {
 	dataRoot := config.DataRootFolder.Value()
 	if dataRoot == "" {
 		dataRoot = "."
 	}
-	configPath := filepath.Join(dataRoot, "gatus", "config.yaml")
+	return filepath.Join(dataRoot, "gatus", "config.yaml")
+}
+
+// StartGatus inicia Gatus como servicio independiente en puerto interno
+func StartGatus() error {
+	configPath := getGatusConfigPath()
 
 	// Crear directorio si no existe
 	configDir := filepath.Dir(configPath)
@@ -79,10 +85,16 @@ func StartGatus() error {
 		return err
 	}
 
-	// Sincronizar nodos automáticamente antes de iniciar
+	// Sincronizar nodos y servidores monitorizados automáticamente antes de iniciar
 	if err := syncNodesToGatus(configPath, cfg); err != nil {
 		logging.Error.Printf("Error syncing nodes to Gatus: %s", err.Error())
 	} else {
+		if servers, err := getMonitoredServers(); err != nil {
+			logging.Error.Printf("Error loading monitored servers: %s", err.Error())
+		} else if err := syncServersToGatus(configPath, servers); err != nil {
+			logging.Error.Printf("Error syncing servers to Gatus: %s", err.Error())
+		}
+
 		// Si la sincronización fue exitosa, recargar la configuración actualizada
 		cfg, err = gatusConfig.LoadConfiguration(configPath)
 		if err != nil {
@@ -303,8 +315,8 @@ func syncNodesToGatus(configPath string, cfg *gatusConfig.Config) error {
 	// Identificar endpoints que son de nodos
 	for _, ep := range endpoints {
 		if epMap, ok := ep.(map[string]interface{}); ok {
-			// Si el grupo es "Nodos SkyPanel" o "Nodos SkyPanel", es un endpoint de nodo
-			if group, ok := epMap["group"].(string); ok && (group == "Nodos SkyPanel" || group == "Nodos SkyPanel") {
+			// Si el grupo es "Nodos SkyPanel", es un endpoint de nodo
+			if group, ok := epMap["group"].(string); ok && group == gatusNodeGroup {
 				if name, ok := epMap["name"].(string); ok {
 					nodeEndpointNames[name] = true
 				}
@@ -315,6 +327,11 @@ func syncNodesToGatus(configPath string, cfg *gatusConfig.Config) error {
 	// Crear endpoints para cada nodo
 	newEndpoints := []interface{}{}
 	for _, node := range nodes {
+		// Los nodos en modo reverse no son accesibles desde el panel, Gatus no puede sondearlos
+		if node.IsReverse() {
+			continue
+		}
+
 		nodeName := node.Name
 		if node.IsLocal() {
 			nodeName = "LocalNode"
@@ -339,7 +356,7 @@ func syncNodesToGatus(configPath string, cfg *gatusConfig.Config) error {
 		// Crear el endpoint
 		endpoint := map[string]interface{}{
 			"name":     nodeName,
-			"group":    "Nodos SkyPanel",
+			"group":    gatusNodeGroup,
 			"url":      daemonURL,
 			"interval": "1m",
 			"conditions": []interface{}{
@@ -359,8 +376,8 @@ func syncNodesToGatus(configPath string, cfg *gatusConfig.Config) error {
 
 	for _, ep := range endpoints {
 		if epMap, ok := ep.(map[string]interface{}); ok {
-			// Eliminar TODOS los endpoints de nodos antiguos (tanto SkyPanel como SkyPanel)
-			if group, ok := epMap["group"].(string); ok && (group == "Nodos SkyPanel" || group == "Nodos SkyPanel") {
+			// Eliminar TODOS los endpoints de nodos antiguos
+			if group, ok := epMap["group"].(string); ok && group == gatusNodeGroup {
 				continue // Eliminar endpoints de nodos antiguos
 			}
 			filteredEndpoints = append(filteredEndpoints, ep)
@@ -408,11 +425,7 @@ func SyncCompanyNameToGatus() error {
 	}
 
 	// Obtener la ruta del archivo de configuración
-	dataRoot := config.DataRootFolder.Value()
-	if dataRoot == "" {
-		dataRoot = "."
-	}
-	configPath := filepath.Join(dataRoot, "gatus", "config.yaml")
+	configPath := getGatusConfigPath()
 
 	// Verificar si el archivo existe
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/database"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	gatusConfig "github.com/TwiN/gatus/v5/config"
+	gatusStorage "github.com/TwiN/gatus/v5/storage/store"
+	gatusPaging "github.com/TwiN/gatus/v5/storage/store/common/paging"
+	gatusWatchdog "github.com/TwiN/gatus/v5/watchdog"
+	"gopkg.in/yaml.v3"
+)
+
+// gatusServerGroup es el grupo de Gatus de los endpoints generados para los servidores
+const gatusServerGroup = "Servidores SkyPanel"
+
+// gatusServerLabel marca los endpoints que genera el panel, con el id del servidor.
+// Los endpoints sin esta etiqueta son del usuario y nunca se tocan.
+const gatusServerLabel = "skypanel_server"
+
+const (
+	GatusCheckTcp              = "tcp"
+	GatusCheckMinecraft        = "minecraft"
+	GatusCheckMinecraftBedrock = "minecraftBedrock"
+	GatusCheckSource           = "source"
+)
+
+// cuerpos de las consultas de cada protocolo, a los que el juego siempre responde
+const (
+	// legacy server list ping, los servidores Java contestan con un paquete de desconexión
+	minecraftPingBody = "\xfe\x01"
+	// unconnected ping de RakNet: id, tiempo, magic y guid del cliente
+	bedrockPingBody = "\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\xff\xff\x00\xfe\xfe\xfe\xfe\xfd\xfd\xfd\xfd\x12\x34\x56\x78\x00\x00\x00\x00\x00\x00\x00\x00"
+	// A2S_INFO
+	sourceQueryBody = "\xff\xff\xff\xffTSource Engine Query\x00"
+)
+
+// gatusLock evita que se escriba la configuración de Gatus desde dos peticiones a la vez
+var gatusLock sync.Mutex
+
+// GatusServerStatus es lo que Gatus sabe del endpoint de un servidor
+type GatusServerStatus struct {
+	Enabled   bool                `json:"enabled"`
+	Check     string              `json:"check,omitempty"`
+	Uptime24h *float64            `json:"uptime24h,omitempty"`
+	Results   []GatusServerResult `json:"results,omitempty"`
+} //@name GatusServerStatus
+
+type GatusServerResult struct {
+	Time     time.Time `json:"time"`
+	Success  bool      `json:"success"`
+	Duration int64     `json:"duration"` //milliseconds
+	Errors   []string  `json:"errors,omitempty"`
+} //@name GatusServerResult
+
+// GatusCheckType elige cómo se comprueba un servidor según su tipo: con una consulta del juego si se conoce el protocolo, o abriendo una conexión TCP
+func GatusCheckType(serverType string) string {
+	switch {
+	case strings.HasPrefix(serverType, "minecraft-bedrock"):
+		return GatusCheckMinecraftBedrock
+	case serverType == "minecraft" || strings.HasPrefix(serverType, "minecraft-"):
+		return GatusCheckMinecraft
+	case serverType == "srcds" || strings.HasPrefix(serverType, "source"):
+		return GatusCheckSource
+	default:
+		return GatusCheckTcp
+	}
+}
+
+// QueueGatusServerSync sincroniza los servidores con Gatus en segundo plano.
+// Hay que llamarla cuando los cambios ya están guardados, desde una petición con transacción con middleware.AfterCommit.
+func QueueGatusServerSync() {
+	if !config.GatusEnabled.Value() {
+		return
+	}
+	go SyncServersToGatus()
+}
+
+// SyncServersToGatus regenera los endpoints de los servidores con monitorización externa y recarga Gatus
+func SyncServersToGatus() {
+	if !config.GatusEnabled.Value() {
+		return
+	}
+
+	servers, err := getMonitoredServers()
+	if err != nil {
+		logging.Error.Printf("Error loading monitored servers: %s", err)
+		return
+	}
+
+	gatusLock.Lock()
+	defer gatusLock.Unlock()
+
+	configPath := getGatusConfigPath()
+	if err = syncServersToGatus(configPath, servers); err != nil {
+		logging.Error.Printf("Error syncing servers to Gatus: %s", err)
+		return
+	}
+	if err = reloadGatusEndpoints(configPath); err != nil {
+		logging.Error.Printf("Error reloading Gatus configuration: %s", err)
+	}
+}
+
+func getMonitoredServers() ([]*models.Server, error) {
+	db, err := database.GetConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	var servers []*models.Server
+	err = db.Preload("Node").Where("monitored = ?", true).Find(&servers).Error
+	return servers, err
+}
+
+// syncServersToGatus deja un endpoint por cada servidor monitorizado.
+// De los endpoints que ya existen solo se cambian el nombre, la url y el cuerpo, el resto se respeta por si se editó a mano.
+// El archivo se edita como árbol de YAML para no perder los comentarios.
+func syncServersToGatus(configPath string, servers []*models.Server) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to parse YAML: the config is not a mapping")
+	}
+
+	wanted := make(map[string]*models.Server, len(servers))
+	for _, v := range servers {
+		if v.Port == 0 {
+			logging.Info.Printf("Gatus: Server %s has no port, it cannot be monitored", v.Identifier)
+			continue
+		}
+		wanted[v.Identifier] = v
+	}
+
+	endpoints := yamlMappingValue(root, "endpoints")
+	if endpoints == nil || endpoints.Kind != yaml.SequenceNode {
+		endpoints = &yaml.Node{Kind: yaml.SequenceNode}
+		if err = yamlSetMapping(root, "endpoints", endpoints); err != nil {
+			return err
+		}
+	}
+
+	result := make([]*yaml.Node, 0, len(endpoints.Content)+len(wanted))
+	seen := make(map[string]bool)
+	for _, ep := range endpoints.Content {
+		id := gatusEndpointServer(ep)
+		if id == "" {
+			result = append(result, ep)
+			continue
+		}
+
+		server, exists := wanted[id]
+		if !exists || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if err = applyGatusServerEndpoint(ep, server); err != nil {
+			return err
+		}
+		result = append(result, ep)
+	}
+
+	for _, server := range servers {
+		if wanted[server.Identifier] == nil || seen[server.Identifier] {
+			continue
+		}
+		ep := &yaml.Node{}
+		err = ep.Encode(map[string]interface{}{
+			"group":    gatusServerGroup,
+			"interval": "1m",
+			"conditions": []interface{}{
+				"[CONNECTED] == true",
+				"[RESPONSE_TIME] < 5000",
+			},
+			"extra-labels": map[string]interface{}{gatusServerLabel: server.Identifier},
+		})
+		if err != nil {
+			return err
+		}
+		if err = applyGatusServerEndpoint(ep, server); err != nil {
+			return err
+		}
+		result = append(result, ep)
+		logging.Info.Printf("Gatus: Added endpoint for server '%s'", server.Identifier)
+	}
+	endpoints.Content = result
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	_ = encoder.Close()
+
+	if err = os.WriteFile(configPath+".backup", data, 0644); err != nil {
+		logging.Error.Printf("Failed to create backup of Gatus config: %s", err.Error())
+	}
+
+	if err = os.WriteFile(configPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+func gatusEndpointServer(ep *yaml.Node) string {
+	if ep.Kind != yaml.MappingNode {
+		return ""
+	}
+	labels := yamlMappingValue(ep, "extra-labels")
+	if labels == nil || labels.Kind != yaml.MappingNode {
+		return ""
+	}
+	if id := yamlMappingValue(labels, gatusServerLabel); id != nil && id.Kind == yaml.ScalarNode {
+		return id.Value
+	}
+	return ""
+}
+
+// applyGatusServerEndpoint pone en el endpoint el nombre y la dirección actuales del servidor
+func applyGatusServerEndpoint(ep *yaml.Node, server *models.Server) error {
+	host := server.IP
+	if host == "" || host == "0.0.0.0" {
+		host = server.Node.PublicHost
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(int(server.Port)))
+
+	values := map[string]string{"name": fmt.Sprintf("%s (%s)", server.Name, server.Identifier)}
+	switch GatusCheckType(server.Type) {
+	case GatusCheckMinecraft:
+		values["url"] = "tcp://" + address
+		values["body"] = minecraftPingBody
+	case GatusCheckMinecraftBedrock:
+		values["url"] = "udp://" + address
+		values["body"] = bedrockPingBody
+	case GatusCheckSource:
+		values["url"] = "udp://" + address
+		values["body"] = sourceQueryBody
+	default:
+		values["url"] = "tcp://" + address
+	}
+
+	if _, ok := values["body"]; !ok {
+		yamlDeleteMapping(ep, "body")
+	}
+	for _, key := range []string{"name", "url", "body"} {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		node := &yaml.Node{}
+		if err := node.Encode(value); err != nil {
+			return err
+		}
+		if err := yamlSetMapping(ep, key, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// yamlMappingValue busca el valor de una clave en un mapa de YAML
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlSetMapping cambia el valor de una clave conservando sus comentarios, o la añade al final si no está
+func yamlSetMapping(node *yaml.Node, key string, value *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to update YAML: %s is not in a mapping", key)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			old := node.Content[i+1]
+			value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+			node.Content[i+1] = value
+			return nil
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return nil
+}
+
+func yamlDeleteMapping(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// reloadGatusEndpoints vuelve a leer la configuración y reinicia las comprobaciones con los endpoints nuevos
+func reloadGatusEndpoints(configPath string) error {
+	if gatusConfigInstance == nil || !gatusRunning {
+		return nil
+	}
+
+	cfg, err := gatusConfig.LoadConfiguration(configPath)
+	if err != nil {
+		return err
+	}
+	cfg.Web = gatusConfigInstance.Web
+	cfg.UI = gatusConfigInstance.UI
+
+	gatusWatchdog.Shutdown(gatusConfigInstance)
+	cleanupGatusStorage(cfg)
+	gatusWatchdog.Monitor(cfg)
+	gatusConfigInstance = cfg
+	return nil
+}
+
+// GetGatusServerStatus da las últimas comprobaciones que Gatus hizo a un servidor
+func GetGatusServerStatus(server *models.Server, results int) *GatusServerStatus {
+	status := &GatusServerStatus{Enabled: server.Monitored}
+	if !server.Monitored {
+		return status
+	}
+	status.Check = GatusCheckType(server.Type)
+
+	if gatusConfigInstance == nil || !gatusRunning {
+		return status
+	}
+
+	for _, ep := range gatusConfigInstance.Endpoints {
+		if ep.ExtraLabels[gatusServerLabel] != server.Identifier {
+			continue
+		}
+
+		key := ep.Key()
+		if uptime, err := gatusStorage.Get().GetUptimeByKey(key, time.Now().Add(-24*time.Hour), time.Now()); err == nil {
+			status.Uptime24h = &uptime
+		}
+
+		epStatus, err := gatusStorage.Get().GetEndpointStatusByKey(key, gatusPaging.NewEndpointStatusParams().WithResults(1, results))
+		if err != nil {
+			break
+		}
+		for _, v := range epStatus.Results {
+			status.Results = append(status.Results, GatusServerResult{
+				Time:     v.Timestamp,
+				Success:  v.Success,
+				Duration: v.Duration.Milliseconds(),
+				Errors:   v.Errors,
+			})
+		}
+		break
+	}
+	return status
+}
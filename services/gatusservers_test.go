@@ -0,0 +1,85 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	gatusConfig "github.com/TwiN/gatus/v5/config"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSyncServersToGatus(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if !assert.NoError(t, createDefaultGatusConfig(configPath)) {
+		return
+	}
+
+	server := &models.Server{Identifier: "abc123", Name: "Survival", Type: "minecraft-java", IP: "10.0.0.5", Port: 25565, Node: *models.LocalNode}
+	other := &models.Server{Identifier: "def456", Name: "Arena", Type: "generic", Port: 7777, Node: *models.LocalNode}
+	if !assert.NoError(t, syncServersToGatus(configPath, []*models.Server{server, other})) {
+		return
+	}
+
+	cfg, err := gatusConfig.LoadConfiguration(configPath)
+	if !assert.NoError(t, err) || !assert.Len(t, cfg.Endpoints, 3) {
+		return
+	}
+	assert.Equal(t, "Panel Principal", cfg.Endpoints[0].Name)
+	assert.Equal(t, "Survival (abc123)", cfg.Endpoints[1].Name)
+	assert.Equal(t, "tcp://10.0.0.5:25565", cfg.Endpoints[1].URL)
+	assert.Equal(t, minecraftPingBody, cfg.Endpoints[1].Body)
+	assert.Equal(t, "tcp://"+models.LocalNode.PublicHost+":7777", cfg.Endpoints[2].URL)
+
+	t.Run("KeepsComments", func(t *testing.T) {
+		data, _ := os.ReadFile(configPath)
+		assert.Contains(t, string(data), "# Configuración de Gatus para SkyPanel")
+		assert.Contains(t, string(data), "# Endpoints a monitorear")
+	})
+
+	t.Run("KeepsHandEdits", func(t *testing.T) {
+		data, _ := os.ReadFile(configPath)
+		var configMap map[string]interface{}
+		if !assert.NoError(t, yaml.Unmarshal(data, &configMap)) {
+			return
+		}
+		endpoints := configMap["endpoints"].([]interface{})
+		endpoints[1].(map[string]interface{})["interval"] = "5m"
+		configMap["endpoints"] = append(endpoints, map[string]interface{}{
+			"name":       "Mine",
+			"url":        "tcp://example.com:80",
+			"conditions": []interface{}{"[CONNECTED] == true"},
+		})
+		data, _ = yaml.Marshal(configMap)
+		if !assert.NoError(t, os.WriteFile(configPath, data, 0644)) {
+			return
+		}
+
+		server.Name = "Creative"
+		if !assert.NoError(t, syncServersToGatus(configPath, []*models.Server{server})) {
+			return
+		}
+
+		cfg, err := gatusConfig.LoadConfiguration(configPath)
+		if !assert.NoError(t, err) || !assert.Len(t, cfg.Endpoints, 3) {
+			return
+		}
+		var names []string
+		for _, v := range cfg.Endpoints {
+			names = append(names, v.Name)
+		}
+		assert.Equal(t, []string{"Panel Principal", "Creative (abc123)", "Mine"}, names)
+		assert.Equal(t, 5*time.Minute, cfg.Endpoints[1].Interval)
+	})
+}
+
+func TestGatusCheckType(t *testing.T) {
+	assert.Equal(t, GatusCheckMinecraft, GatusCheckType("minecraft"))
+	assert.Equal(t, GatusCheckMinecraft, GatusCheckType("minecraft-java"))
+	assert.Equal(t, GatusCheckMinecraftBedrock, GatusCheckType("minecraft-bedrock"))
+	assert.Equal(t, GatusCheckSource, GatusCheckType("srcds"))
+	assert.Equal(t, GatusCheckTcp, GatusCheckType("generic"))
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/notifications"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Inbox struct {
+	DB *gorm.DB
+}
+
+// Ways a user can get the events they subscribed to
+const (
+	DeliveryInbox = "inbox"
+	DeliveryEmail = "email"
+)
+
+// InboxEvents are the events users can subscribe to, every other event only goes to notification channels
+var InboxEvents = []string{
+	notifications.EventServerCrash,
+	notifications.EventServerHung,
+	notifications.EventBackupFailed,
+	notifications.EventServerOffline,
+	notifications.EventNodeOffline,
+	notifications.EventLoginNewIp,
+}
+
+// inboxRetention is how long inbox entries are kept
+const inboxRetention = 90 * 24 * time.Hour
+
+const preferenceKeyPrefix = "notifications."
+
+var inboxSockets = make(map[uint]*SkyPanel.Tracker)
+var inboxSocketsLock sync.Mutex
+
+// RegisterInboxSocket adds a websocket which gets the user's new notifications as they arrive
+func RegisterInboxSocket(userId uint, socket *SkyPanel.Socket) {
+	inboxSocketsLock.Lock()
+	defer inboxSocketsLock.Unlock()
+
+	tracker, ok := inboxSockets[userId]
+	if !ok {
+		tracker = SkyPanel.CreateTracker()
+		inboxSockets[userId] = tracker
+	}
+	tracker.Register(socket)
+}
+
+func pushToInboxSockets(userId uint, notification *models.UserNotification) {
+	inboxSocketsLock.Lock()
+	tracker, ok := inboxSockets[userId]
+	inboxSocketsLock.Unlock()
+	if !ok {
+		return
+	}
+
+	_ = tracker.WriteMessage(SkyPanel.Transmission{
+		Message: notification,
+		Type:    SkyPanel.MessageTypeNotification,
+	})
+}
+
+// GetPreferences gets how the user gets each event, events the user never changed go to the inbox
+func (is *Inbox) GetPreferences(userId uint) (models.NotificationPreferences, error) {
+	var settings []*models.UserSetting
+	err := is.DB.Where("user_id = ?", userId).Where(clause.Like{Column: clause.Column{Name: "key"}, Value: preferenceKeyPrefix + "%"}).Find(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make(models.NotificationPreferences, len(InboxEvents))
+	for _, v := range InboxEvents {
+		preferences[v] = []string{DeliveryInbox}
+	}
+	for _, v := range settings {
+		event := strings.TrimPrefix(v.Key, preferenceKeyPrefix)
+		if _, ok := preferences[event]; !ok {
+			continue
+		}
+		preferences[event] = make([]string, 0)
+		if v.Value != "" {
+			preferences[event] = strings.Split(v.Value, ",")
+		}
+	}
+	return preferences, nil
+}
+
+// SetPreferences stores how the user gets the given events, an empty list turns the event off
+func (is *Inbox) SetPreferences(userId uint, preferences models.NotificationPreferences) error {
+	for event, methods := range preferences {
+		if !isInboxEvent(event) {
+			return SkyPanel.ErrFieldNotValidOption("event", InboxEvents...)
+		}
+		for _, v := range methods {
+			if v != DeliveryInbox && v != DeliveryEmail {
+				return SkyPanel.ErrFieldNotValidOption("delivery", DeliveryInbox, DeliveryEmail)
+			}
+		}
+	}
+
+	return is.DB.Transaction(func(tx *gorm.DB) error {
+		uss := &UserSettings{DB: tx}
+		for event, methods := range preferences {
+			err := uss.Update(&models.UserSetting{
+				Key:    preferenceKeyPrefix + event,
+				UserID: userId,
+				Value:  strings.Join(methods, ","),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (is *Inbox) Search(userId uint, unreadOnly bool, pageSize, page uint) ([]*models.UserNotification, int64, error) {
+	query := is.DB.Model(&models.UserNotification{}).Where("user_id = ?", userId)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]*models.UserNotification, 0)
+	err := query.Order("id DESC").Offset(int((page - 1) * pageSize)).Limit(int(pageSize)).Find(&results).Error
+	return results, count, err
+}
+
+func (is *Inbox) UnreadCount(userId uint) (count int64, err error) {
+	err = is.DB.Model(&models.UserNotification{}).Where("user_id = ? AND is_read = ?", userId, false).Count(&count).Error
+	return
+}
+
+func (is *Inbox) MarkRead(id, userId uint) error {
+	res := is.DB.Model(&models.UserNotification{}).Where("id = ? AND user_id = ?", id, userId).Update("is_read", true)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (is *Inbox) MarkAllRead(userId uint) error {
+	return is.DB.Model(&models.UserNotification{}).Where("user_id = ? AND is_read = ?", userId, false).Update("is_read", true).Error
+}
+
+func (is *Inbox) Delete(id, userId uint) error {
+	res := is.DB.Where("id = ? AND user_id = ?", id, userId).Delete(&models.UserNotification{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// deliver hands a notification to the user the ways they asked for
+func (is *Inbox) deliver(userId uint, n *notifications.Notification) {
+	if !isInboxEvent(n.Event) {
+		return
+	}
+
+	preferences, err := is.GetPreferences(userId)
+	if err != nil {
+		logging.Error.Printf("Error loading notification preferences of user %d: %s", userId, err)
+		return
+	}
+
+	for _, v := range preferences[n.Event] {
+		switch v {
+		case DeliveryInbox:
+			err = is.store(userId, n)
+		case DeliveryEmail:
+			err = is.email(userId, n)
+		}
+		if err != nil {
+			logging.Error.Printf("Error delivering %s notification to user %d by %s: %s", n.Event, userId, v, err)
+		}
+	}
+}
+
+func (is *Inbox) store(userId uint, n *notifications.Notification) error {
+	entry := &models.UserNotification{
+		UserID:     userId,
+		Event:      n.Event,
+		Severity:   n.Severity,
+		Title:      n.Title,
+		Message:    n.Message,
+		ServerID:   n.ServerId,
+		ServerName: n.ServerName,
+		CreatedAt:  n.Time,
+	}
+	if err := is.DB.Create(entry).Error; err != nil {
+		return err
+	}
+	pushToInboxSockets(userId, entry)
+
+	return is.DB.Where("user_id = ? AND created_at < ?", userId, time.Now().Add(-inboxRetention)).Delete(&models.UserNotification{}).Error
+}
+
+func (is *Inbox) email(userId uint, n *notifications.Notification) error {
+	us := &User{DB: is.DB}
+	user, err := us.GetById(userId)
+	if err != nil {
+		return err
+	}
+	return notifications.Mailer(user.Email, n)
+}
+
+func isInboxEvent(event string) bool {
+	for _, v := range InboxEvents {
+		if v == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"gorm.io/gorm"
+)
+
+type Maintenance struct {
+	DB *gorm.DB
+}
+
+func (ms *Maintenance) Get(id uint) (*models.MaintenanceWindow, error) {
+	window := &models.MaintenanceWindow{}
+	err := ms.DB.First(window, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// List gets the windows that overlap with the given range, newest first
+func (ms *Maintenance) List(from, to time.Time) ([]*models.MaintenanceWindow, error) {
+	windows := make([]*models.MaintenanceWindow, 0)
+	err := ms.DB.Where("start_time < ? AND end_time > ?", to, from).Order("start_time DESC").Find(&windows).Error
+	return windows, err
+}
+
+func (ms *Maintenance) Create(window *models.MaintenanceWindow) error {
+	window.ID = 0
+	return ms.DB.Create(window).Error
+}
+
+func (ms *Maintenance) Update(window *models.MaintenanceWindow) error {
+	existing, err := ms.Get(window.ID)
+	if err != nil {
+		return err
+	}
+	window.CreatedAt = existing.CreatedAt
+	return ms.DB.Save(window).Error
+}
+
+func (ms *Maintenance) Delete(id uint) error {
+	if _, err := ms.Get(id); err != nil {
+		return err
+	}
+	return ms.DB.Delete(&models.MaintenanceWindow{}, id).Error
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/SkyPanel/SkyPanel/v3/config"
 	"github.com/SkyPanel/SkyPanel/v3/logging"
 	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/tunnel"
 	"gorm.io/gorm"
 	"io"
 	"net/http"
@@ -131,9 +132,16 @@ func (ns *Node) Create(node *models.Node) error {
 func (ns *Node) CallNode(node *models.Node, method string, path string, body io.ReadCloser, headers http.Header) (*http.Response, error) {
 	var fullUrl string
 	var err error
+	var session *tunnel.Session
 
 	if node.IsLocal() {
 		fullUrl = "http://localhost" + path
+	} else if node.IsReverse() {
+		var ok bool
+		if session, ok = GetTunnel(node.ID); !ok {
+			return nil, SkyPanel.ErrNodeNotConnected
+		}
+		fullUrl = "http://tunnel" + path
 	} else {
 		fullUrl, err = createNodeURL(node, path)
 		if err != nil {
@@ -179,6 +187,10 @@ func (ns *Node) CallNode(node *models.Node, method string, path string, body io.
 		return w.Result(), err
 	}
 
+	if session != nil {
+		return session.HttpClient().Do(request)
+	}
+
 	response, err := SkyPanel.Http().Do(request)
 	return response, err
 }
@@ -189,17 +201,28 @@ func (ns *Node) OpenSocket(node *models.Node, path string, writer http.ResponseW
 		return err
 	}
 
-	ssl, err := doesDaemonUseSSL(node)
-	if err != nil {
-		return err
-	}
-
+	dialer := websocket.DefaultDialer
 	scheme := "ws"
-	if ssl {
-		scheme = "wss"
-	}
 	addr := fmt.Sprintf("%s:%d", node.PrivateHost, node.PrivatePort)
 
+	if node.IsReverse() {
+		session, ok := GetTunnel(node.ID)
+		if !ok {
+			_ = conn.Close()
+			return SkyPanel.ErrNodeNotConnected
+		}
+		dialer = &websocket.Dialer{NetDialContext: session.DialContext, HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout}
+		addr = "tunnel"
+	} else {
+		ssl, err := doesDaemonUseSSL(node)
+		if err != nil {
+			return err
+		}
+		if ssl {
+			scheme = "wss"
+		}
+	}
+
 	u := fmt.Sprintf("%s://%s%s", scheme, addr, path)
 	logging.Debug.Printf("Proxying connection to %s", u)
 
@@ -215,7 +238,7 @@ func (ns *Node) OpenSocket(node *models.Node, path string, writer http.ResponseW
 	header := http.Header{}
 	header.Set("Authorization", "Bearer "+token)
 
-	c, _, err := websocket.DefaultDialer.Dial(u, header)
+	c, _, err := dialer.Dial(u, header)
 	if err != nil {
 		//close the connection, because it failed
 		_ = conn.Close()
@@ -242,7 +265,7 @@ func (ns *Node) OpenSocket(node *models.Node, path string, writer http.ResponseW
 }
 
 func doesDaemonUseSSL(node *models.Node) (bool, error) {
-	if node.IsLocal() {
+	if node.IsLocal() || node.IsReverse() {
 		return false, nil
 	}
 
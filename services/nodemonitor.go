@@ -0,0 +1,171 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/database"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/notifications"
+	"github.com/SkyPanel/SkyPanel/v3/utils"
+)
+
+// nodeMonitorInterval is how often the panel checks that its nodes answer
+const nodeMonitorInterval = time.Minute
+
+// nodeCheckTimeout is how long a node has to answer before it counts as offline
+const nodeCheckTimeout = 10 * time.Second
+
+var nodeMonitorStop chan bool
+var nodeOnline = make(map[uint]bool)
+var nodeMonitorLock sync.Mutex
+
+// StartNodeMonitor checks the nodes periodically and sends node.offline when one stops answering
+func StartNodeMonitor() {
+	nodeMonitorLock.Lock()
+	defer nodeMonitorLock.Unlock()
+
+	if nodeMonitorStop != nil {
+		return
+	}
+	nodeMonitorStop = make(chan bool)
+
+	go func(stop chan bool) {
+		ticker := time.NewTicker(nodeMonitorInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				checkNodes()
+			}
+		}
+	}(nodeMonitorStop)
+}
+
+func StopNodeMonitor() {
+	nodeMonitorLock.Lock()
+	defer nodeMonitorLock.Unlock()
+
+	if nodeMonitorStop != nil {
+		close(nodeMonitorStop)
+		nodeMonitorStop = nil
+	}
+}
+
+func checkNodes() {
+	db, err := database.GetConnection()
+	if err != nil {
+		return
+	}
+
+	ns := &Node{DB: db}
+	nodes, err := ns.GetAll()
+	if err != nil {
+		logging.Error.Printf("Error loading nodes to check: %s", err)
+		return
+	}
+
+	seen := make(map[uint]bool, len(nodes))
+	for _, node := range nodes {
+		if node.IsLocal() {
+			continue
+		}
+		seen[node.ID] = true
+
+		online := isNodeOnline(ns, node)
+
+		nodeMonitorLock.Lock()
+		wasOnline, known := nodeOnline[node.ID]
+		nodeOnline[node.ID] = online
+		nodeMonitorLock.Unlock()
+
+		go trackNodeUptime(ns, node, online)
+
+		if known && wasOnline && !online {
+			logging.Info.Printf("Node %d (%s) is not answering", node.ID, node.Name)
+			Notify(&notifications.Notification{
+				Event:    notifications.EventNodeOffline,
+				Severity: notifications.SeverityCritical,
+				Title:    "🔴 Nodo Desconectado",
+				Message:  fmt.Sprintf("El nodo %s no responde.", node.Name),
+				Fields:   []notifications.Field{{Name: "Host", Value: node.PublicHost}},
+			})
+		}
+	}
+
+	nodeMonitorLock.Lock()
+	for id := range nodeOnline {
+		if !seen[id] {
+			delete(nodeOnline, id)
+		}
+	}
+	nodeMonitorLock.Unlock()
+}
+
+func isNodeOnline(ns *Node, node *models.Node) bool {
+	result := make(chan bool, 1)
+	go func() {
+		response, err := ns.CallNode(node, http.MethodGet, "/daemon", nil, nil)
+		if err != nil {
+			result <- false
+			return
+		}
+		_ = response.Body.Close()
+		result <- response.StatusCode == http.StatusOK
+	}()
+
+	select {
+	case online := <-result:
+		return online
+	case <-time.After(nodeCheckTimeout):
+		return false
+	}
+}
+
+// trackNodeUptime records the uptime of the servers of a remote node, which cannot write to the panel database.
+// While the node does not answer, its servers count as down because the node is offline.
+func trackNodeUptime(ns *Node, node *models.Node, online bool) {
+	var servers []*models.Server
+	if err := ns.DB.Where("node_id = ?", node.ID).Find(&servers).Error; err != nil {
+		logging.Error.Printf("Error loading servers of node %d: %s", node.ID, err)
+		return
+	}
+
+	us := &Uptime{DB: ns.DB}
+	for _, server := range servers {
+		running, cause := false, models.DowntimeNodeOffline
+		if online {
+			status, err := getRemoteStatus(ns, node, server.Identifier)
+			if err != nil {
+				continue
+			}
+			running, cause = status.Running, status.DownCause
+		}
+
+		if err := us.TrackStatus(server.Identifier, running, cause); err != nil {
+			logging.Error.Printf("[%s] Error tracking uptime: %s", server.Identifier, err)
+		}
+	}
+}
+
+func getRemoteStatus(ns *Node, node *models.Node, serverId string) (*SkyPanel.ServerRunning, error) {
+	response, err := ns.CallNode(node, http.MethodGet, "/daemon/server/"+serverId+"/status", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.CloseResponse(response)
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("node answered with status %d", response.StatusCode)
+	}
+
+	status := &SkyPanel.ServerRunning{}
+	err = json.NewDecoder(response.Body).Decode(status)
+	return status, err
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/database"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/notifications"
+	"github.com/SkyPanel/SkyPanel/v3/scopes"
+	"gorm.io/gorm"
+)
+
+type NotificationChannel struct {
+	DB *gorm.DB
+}
+
+// notificationChannelCacheTime is how long the enabled channels are kept in memory before they are read again
+const notificationChannelCacheTime = time.Minute
+
+var notificationChannelCache []*models.NotificationChannel
+var notificationChannelCacheTimestamp time.Time
+var notificationChannelCacheLock sync.Mutex
+
+func init() {
+	notifications.Mailer = func(to string, n *notifications.Notification) error {
+		if globalEmailService == nil {
+			return SkyPanel.ErrEmailNotConfigured
+		}
+		fields := make(map[string]string, len(n.Fields))
+		for _, v := range n.Fields {
+			fields[v.Name] = v.Value
+		}
+		return globalEmailService.SendEmail(to, "notification", map[string]interface{}{
+			"TITLE":   n.Title,
+			"MESSAGE": n.Message,
+			"SERVER":  n.ServerName,
+			"FIELDS":  fields,
+		}, false)
+	}
+}
+
+func (ns *NotificationChannel) Get(id uint) (*models.NotificationChannel, error) {
+	channel := &models.NotificationChannel{}
+	err := ns.DB.First(channel, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// GetForUser gets a channel only if it belongs to the user
+func (ns *NotificationChannel) GetForUser(id, userId uint) (*models.NotificationChannel, error) {
+	channel := &models.NotificationChannel{}
+	err := ns.DB.Where("user_id = ?", userId).First(channel, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+func (ns *NotificationChannel) List(userId uint) ([]*models.NotificationChannel, error) {
+	channels := make([]*models.NotificationChannel, 0)
+	err := ns.DB.Where("user_id = ?", userId).Order("id ASC").Find(&channels).Error
+	return channels, err
+}
+
+func (ns *NotificationChannel) Create(channel *models.NotificationChannel) error {
+	channel.ID = 0
+	err := ns.DB.Create(channel).Error
+	invalidateNotificationChannels()
+	return err
+}
+
+func (ns *NotificationChannel) Update(channel *models.NotificationChannel) error {
+	existing, err := ns.GetForUser(channel.ID, channel.UserID)
+	if err != nil {
+		return err
+	}
+	channel.CreatedAt = existing.CreatedAt
+	err = ns.DB.Save(channel).Error
+	invalidateNotificationChannels()
+	return err
+}
+
+func (ns *NotificationChannel) Delete(id, userId uint) error {
+	if _, err := ns.GetForUser(id, userId); err != nil {
+		return err
+	}
+	err := ns.DB.Delete(&models.NotificationChannel{}, id).Error
+	invalidateNotificationChannels()
+	return err
+}
+
+func (ns *NotificationChannel) getEnabled() ([]*models.NotificationChannel, error) {
+	notificationChannelCacheLock.Lock()
+	defer notificationChannelCacheLock.Unlock()
+
+	if notificationChannelCache != nil && time.Since(notificationChannelCacheTimestamp) < notificationChannelCacheTime {
+		return notificationChannelCache, nil
+	}
+
+	channels := make([]*models.NotificationChannel, 0)
+	err := ns.DB.Where("enabled = ?", true).Find(&channels).Error
+	if err != nil {
+		return nil, err
+	}
+	notificationChannelCache = channels
+	notificationChannelCacheTimestamp = time.Now()
+	return channels, nil
+}
+
+func invalidateNotificationChannels() {
+	notificationChannelCacheLock.Lock()
+	defer notificationChannelCacheLock.Unlock()
+	notificationChannelCache = nil
+}
+
+// Notify sends a notification in the background to the Discord webhook from the settings,
+// and to every channel subscribed to its event whose owner can see the server
+func Notify(n *notifications.Notification) {
+	if n.Time.IsZero() {
+		n.Time = time.Now()
+	}
+	go dispatch(n)
+}
+
+func dispatch(n *notifications.Notification) {
+	if url := config.DiscordWebhook.Value(); url != "" && n.UserId == 0 {
+		legacy := &notifications.Discord{Url: url}
+		if err := legacy.Send(n); err != nil {
+			logging.Error.Printf("Error sending notification to Discord: %s", err)
+		}
+	}
+
+	db, err := database.GetConnection()
+	if err != nil {
+		return
+	}
+
+	recipients, err := notificationRecipients(db, n)
+	if err != nil {
+		logging.Error.Printf("Error loading notification recipients: %s", err)
+		return
+	}
+
+	is := &Inbox{DB: db}
+	for _, v := range recipients {
+		is.deliver(v, n)
+	}
+
+	ns := &NotificationChannel{DB: db}
+	channels, err := ns.getEnabled()
+	if err != nil {
+		logging.Error.Printf("Error loading notification channels: %s", err)
+		return
+	}
+
+	for _, v := range channels {
+		if !notifications.Subscribed(v.Events, n.Event) || !containsUser(recipients, v.UserID) {
+			continue
+		}
+		if v.ServerID != nil && *v.ServerID != n.ServerId {
+			continue
+		}
+
+		if err = SendNotification(v, n); err != nil {
+			logging.Error.Printf("Error sending notification to channel %d (%s): %s", v.ID, v.Name, err)
+		}
+	}
+}
+
+// notificationRecipients lists the users allowed to see a notification: the user it is about,
+// the users who can view its server, or for events outside a server, the users who can view nodes
+func notificationRecipients(db *gorm.DB, n *notifications.Notification) ([]uint, error) {
+	if n.UserId != 0 {
+		return []uint{n.UserId}, nil
+	}
+
+	ps := &Permission{DB: db}
+	if n.ServerId != "" {
+		return ps.GetUsersWithPermission(n.ServerId, scopes.ScopeServerView)
+	}
+	return ps.GetUsersWithPermission("", scopes.ScopeNodesView)
+}
+
+func containsUser(users []uint, userId uint) bool {
+	for _, v := range users {
+		if v == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// SendNotification sends to one channel right away
+func SendNotification(channel *models.NotificationChannel, n *notifications.Notification) error {
+	sender, err := channel.Channel()
+	if err != nil {
+		return err
+	}
+	return sender.Send(n)
+}
@@ -95,6 +95,31 @@ func (ps *Permission) HasPermission(userId uint, serverId string, permission *sc
 	return false, nil
 }
 
+// GetUsersWithPermission lists the users which have the scope, globally or on the given server
+func (ps *Permission) GetUsersWithPermission(serverId string, permission *scopes.Scope) ([]uint, error) {
+	query := ps.DB.Where("user_id IS NOT NULL")
+	if serverId != "" {
+		query = query.Where("server_identifier = ? OR server_identifier IS NULL", serverId)
+	} else {
+		query = query.Where("server_identifier IS NULL")
+	}
+
+	var perms []*models.Permissions
+	if err := query.Find(&perms).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint]bool)
+	users := make([]uint, 0)
+	for _, perm := range perms {
+		if !found[*perm.UserId] && scopes.ContainsScope(perm.Scopes, permission) {
+			found[*perm.UserId] = true
+			users = append(users, *perm.UserId)
+		}
+	}
+	return users, nil
+}
+
 func (ps *Permission) GetForClient(id uint) ([]*models.Permissions, error) {
 	var allPerms []*models.Permissions
 	permissions := &models.Permissions{
@@ -101,6 +101,18 @@ func (ss *Server) Delete(id string) error {
 		return err
 	}
 
+	err = ss.DB.Delete(models.AlertRule{}, "server_id = ?", id).Error
+	if err != nil {
+		return err
+	}
+	invalidateAlertRules()
+
+	err = ss.DB.Delete(models.NotificationChannel{}, "server_id = ?", id).Error
+	if err != nil {
+		return err
+	}
+	invalidateNotificationChannels()
+
 	err = ss.DB.Delete(model).Error
 	if err != nil {
 		return err
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/tunnel"
+)
+
+var tunnels = make(map[uint]*tunnel.Session)
+var tunnelLocker sync.RWMutex
+
+// RegisterTunnel stores the session a reverse-connected node dialed in with, replacing any older one
+func RegisterTunnel(nodeId uint, session *tunnel.Session) {
+	tunnelLocker.Lock()
+	existing := tunnels[nodeId]
+	tunnels[nodeId] = session
+	tunnelLocker.Unlock()
+
+	if existing != nil && existing != session {
+		_ = existing.Close()
+	}
+	logging.Info.Printf("Node %d connected through reverse tunnel", nodeId)
+
+	go func() {
+		<-session.Done()
+		UnregisterTunnel(nodeId, session)
+	}()
+}
+
+// UnregisterTunnel removes the session if it is still the active one for the node
+func UnregisterTunnel(nodeId uint, session *tunnel.Session) {
+	tunnelLocker.Lock()
+	defer tunnelLocker.Unlock()
+
+	if tunnels[nodeId] == session {
+		delete(tunnels, nodeId)
+		logging.Info.Printf("Node %d reverse tunnel closed", nodeId)
+	}
+}
+
+// GetTunnel gets the active session for a node, if the node is connected
+func GetTunnel(nodeId uint) (*tunnel.Session, bool) {
+	tunnelLocker.RLock()
+	defer tunnelLocker.RUnlock()
+
+	session, ok := tunnels[nodeId]
+	if !ok || session.IsClosed() {
+		return nil, false
+	}
+	return session, true
+}
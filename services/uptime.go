@@ -11,8 +11,13 @@ type Uptime struct {
 	DB *gorm.DB
 }
 
-// TrackStatus registra o actualiza el estado de uptime/downtime de un servidor
-func (us *Uptime) TrackStatus(serverID string, isRunning bool) error {
+// TrackStatus registra o actualiza el estado de uptime/downtime de un servidor.
+// cause es el motivo por el que está parado, si cambia se abre un registro nuevo.
+func (us *Uptime) TrackStatus(serverID string, isRunning bool, cause string) error {
+	if isRunning {
+		cause = ""
+	}
+
 	// Buscar si hay un registro activo (sin EndTime) para este servidor
 	var currentStatus *models.UptimeStatus
 	err := us.DB.Where("server_id = ? AND end_time IS NULL", serverID).Order("start_time DESC").First(&currentStatus).Error
@@ -25,14 +30,22 @@ func (us *Uptime) TrackStatus(serverID string, isRunning bool) error {
 			ServerID:  serverID,
 			IsRunning: isRunning,
 			StartTime: now,
+			Cause:     cause,
 		}
 		return us.DB.Create(newStatus).Error
 	} else if err != nil {
 		return err
 	}
 
-	// Si el estado cambió, cerrar el registro anterior y crear uno nuevo
-	if currentStatus.IsRunning != isRunning {
+	// El motivo puede llegar un poco después de que se pare, se completa el registro actual
+	if !isRunning && !currentStatus.IsRunning && currentStatus.Cause == "" && cause != "" {
+		currentStatus.Cause = cause
+		currentStatus.UpdatedAt = now
+		return us.DB.Save(currentStatus).Error
+	}
+
+	// Si el estado o el motivo cambió, cerrar el registro anterior y crear uno nuevo
+	if currentStatus.IsRunning != isRunning || (cause != "" && currentStatus.Cause != cause) {
 		// Calcular duración
 		duration := int64(now.Sub(currentStatus.StartTime).Seconds())
 		currentStatus.Duration = duration
@@ -48,6 +61,7 @@ func (us *Uptime) TrackStatus(serverID string, isRunning bool) error {
 			ServerID:  serverID,
 			IsRunning: isRunning,
 			StartTime: now,
+			Cause:     cause,
 		}
 		return us.DB.Create(newStatus).Error
 	}
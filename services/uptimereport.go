@@ -0,0 +1,306 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ReportPeriodWeek  = "week"
+	ReportPeriodMonth = "month"
+)
+
+// ReportPeriod da el inicio y el fin de la semana (desde el lunes) o del mes que contiene date
+func ReportPeriod(period string, date time.Time) (from, to time.Time, err error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	switch period {
+	case ReportPeriodWeek:
+		from = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		to = from.AddDate(0, 0, 7)
+	case ReportPeriodMonth:
+		from = day.AddDate(0, 0, 1-day.Day())
+		to = from.AddDate(0, 1, 0)
+	default:
+		err = SkyPanel.ErrInvalidReportPeriod
+	}
+	return
+}
+
+// GetReport calcula el informe de SLA de un servidor entre from y to.
+// El tiempo dentro de una ventana de mantenimiento no cuenta ni como activo ni como caída.
+func (us *Uptime) GetReport(server *models.Server, from, to time.Time) (*models.UptimeReport, error) {
+	ms := &Maintenance{DB: us.DB}
+	windows, err := ms.List(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return us.buildReport(server, from, to, windows)
+}
+
+// GetSummary calcula el informe de todos los servidores, o solo de los de un nodo si nodeId no es nil
+func (us *Uptime) GetSummary(nodeId *uint, period string, from, to time.Time) (*models.UptimeSummary, error) {
+	var servers []*models.Server
+	query := us.DB.Preload(clause.Associations).Order("servers.name")
+	if nodeId != nil {
+		if *nodeId == models.LocalNode.ID {
+			query = query.Where("node_id IS NULL OR node_id = ?", models.LocalNode.ID)
+		} else {
+			query = query.Where("node_id = ?", *nodeId)
+		}
+	}
+	if err := query.Find(&servers).Error; err != nil {
+		return nil, err
+	}
+
+	ms := &Maintenance{DB: us.DB}
+	windows, err := ms.List(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.UptimeSummary{
+		From:    from,
+		To:      to,
+		Period:  period,
+		Servers: make([]*models.UptimeReport, 0, len(servers)),
+	}
+	for _, server := range servers {
+		report, err := us.buildReport(server, from, to, windows)
+		if err != nil {
+			return nil, err
+		}
+		summary.Servers = append(summary.Servers, report)
+		summary.UptimeSeconds += report.UptimeSeconds
+		summary.DowntimeSeconds += report.DowntimeSeconds
+		summary.MaintenanceSeconds += report.MaintenanceSeconds
+	}
+	summary.UptimePercent = uptimePercent(summary.UptimeSeconds, summary.DowntimeSeconds)
+	return summary, nil
+}
+
+func (us *Uptime) buildReport(server *models.Server, from, to time.Time, windows []*models.MaintenanceWindow) (*models.UptimeReport, error) {
+	var records []*models.UptimeStatus
+	err := us.DB.Where("server_id = ? AND start_time < ? AND (end_time IS NULL OR end_time > ?)", server.Identifier, to, from).
+		Order("start_time ASC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	nodeId := models.LocalNode.ID
+	if server.RawNodeID != nil {
+		nodeId = *server.RawNodeID
+	}
+	var applies []*models.MaintenanceWindow
+	for _, v := range windows {
+		if v.AppliesTo(server.Identifier, nodeId) {
+			applies = append(applies, v)
+		}
+	}
+
+	nodeName := server.Node.Name
+	if nodeName == "" || server.Node.IsLocal() {
+		nodeName = models.LocalNode.Name
+	}
+
+	report := computeReport(records, applies, from, to, time.Now())
+	report.ServerId = server.Identifier
+	report.ServerName = server.Name
+	report.NodeName = nodeName
+	return report, nil
+}
+
+// computeReport reparte los registros entre from y to (o now si es antes) en activo, caída y mantenimiento
+func computeReport(records []*models.UptimeStatus, windows []*models.MaintenanceWindow, from, to, now time.Time) *models.UptimeReport {
+	report := &models.UptimeReport{
+		From:      from,
+		To:        to,
+		Incidents: make([]models.UptimeIncident, 0),
+	}
+
+	limit := to
+	if now.Before(limit) {
+		limit = now
+	}
+	maintenance := mergeWindows(windows, from, limit)
+
+	for _, record := range records {
+		start, end := record.StartTime, limit
+		if record.EndTime != nil && record.EndTime.Before(end) {
+			end = *record.EndTime
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		cause := record.Cause
+		if cause == "" {
+			cause = models.DowntimeUnknown
+		}
+
+		for _, piece := range splitByWindows(start, end, maintenance) {
+			seconds := int64(piece.end.Sub(piece.start).Seconds())
+			switch {
+			case piece.maintenance:
+				report.MaintenanceSeconds += seconds
+				if !record.IsRunning {
+					addIncident(report, piece.start, piece.end, models.DowntimeMaintenance)
+				}
+			case record.IsRunning:
+				report.UptimeSeconds += seconds
+			default:
+				report.DowntimeSeconds += seconds
+				addIncident(report, piece.start, piece.end, cause)
+			}
+		}
+	}
+
+	report.UptimePercent = uptimePercent(report.UptimeSeconds, report.DowntimeSeconds)
+	return report
+}
+
+// addIncident añade una caída al informe, juntándola con la anterior si siguen una a la otra por el mismo motivo
+func addIncident(report *models.UptimeReport, start, end time.Time, cause string) {
+	if last := len(report.Incidents) - 1; last >= 0 {
+		prev := &report.Incidents[last]
+		if prev.Cause == cause && prev.End.Equal(start) {
+			prev.End = end
+			prev.Duration = int64(end.Sub(prev.Start).Seconds())
+			return
+		}
+	}
+	report.Incidents = append(report.Incidents, models.UptimeIncident{
+		Start:    start,
+		End:      end,
+		Duration: int64(end.Sub(start).Seconds()),
+		Cause:    cause,
+	})
+}
+
+type reportPiece struct {
+	start, end  time.Time
+	maintenance bool
+}
+
+type timeRange struct {
+	start, end time.Time
+}
+
+// mergeWindows recorta las ventanas al rango y junta las que se solapan
+func mergeWindows(windows []*models.MaintenanceWindow, from, to time.Time) []timeRange {
+	var result []timeRange
+	for _, v := range windows {
+		start, end := v.StartTime, v.EndTime
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			result = append(result, timeRange{start: start, end: end})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].start.Before(result[j].start)
+	})
+
+	merged := make([]timeRange, 0, len(result))
+	for _, v := range result {
+		if last := len(merged) - 1; last >= 0 && !v.start.After(merged[last].end) {
+			if v.end.After(merged[last].end) {
+				merged[last].end = v.end
+			}
+			continue
+		}
+		merged = append(merged, v)
+	}
+	return merged
+}
+
+// splitByWindows parte [start, end) en trozos dentro y fuera de mantenimiento
+func splitByWindows(start, end time.Time, windows []timeRange) []reportPiece {
+	var pieces []reportPiece
+	for _, w := range windows {
+		if !w.end.After(start) {
+			continue
+		}
+		if !w.start.Before(end) {
+			break
+		}
+		if w.start.After(start) {
+			pieces = append(pieces, reportPiece{start: start, end: w.start})
+			start = w.start
+		}
+		pieceEnd := w.end
+		if pieceEnd.After(end) {
+			pieceEnd = end
+		}
+		pieces = append(pieces, reportPiece{start: start, end: pieceEnd, maintenance: true})
+		start = pieceEnd
+	}
+	if end.After(start) {
+		pieces = append(pieces, reportPiece{start: start, end: end})
+	}
+	return pieces
+}
+
+func uptimePercent(uptime, downtime int64) float64 {
+	if uptime+downtime == 0 {
+		return 100.0
+	}
+	return float64(uptime) / float64(uptime+downtime) * 100.0
+}
+
+// WriteUptimeCsv escribe una fila por incidencia, y una fila sin incidencia para los servidores que no tuvieron ninguna
+func WriteUptimeCsv(w io.Writer, reports []*models.UptimeReport) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"serverId", "serverName", "node", "from", "to", "uptimePercent", "uptimeSeconds", "downtimeSeconds", "maintenanceSeconds", "incidentStart", "incidentEnd", "incidentDuration", "incidentCause"})
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		row := []string{
+			report.ServerId,
+			report.ServerName,
+			report.NodeName,
+			report.From.Format(time.RFC3339),
+			report.To.Format(time.RFC3339),
+			fmt.Sprintf("%.3f", report.UptimePercent),
+			strconv.FormatInt(report.UptimeSeconds, 10),
+			strconv.FormatInt(report.DowntimeSeconds, 10),
+			strconv.FormatInt(report.MaintenanceSeconds, 10),
+		}
+		if len(report.Incidents) == 0 {
+			if err = writer.Write(append(row, "", "", "", "")); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, incident := range report.Incidents {
+			line := append(append([]string{}, row...),
+				incident.Start.Format(time.RFC3339),
+				incident.End.Format(time.RFC3339),
+				strconv.FormatInt(incident.Duration, 10),
+				incident.Cause,
+			)
+			if err = writer.Write(line); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportPeriod(t *testing.T) {
+	date := time.Date(2024, time.March, 14, 15, 30, 0, 0, time.UTC) //thursday
+
+	from, to, err := ReportPeriod(ReportPeriodWeek, date)
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC), from)
+		assert.Equal(t, time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC), to)
+	}
+
+	from, to, err = ReportPeriod(ReportPeriodMonth, date)
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), from)
+		assert.Equal(t, time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), to)
+	}
+
+	_, _, err = ReportPeriod("year", date)
+	assert.Error(t, err)
+}
+
+func TestComputeReport(t *testing.T) {
+	from := time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+	at := func(hours int) *time.Time {
+		v := from.Add(time.Duration(hours) * time.Hour)
+		return &v
+	}
+
+	records := []*models.UptimeStatus{
+		{IsRunning: true, StartTime: from.Add(-time.Hour), EndTime: at(10)},
+		{IsRunning: false, StartTime: *at(10), EndTime: at(12), Cause: models.DowntimeCrash},
+		{IsRunning: true, StartTime: *at(12), EndTime: at(20)},
+		{IsRunning: false, StartTime: *at(20), EndTime: at(24), Cause: models.DowntimeStopped},
+		{IsRunning: true, StartTime: *at(24)},
+	}
+	windows := []*models.MaintenanceWindow{
+		{StartTime: *at(22), EndTime: *at(26)},
+	}
+
+	report := computeReport(records, windows, from, to, to.Add(time.Hour))
+
+	assert.Equal(t, int64(4*3600), report.MaintenanceSeconds)
+	assert.Equal(t, int64(4*3600), report.DowntimeSeconds)
+	assert.Equal(t, int64((7*24-8)*3600), report.UptimeSeconds)
+	if assert.Len(t, report.Incidents, 3) {
+		assert.Equal(t, models.DowntimeCrash, report.Incidents[0].Cause)
+		assert.Equal(t, int64(2*3600), report.Incidents[0].Duration)
+		assert.Equal(t, models.DowntimeStopped, report.Incidents[1].Cause)
+		assert.Equal(t, int64(2*3600), report.Incidents[1].Duration)
+		assert.Equal(t, models.DowntimeMaintenance, report.Incidents[2].Cause)
+		assert.Equal(t, *at(24), report.Incidents[2].End)
+	}
+	assert.InDelta(t, 160.0/164.0*100, report.UptimePercent, 0.01)
+}
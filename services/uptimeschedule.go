@@ -0,0 +1,112 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/database"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+)
+
+// uptimeReportInterval is how often the panel checks whether the last period was already reported
+const uptimeReportInterval = time.Hour
+
+var uptimeReportStop chan bool
+var uptimeReportLock sync.Mutex
+
+// StartUptimeReports emails the SLA report of the last week or month once it is over, as set in panel.uptime.report
+func StartUptimeReports() {
+	uptimeReportLock.Lock()
+	defer uptimeReportLock.Unlock()
+
+	if uptimeReportStop != nil {
+		return
+	}
+	uptimeReportStop = make(chan bool)
+
+	go func(stop chan bool) {
+		ticker := time.NewTicker(uptimeReportInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				sendScheduledUptimeReport(time.Now())
+			}
+		}
+	}(uptimeReportStop)
+}
+
+func StopUptimeReports() {
+	uptimeReportLock.Lock()
+	defer uptimeReportLock.Unlock()
+
+	if uptimeReportStop != nil {
+		close(uptimeReportStop)
+		uptimeReportStop = nil
+	}
+}
+
+// scheduledReportPeriod gives the last complete period before now, and a key to remember it was sent
+func scheduledReportPeriod(schedule string, now time.Time) (period string, from, to time.Time, key string, ok bool) {
+	switch schedule {
+	case "weekly":
+		period = ReportPeriodWeek
+	case "monthly":
+		period = ReportPeriodMonth
+	default:
+		return
+	}
+
+	current, _, _ := ReportPeriod(period, now)
+	from, to, _ = ReportPeriod(period, current.AddDate(0, 0, -1))
+	return period, from, to, period + ":" + from.Format(time.DateOnly), true
+}
+
+func sendScheduledUptimeReport(now time.Time) {
+	period, from, to, key, ok := scheduledReportPeriod(config.UptimeReportSchedule.Value(), now)
+	if !ok || config.UptimeReportLastSent.Value() == key {
+		return
+	}
+
+	var recipients []string
+	for _, v := range strings.Split(config.UptimeReportEmails.Value(), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			recipients = append(recipients, v)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	db, err := database.GetConnection()
+	if err != nil {
+		return
+	}
+
+	us := &Uptime{DB: db}
+	summary, err := us.GetSummary(nil, period, from, to)
+	if err != nil {
+		logging.Error.Printf("Error generating uptime report: %s", err)
+		return
+	}
+
+	data := map[string]interface{}{
+		"PERIOD":  period,
+		"FROM":    from.Format(time.DateOnly),
+		"TO":      to.AddDate(0, 0, -1).Format(time.DateOnly),
+		"SUMMARY": summary,
+	}
+	for _, v := range recipients {
+		if err = GetEmailService().SendEmail(v, "uptimeReport", data, false); err != nil {
+			logging.Error.Printf("Error sending uptime report to %s: %s", v, err)
+		}
+	}
+
+	if err = config.UptimeReportLastSent.Set(key, true); err != nil {
+		logging.Error.Printf("Error saving uptime report state: %s", err)
+	}
+}
@@ -23,6 +23,9 @@ type User struct {
 	DB *gorm.DB
 }
 
+const knownLoginIpsKey = "knownLoginIps"
+const maxKnownLoginIps = 20
+
 func (us *User) Get(username string) (*models.User, error) {
 	model := &models.User{
 		Username: username,
@@ -137,11 +140,47 @@ func (us *User) Delete(model *models.User) (err error) {
 		tx.Delete(models.Permissions{}, "user_id = ?", model.ID)
 		tx.Delete(models.Client{}, "user_id = ?", model.ID)
 		tx.Delete(models.Session{}, "user_id = ?", model.ID)
+		tx.Delete(models.NotificationChannel{}, "user_id = ?", model.ID)
+		tx.Delete(models.UserNotification{}, "user_id = ?", model.ID)
+		tx.Delete(models.UserSetting{}, "user_id = ?", model.ID)
 		tx.Delete(models.User{}, "id = ?", model.ID)
+		invalidateNotificationChannels()
 		return nil
 	})
 }
 
+// RememberLoginIp records the address a user logged in from, and tells if the address is new for the user.
+// The first address a user logs in from is not reported as new.
+func (us *User) RememberLoginIp(userId uint, ip string) (bool, error) {
+	setting := &models.UserSetting{Key: knownLoginIpsKey, UserID: userId}
+	err := us.DB.Where(setting).First(setting).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+
+	known := make([]string, 0)
+	if setting.Value != "" {
+		known = strings.Split(setting.Value, ",")
+	}
+	for _, v := range known {
+		if v == ip {
+			return false, nil
+		}
+	}
+
+	known = append(known, ip)
+	if len(known) > maxKnownLoginIps {
+		known = known[len(known)-maxKnownLoginIps:]
+	}
+	setting.Value = strings.Join(known, ",")
+
+	uss := &UserSettings{DB: us.DB}
+	if err = uss.Update(setting); err != nil {
+		return false, err
+	}
+	return len(known) > 1, nil
+}
+
 func (us *User) Create(user *models.User) error {
 	return us.DB.Create(user).Error
 }
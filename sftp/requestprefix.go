@@ -14,12 +14,13 @@ import (
 
 type requestPrefix struct {
 	fs         files.FileServer
+	quota      *files.Quota
 	remoteAddr net.Addr
 	serverId   string
 }
 
-func CreateRequestPrefix(remoteAddr net.Addr, serverId string, fs files.FileServer) sftp.Handlers {
-	h := requestPrefix{fs: fs, serverId: serverId, remoteAddr: remoteAddr}
+func CreateRequestPrefix(remoteAddr net.Addr, serverId string, fs files.FileServer, quota *files.Quota) sftp.Handlers {
+	h := requestPrefix{fs: fs, quota: quota, serverId: serverId, remoteAddr: remoteAddr}
 
 	return sftp.Handlers{FileCmd: h, FileGet: h, FileList: h, FilePut: h}
 }
@@ -34,8 +35,23 @@ func (rp requestPrefix) Fileread(request *sftp.Request) (io.ReaderAt, error) {
 func (rp requestPrefix) Filewrite(request *sftp.Request) (io.WriterAt, error) {
 	rp.log(request)
 
+	if rp.quota == nil {
+		file, err := rp.getFile(request.Filepath, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0644)
+		return file, err
+	}
+
+	//the file is truncated, so what it held no longer counts towards the quota
+	var existingSize int64
+	if fi, err := rp.fs.Stat(request.Filepath); err == nil && !fi.IsDir() {
+		existingSize = fi.Size()
+	}
+
 	file, err := rp.getFile(request.Filepath, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0644)
-	return file, err
+	if err != nil {
+		return nil, err
+	}
+	rp.quota.Add(-existingSize)
+	return rp.quota.WriterAt(file), nil
 }
 
 func (rp requestPrefix) Filecmd(request *sftp.Request) error {
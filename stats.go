@@ -0,0 +1,43 @@
+package SkyPanel
+
+import (
+	"sync"
+	"time"
+)
+
+// RateCounter turns counters which only go up, like bytes sent, into per second rates
+type RateCounter struct {
+	locker   sync.Mutex
+	lastTime time.Time
+	last     []uint64
+}
+
+// Rates records the counters and returns how fast each one grew since the previous call.
+// The first call, or a call after the counters went down because the process restarted, gives 0.
+func (r *RateCounter) Rates(counters ...uint64) []float64 {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+
+	now := time.Now()
+	rates := make([]float64, len(counters))
+
+	elapsed := now.Sub(r.lastTime).Seconds()
+	if len(r.last) == len(counters) && elapsed > 0 {
+		for i, v := range counters {
+			if v >= r.last[i] {
+				rates[i] = float64(v-r.last[i]) / elapsed
+			}
+		}
+	}
+
+	r.lastTime = now
+	r.last = counters
+	return rates
+}
+
+// Reset forgets the previous counters, so the next rates start over
+func (r *RateCounter) Reset() {
+	r.locker.Lock()
+	defer r.locker.Unlock()
+	r.last = nil
+}
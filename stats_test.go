@@ -0,0 +1,30 @@
+package SkyPanel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateCounter(t *testing.T) {
+	counter := &RateCounter{}
+
+	//the first sample has nothing to compare against
+	assert.Equal(t, []float64{0, 0}, counter.Rates(100, 200))
+
+	counter.lastTime = time.Now().Add(-2 * time.Second)
+	rates := counter.Rates(300, 200)
+	assert.InDelta(t, 100, rates[0], 1)
+	assert.Equal(t, float64(0), rates[1])
+
+	//counters going down mean the process restarted
+	counter.lastTime = time.Now().Add(-time.Second)
+	assert.Equal(t, []float64{0, 0}, counter.Rates(10, 20))
+
+	counter.Reset()
+	assert.Equal(t, []float64{0, 0}, counter.Rates(1000, 2000))
+
+	//a different number of counters starts over too
+	assert.Equal(t, []float64{0}, counter.Rates(5000))
+}
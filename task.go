@@ -4,5 +4,6 @@ type Task struct {
 	Name         string                    `json:"name"`
 	CronSchedule string                    `json:"cronSchedule"`
 	Description  string                    `json:"description,omitempty"`
+	Timeout      string                    `json:"timeout,omitempty"` //longest the whole task may take, such as 10m, empty means no limit
 	Operations   []ConditionalMetadataType `json:"operations,omitempty" binding:"required"`
 } //@name Task
@@ -0,0 +1,111 @@
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/gorilla/websocket"
+)
+
+const minBackoff = 5 * time.Second
+const maxBackoff = time.Minute
+
+// Serve keeps a tunnel open to the panel, serving handler on every stream the panel opens.
+// It reconnects with an increasing delay whenever the connection drops, until the context is cancelled.
+func Serve(ctx context.Context, panelUrl string, secret string, handler http.Handler) {
+	backoff := minBackoff
+
+	for {
+		started := time.Now()
+		err := serveOnce(ctx, panelUrl, secret, handler)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logging.Error.Printf("Tunnel to panel closed: %s", err)
+		}
+
+		//a connection that stayed up for a while was healthy, so start over with a short delay
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func serveOnce(ctx context.Context, panelUrl string, secret string, handler http.Handler) error {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+secret)
+
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 30 * time.Second,
+		ReadBufferSize:   32 * 1024,
+		WriteBufferSize:  32 * 1024,
+	}
+
+	conn, res, err := dialer.DialContext(ctx, panelUrl, header)
+	if err != nil {
+		if res != nil {
+			return errors.New(err.Error() + " (" + res.Status + ")")
+		}
+		return err
+	}
+
+	logging.Info.Printf("Tunnel to panel established at %s", panelUrl)
+	session := NewSession(conn, false)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-session.Done():
+		}
+		_ = session.Close()
+	}()
+
+	server := &http.Server{Handler: handler}
+	err = server.Serve(session)
+	if errors.Is(err, ErrSessionClosed) {
+		return nil
+	}
+	return err
+}
+
+// PanelUrl works out the websocket address of the tunnel endpoint from a panel url, like the configured auth url
+func PanelUrl(source string) (string, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", err
+	}
+
+	switch u.Scheme {
+	case "https", "wss":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+
+	//the panel may be served under a sub-path, which has to be kept
+	path := strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(path, "/tunnel") {
+		path = strings.TrimSuffix(path, "/oauth2/token") + "/tunnel"
+	}
+	u.Path = path
+	u.RawQuery = ""
+	return u.String(), nil
+}
@@ -0,0 +1,282 @@
+package tunnel
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	frameOpen byte = iota + 1
+	frameData
+	frameClose
+	frameWindow
+)
+
+const headerSize = 5
+
+// initialWindow is how many bytes a peer may send on a stream before it has to wait for the receiver to read them
+const initialWindow = 256 * 1024
+
+// maxFrameSize caps a single data frame, so one busy stream cannot hog the socket
+const maxFrameSize = 32 * 1024
+
+const pingInterval = 30 * time.Second
+
+var ErrSessionClosed = errors.New("tunnel session closed")
+var ErrNotAccepting = errors.New("tunnel session does not accept streams")
+
+// Session multiplexes many independent streams over a single websocket.
+// The panel opens streams to reach a daemon that dialed in, and the daemon accepts them and serves HTTP on them.
+type Session struct {
+	conn       *websocket.Conn
+	writeLock  sync.Mutex
+	streams    map[uint32]*stream
+	streamLock sync.Mutex
+	nextId     uint32
+	accept     chan *stream //nil on the initiator, which only opens streams
+	closed     chan struct{}
+	closeOnce  sync.Once
+	client     *http.Client
+}
+
+// NewSession wraps the websocket. The initiator side allocates odd stream ids, the other side even ones.
+// Only the other side accepts streams, streams opened towards the initiator are closed right away.
+func NewSession(conn *websocket.Conn, initiator bool) *Session {
+	s := &Session{
+		conn:    conn,
+		streams: make(map[uint32]*stream),
+		closed:  make(chan struct{}),
+	}
+	if initiator {
+		s.nextId = 1
+	} else {
+		s.nextId = 2
+		s.accept = make(chan *stream, 16)
+	}
+
+	s.client = &http.Client{
+		Transport: &http.Transport{
+			DialContext:       s.DialContext,
+			DisableKeepAlives: false,
+			IdleConnTimeout:   time.Minute,
+		},
+	}
+
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pingInterval * 2))
+	})
+	_ = conn.SetReadDeadline(time.Now().Add(pingInterval * 2))
+
+	go s.readLoop()
+	go s.pingLoop()
+	return s
+}
+
+// Open creates a new stream to the other end
+func (s *Session) Open() (net.Conn, error) {
+	s.streamLock.Lock()
+	select {
+	case <-s.closed:
+		s.streamLock.Unlock()
+		return nil, ErrSessionClosed
+	default:
+	}
+	id := s.nextId
+	s.nextId += 2
+	st := newStream(s, id)
+	s.streams[id] = st
+	s.streamLock.Unlock()
+
+	if err := s.writeFrame(frameOpen, id, nil); err != nil {
+		s.removeStream(id)
+		return nil, err
+	}
+	return st, nil
+}
+
+// DialContext matches the signature used by http.Transport and websocket.Dialer, the address is ignored as the
+// session only has one destination
+func (s *Session) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Open()
+}
+
+// HttpClient returns a client whose requests are carried over this session
+func (s *Session) HttpClient() *http.Client {
+	return s.client
+}
+
+// Accept waits for the other end to open a stream
+func (s *Session) Accept() (net.Conn, error) {
+	if s.accept == nil {
+		return nil, ErrNotAccepting
+	}
+	select {
+	case st := <-s.accept:
+		return st, nil
+	case <-s.closed:
+		return nil, ErrSessionClosed
+	}
+}
+
+// Addr is needed to satisfy net.Listener
+func (s *Session) Addr() net.Addr {
+	return addr{}
+}
+
+// Close tears down the websocket and every stream on it
+func (s *Session) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		err = s.conn.Close()
+
+		s.streamLock.Lock()
+		for _, v := range s.streams {
+			v.remoteClose()
+		}
+		s.streams = make(map[uint32]*stream)
+		s.streamLock.Unlock()
+
+		if t, ok := s.client.Transport.(*http.Transport); ok {
+			t.CloseIdleConnections()
+		}
+	})
+	return err
+}
+
+// Done is closed once the session is no longer usable
+func (s *Session) Done() <-chan struct{} {
+	return s.closed
+}
+
+func (s *Session) IsClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Session) writeFrame(frameType byte, id uint32, payload []byte) error {
+	data := make([]byte, headerSize+len(payload))
+	data[0] = frameType
+	binary.BigEndian.PutUint32(data[1:headerSize], id)
+	copy(data[headerSize:], payload)
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	select {
+	case <-s.closed:
+		return ErrSessionClosed
+	default:
+	}
+
+	_ = s.conn.SetWriteDeadline(time.Now().Add(pingInterval))
+	err := s.conn.WriteMessage(websocket.BinaryMessage, data)
+	if err != nil {
+		go s.Close()
+	}
+	return err
+}
+
+func (s *Session) readLoop() {
+	defer s.Close()
+
+	for {
+		messageType, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if messageType != websocket.BinaryMessage || len(data) < headerSize {
+			continue
+		}
+
+		id := binary.BigEndian.Uint32(data[1:headerSize])
+		payload := data[headerSize:]
+
+		switch data[0] {
+		case frameOpen:
+			if s.accept == nil {
+				_ = s.writeFrame(frameClose, id, nil)
+				continue
+			}
+			st := newStream(s, id)
+			s.streamLock.Lock()
+			s.streams[id] = st
+			s.streamLock.Unlock()
+
+			select {
+			case s.accept <- st:
+			case <-s.closed:
+				return
+			}
+		case frameData:
+			if st := s.getStream(id); st != nil {
+				st.receive(payload)
+			}
+		case frameWindow:
+			if st := s.getStream(id); st != nil && len(payload) == 4 {
+				st.grow(binary.BigEndian.Uint32(payload))
+			}
+		case frameClose:
+			if st := s.getStream(id); st != nil {
+				st.remoteClose()
+				s.removeStream(id)
+			}
+		}
+	}
+}
+
+func (s *Session) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-ticker.C:
+			s.writeLock.Lock()
+			err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingInterval))
+			s.writeLock.Unlock()
+			if err != nil {
+				_ = s.Close()
+				return
+			}
+		}
+	}
+}
+
+func (s *Session) getStream(id uint32) *stream {
+	s.streamLock.Lock()
+	defer s.streamLock.Unlock()
+	return s.streams[id]
+}
+
+func (s *Session) removeStream(id uint32) {
+	s.streamLock.Lock()
+	defer s.streamLock.Unlock()
+	delete(s.streams, id)
+}
+
+type addr struct{}
+
+func (addr) Network() string {
+	return "tunnel"
+}
+
+func (addr) String() string {
+	return "tunnel"
+}
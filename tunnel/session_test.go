@@ -0,0 +1,126 @@
+package tunnel
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSessionRoundTrip(t *testing.T) {
+	payload := bytes.Repeat([]byte("SkyPanel"), 200*1024)
+
+	sessions := make(chan *Session, 1)
+	panel := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		sessions <- NewSession(conn, true)
+	}))
+	defer panel.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(panel.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	daemon := NewSession(conn, false)
+	defer daemon.Close()
+
+	go func() {
+		_ = (&http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/daemon/large" {
+				_, _ = w.Write(payload)
+				return
+			}
+			_, _ = io.WriteString(w, r.URL.Path)
+		})}).Serve(daemon)
+	}()
+
+	var session *Session
+	select {
+	case session = <-sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tunnel was never established")
+	}
+	defer session.Close()
+
+	tests := []struct {
+		name string
+		path string
+		want []byte
+	}{
+		{name: "small response", path: "/daemon/server/abc", want: []byte("/daemon/server/abc")},
+		{name: "response larger than the window", path: "/daemon/large", want: payload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := session.HttpClient().Get("http://tunnel" + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			got, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got %d bytes, want %d bytes", len(got), len(tt.want))
+			}
+		})
+	}
+
+	t.Run("panel refuses streams", func(t *testing.T) {
+		_, err := session.Accept()
+		if err != ErrNotAccepting {
+			t.Errorf("Accept() error = %v, want %v", err, ErrNotAccepting)
+		}
+
+		st, err := daemon.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = st.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if _, err = st.Read(make([]byte, 1)); err != io.EOF {
+			t.Errorf("Read() error = %v, want the panel to close the stream", err)
+		}
+	})
+
+	_ = daemon.Close()
+	select {
+	case <-session.Done():
+	case <-time.After(5 * time.Second):
+		t.Error("panel session did not notice the daemon disconnecting")
+	}
+}
+
+func TestPanelUrl(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "http://localhost:8080/oauth2/token", want: "ws://localhost:8080/tunnel"},
+		{source: "https://panel.example.com", want: "wss://panel.example.com/tunnel"},
+		{source: "wss://panel.example.com/tunnel", want: "wss://panel.example.com/tunnel"},
+		{source: "https://example.com/panel", want: "wss://example.com/panel/tunnel"},
+		{source: "https://example.com/panel/oauth2/token", want: "wss://example.com/panel/tunnel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got, err := PanelUrl(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("PanelUrl() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package tunnel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// stream is one logical connection inside a Session
+type stream struct {
+	id      uint32
+	session *Session
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	buffer bytes.Buffer
+	window uint32
+
+	remoteClosed bool
+	localClosed  bool
+
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func newStream(session *Session, id uint32) *stream {
+	st := &stream{
+		id:      id,
+		session: session,
+		window:  initialWindow,
+	}
+	st.cond = sync.NewCond(&st.lock)
+	return st
+}
+
+func (st *stream) Read(p []byte) (int, error) {
+	st.lock.Lock()
+	for st.buffer.Len() == 0 {
+		if st.remoteClosed || st.localClosed {
+			st.lock.Unlock()
+			return 0, io.EOF
+		}
+		if !st.readDeadline.IsZero() && !time.Now().Before(st.readDeadline) {
+			st.lock.Unlock()
+			return 0, os.ErrDeadlineExceeded
+		}
+		st.cond.Wait()
+	}
+	n, _ := st.buffer.Read(p)
+	st.lock.Unlock()
+
+	//tell the other end it may send this much more
+	if n > 0 {
+		update := make([]byte, 4)
+		binary.BigEndian.PutUint32(update, uint32(n))
+		_ = st.session.writeFrame(frameWindow, st.id, update)
+	}
+	return n, nil
+}
+
+func (st *stream) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		st.lock.Lock()
+		for st.window == 0 {
+			if st.remoteClosed || st.localClosed {
+				st.lock.Unlock()
+				return written, io.ErrClosedPipe
+			}
+			if !st.writeDeadline.IsZero() && !time.Now().Before(st.writeDeadline) {
+				st.lock.Unlock()
+				return written, os.ErrDeadlineExceeded
+			}
+			st.cond.Wait()
+		}
+		if st.remoteClosed || st.localClosed {
+			st.lock.Unlock()
+			return written, io.ErrClosedPipe
+		}
+
+		size := len(p) - written
+		if size > maxFrameSize {
+			size = maxFrameSize
+		}
+		if uint32(size) > st.window {
+			size = int(st.window)
+		}
+		st.window -= uint32(size)
+		st.lock.Unlock()
+
+		if err := st.session.writeFrame(frameData, st.id, p[written:written+size]); err != nil {
+			return written, err
+		}
+		written += size
+	}
+	return written, nil
+}
+
+func (st *stream) Close() error {
+	st.lock.Lock()
+	if st.localClosed {
+		st.lock.Unlock()
+		return nil
+	}
+	st.localClosed = true
+	remoteClosed := st.remoteClosed
+	st.cond.Broadcast()
+	st.lock.Unlock()
+
+	st.session.removeStream(st.id)
+	if remoteClosed {
+		return nil
+	}
+	return st.session.writeFrame(frameClose, st.id, nil)
+}
+
+func (st *stream) LocalAddr() net.Addr {
+	return addr{}
+}
+
+func (st *stream) RemoteAddr() net.Addr {
+	return addr{}
+}
+
+func (st *stream) SetDeadline(t time.Time) error {
+	_ = st.SetReadDeadline(t)
+	return st.SetWriteDeadline(t)
+}
+
+func (st *stream) SetReadDeadline(t time.Time) error {
+	st.lock.Lock()
+	st.readDeadline = t
+	st.lock.Unlock()
+	st.wakeAt(t)
+	return nil
+}
+
+func (st *stream) SetWriteDeadline(t time.Time) error {
+	st.lock.Lock()
+	st.writeDeadline = t
+	st.lock.Unlock()
+	st.wakeAt(t)
+	return nil
+}
+
+// wakeAt makes sure blocked readers and writers re-check their deadline once it passes
+func (st *stream) wakeAt(t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	time.AfterFunc(time.Until(t), func() {
+		st.lock.Lock()
+		st.cond.Broadcast()
+		st.lock.Unlock()
+	})
+}
+
+func (st *stream) receive(data []byte) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	if st.localClosed {
+		return
+	}
+	st.buffer.Write(data)
+	st.cond.Broadcast()
+}
+
+func (st *stream) grow(size uint32) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	st.window += size
+	st.cond.Broadcast()
+}
+
+func (st *stream) remoteClose() {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	st.remoteClosed = true
+	st.cond.Broadcast()
+}
@@ -49,3 +49,16 @@ func Remove[T comparable](a []T, b T) []T {
 	}
 	return replacement
 }
+
+// IsHex checks a value is lowercase hex of the given length, such as a sha1 or sha256 sum
+func IsHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for _, c := range value {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
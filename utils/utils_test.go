@@ -146,3 +146,11 @@ func TestRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestIsHex(t *testing.T) {
+	assert.True(t, IsHex("0123456789abcdef", 16))
+	assert.False(t, IsHex("0123456789ABCDEF", 16))
+	assert.False(t, IsHex("abc", 2))
+	assert.False(t, IsHex("", 40))
+	assert.False(t, IsHex("../../x", 7))
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/SkyPanel/SkyPanel/v3/alerts"
+	"github.com/SkyPanel/SkyPanel/v3/middleware"
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/response"
+	"github.com/SkyPanel/SkyPanel/v3/scopes"
+	"github.com/SkyPanel/SkyPanel/v3/services"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+)
+
+func registerAlertRules(g *gin.RouterGroup) {
+	g.Handle("GET", "", middleware.RequiresPermission(scopes.ScopeAlertsView), listAlertRules)
+	g.Handle("POST", "", middleware.RequiresPermission(scopes.ScopeAlertsEdit), createAlertRule)
+	g.Handle("OPTIONS", "", response.CreateOptions("GET", "POST"))
+
+	g.Handle("GET", "/variables", middleware.RequiresPermission(scopes.ScopeAlertsView), getAlertVariables)
+	g.Handle("OPTIONS", "/variables", response.CreateOptions("GET"))
+
+	g.Handle("GET", "/:id", middleware.RequiresPermission(scopes.ScopeAlertsView), getAlertRule)
+	g.Handle("PUT", "/:id", middleware.RequiresPermission(scopes.ScopeAlertsEdit), updateAlertRule)
+	g.Handle("DELETE", "/:id", middleware.RequiresPermission(scopes.ScopeAlertsEdit), deleteAlertRule)
+	g.Handle("OPTIONS", "/:id", response.CreateOptions("GET", "PUT", "DELETE"))
+}
+
+// @Summary List alert rules
+// @Description Lists every alert rule, or only the rules of one server when serverId is given
+// @Success 200 {array} models.AlertRule
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param serverId query string false "Only rules of this server"
+// @Router /api/alerts [get]
+// @Security OAuth2Application[alerts.view]
+func listAlertRules(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	as := &services.AlertRule{DB: db}
+
+	rules, err := as.List(c.Query("serverId"))
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// @Summary Create alert rule
+// @Description Creates a rule, which applies to every server unless serverId is set
+// @Success 200 {object} models.AlertRule
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param body body models.AlertRule true "New alert rule"
+// @Router /api/alerts [post]
+// @Security OAuth2Application[alerts.edit]
+func createAlertRule(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	as := &services.AlertRule{DB: db}
+
+	var rule models.AlertRule
+	if err := c.BindJSON(&rule); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	if err := as.Create(&rule); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// @Summary Get alert rule
+// @Success 200 {object} models.AlertRule
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Alert rule ID"
+// @Router /api/alerts/{id} [get]
+// @Security OAuth2Application[alerts.view]
+func getAlertRule(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	as := &services.AlertRule{DB: db}
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	rule, err := as.Get(id)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// @Summary Update alert rule
+// @Success 200 {object} models.AlertRule
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Alert rule ID"
+// @Param body body models.AlertRule true "Updated alert rule"
+// @Router /api/alerts/{id} [put]
+// @Security OAuth2Application[alerts.edit]
+func updateAlertRule(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	as := &services.AlertRule{DB: db}
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var rule models.AlertRule
+	if err := c.BindJSON(&rule); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	rule.ID = id
+	if err := as.Update(&rule); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// @Summary Delete alert rule
+// @Success 204 {object} nil
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Alert rule ID"
+// @Router /api/alerts/{id} [delete]
+// @Security OAuth2Application[alerts.edit]
+func deleteAlertRule(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	as := &services.AlertRule{DB: db}
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := as.Delete(id); response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get alert variables
+// @Description Lists the variables alert expressions can use, with their type
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Router /api/alerts/variables [get]
+// @Security OAuth2Application[alerts.view]
+func getAlertVariables(c *gin.Context) {
+	result := make(map[string]string, len(alerts.Variables))
+	for k, v := range alerts.Variables {
+		result[k] = v.String()
+	}
+	c.JSON(http.StatusOK, result)
+}
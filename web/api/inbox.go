@@ -0,0 +1,269 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/middleware"
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/response"
+	"github.com/SkyPanel/SkyPanel/v3/scopes"
+	"github.com/SkyPanel/SkyPanel/v3/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/spf13/cast"
+)
+
+var inboxUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+func registerInbox(g *gin.RouterGroup) {
+	g.Handle("GET", "", middleware.RequiresPermission(scopes.ScopeLogin), getInbox)
+	g.Handle("OPTIONS", "", response.CreateOptions("GET"))
+
+	g.Handle("GET", "/unread", middleware.RequiresPermission(scopes.ScopeLogin), getInboxUnread)
+	g.Handle("OPTIONS", "/unread", response.CreateOptions("GET"))
+
+	g.Handle("POST", "/read", middleware.RequiresPermission(scopes.ScopeLogin), markInboxRead)
+	g.Handle("OPTIONS", "/read", response.CreateOptions("POST"))
+
+	g.Handle("GET", "/preferences", middleware.RequiresPermission(scopes.ScopeLogin), getNotificationPreferences)
+	g.Handle("PUT", "/preferences", middleware.RequiresPermission(scopes.ScopeLogin), setNotificationPreferences)
+	g.Handle("OPTIONS", "/preferences", response.CreateOptions("GET", "PUT"))
+
+	g.Handle("GET", "/socket", middleware.RequiresPermission(scopes.ScopeLogin), openInboxSocket)
+
+	g.Handle("POST", "/:id/read", middleware.RequiresPermission(scopes.ScopeLogin), markInboxEntryRead)
+	g.Handle("OPTIONS", "/:id/read", response.CreateOptions("POST"))
+
+	g.Handle("DELETE", "/:id", middleware.RequiresPermission(scopes.ScopeLogin), deleteInboxEntry)
+	g.Handle("OPTIONS", "/:id", response.CreateOptions("DELETE"))
+}
+
+type InboxUnread struct {
+	Unread int64 `json:"unread"`
+} //@name InboxUnread
+
+// @Summary Get your notifications
+// @Description Gets the in-panel notifications of the current user, newest first
+// @Success 200 {object} models.UserNotificationSearchResponse
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param unread query bool false "Only unread notifications"
+// @Param limit query uint false "Max results to return"
+// @Param page query uint false "What page to get"
+// @Router /api/self/notifications [get]
+// @Security OAuth2Application[login]
+func getInbox(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	is := &services.Inbox{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	search := &models.UserNotificationSearch{PageLimit: DefaultPageSize, Page: 1}
+	if err := c.ShouldBind(search); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	if search.PageLimit > MaxPageSize {
+		search.PageLimit = MaxPageSize
+	}
+	if search.PageLimit == 0 {
+		search.PageLimit = DefaultPageSize
+	}
+	if search.Page == 0 {
+		search.Page = 1
+	}
+
+	results, total, err := is.Search(user.ID, search.Unread, search.PageLimit, search.Page)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	unread, err := is.UnreadCount(user.ID)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.UserNotificationSearchResponse{
+		Notifications: results,
+		Unread:        unread,
+		Metadata: &SkyPanel.Metadata{Paging: &SkyPanel.Paging{
+			Page:    search.Page,
+			Size:    search.PageLimit,
+			MaxSize: MaxPageSize,
+			Total:   total,
+		}},
+	})
+}
+
+// @Summary Get your unread notification count
+// @Success 200 {object} InboxUnread
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Router /api/self/notifications/unread [get]
+// @Security OAuth2Application[login]
+func getInboxUnread(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	is := &services.Inbox{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	unread, err := is.UnreadCount(user.ID)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.JSON(http.StatusOK, &InboxUnread{Unread: unread})
+}
+
+// @Summary Mark all your notifications as read
+// @Success 204 {object} nil
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Router /api/self/notifications/read [post]
+// @Security OAuth2Application[login]
+func markInboxRead(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	is := &services.Inbox{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	if err := is.MarkAllRead(user.ID); response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Mark a notification as read
+// @Success 204 {object} nil
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Notification ID"
+// @Router /api/self/notifications/{id}/read [post]
+// @Security OAuth2Application[login]
+func markInboxEntryRead(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	is := &services.Inbox{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = is.MarkRead(id, user.ID); response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Delete a notification
+// @Success 204 {object} nil
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Notification ID"
+// @Router /api/self/notifications/{id} [delete]
+// @Security OAuth2Application[login]
+func deleteInboxEntry(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	is := &services.Inbox{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err = is.Delete(id, user.ID); response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get your notification preferences
+// @Description Gets how you get each event: in the inbox, by email, both, or not at all when the list is empty
+// @Success 200 {object} models.NotificationPreferences
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Router /api/self/notifications/preferences [get]
+// @Security OAuth2Application[login]
+func getNotificationPreferences(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	is := &services.Inbox{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	preferences, err := is.GetPreferences(user.ID)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// @Summary Update your notification preferences
+// @Description Only the events in the body are changed
+// @Success 204 {object} nil
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param body body models.NotificationPreferences true "Preferences per event"
+// @Router /api/self/notifications/preferences [put]
+// @Security OAuth2Application[login]
+func setNotificationPreferences(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	is := &services.Inbox{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	var preferences models.NotificationPreferences
+	if err := c.BindJSON(&preferences); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	if err := is.SetPreferences(user.ID, preferences); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Notification socket
+// @Description Websocket which sends your new inbox notifications as they arrive, with type "notification"
+// @Success 101 {object} nil
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Router /api/self/notifications/socket [get]
+// @Security OAuth2Application[login]
+func openInboxSocket(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	conn, err := inboxUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	socket := SkyPanel.Create(conn)
+	services.RegisterInboxSocket(user.ID, socket)
+
+	//the socket only sends, reading is just to notice when the client goes away
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				_ = socket.Close()
+				return
+			}
+		}
+	}()
+}
@@ -27,6 +27,9 @@ func RegisterRoutes(rg *gin.RouterGroup) {
 	registerSettings(rg.Group("/settings"))
 	registerUserSettings(rg.Group("/userSettings"))
 	registerUptime(rg.Group("/uptime"))
+	registerMaintenance(rg.Group("/maintenance"))
+	registerAlertRules(rg.Group("/alerts"))
+	registerNotificationChannels(rg.Group("/notificationChannels"))
 	registerRoles(rg.Group("/roles"))
 
 	rg.GET("/config", panelConfig)
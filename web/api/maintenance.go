@@ -0,0 +1,175 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/middleware"
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/response"
+	"github.com/SkyPanel/SkyPanel/v3/scopes"
+	"github.com/SkyPanel/SkyPanel/v3/services"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+)
+
+func registerMaintenance(g *gin.RouterGroup) {
+	g.Handle("GET", "", middleware.RequiresPermission(scopes.ScopeUptimeView), listMaintenanceWindows)
+	g.Handle("POST", "", middleware.RequiresPermission(scopes.ScopeUptimeEdit), createMaintenanceWindow)
+	g.Handle("OPTIONS", "", response.CreateOptions("GET", "POST"))
+
+	g.Handle("GET", "/:id", middleware.RequiresPermission(scopes.ScopeUptimeView), getMaintenanceWindow)
+	g.Handle("PUT", "/:id", middleware.RequiresPermission(scopes.ScopeUptimeEdit), updateMaintenanceWindow)
+	g.Handle("DELETE", "/:id", middleware.RequiresPermission(scopes.ScopeUptimeEdit), deleteMaintenanceWindow)
+	g.Handle("OPTIONS", "/:id", response.CreateOptions("GET", "PUT", "DELETE"))
+}
+
+// @Summary List maintenance windows
+// @Description Lists the maintenance windows that overlap with the given dates, by default the last 90 days and every planned one
+// @Success 200 {array} models.MaintenanceWindow
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param from query string false "Start date, as YYYY-MM-DD"
+// @Param to query string false "End date, as YYYY-MM-DD"
+// @Router /api/maintenance [get]
+// @Security OAuth2Application[uptime.view]
+func listMaintenanceWindows(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ms := &services.Maintenance{DB: db}
+
+	from := time.Now().AddDate(0, 0, -90)
+	to := time.Now().AddDate(100, 0, 0)
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, v, time.Local); response.HandleError(c, err, http.StatusBadRequest) {
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, v, time.Local); response.HandleError(c, err, http.StatusBadRequest) {
+			return
+		}
+	}
+
+	windows, err := ms.List(from, to)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.JSON(http.StatusOK, windows)
+}
+
+// @Summary Create maintenance window
+// @Description Creates a planned maintenance window, for one server, the servers of one node, or every server when neither is set
+// @Success 200 {object} models.MaintenanceWindow
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param body body models.MaintenanceWindow true "New maintenance window"
+// @Router /api/maintenance [post]
+// @Security OAuth2Application[uptime.edit]
+func createMaintenanceWindow(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ms := &services.Maintenance{DB: db}
+
+	var window models.MaintenanceWindow
+	if err := c.BindJSON(&window); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	if err := ms.Create(&window); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// @Summary Get maintenance window
+// @Success 200 {object} models.MaintenanceWindow
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Maintenance window ID"
+// @Router /api/maintenance/{id} [get]
+// @Security OAuth2Application[uptime.view]
+func getMaintenanceWindow(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ms := &services.Maintenance{DB: db}
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	window, err := ms.Get(id)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// @Summary Update maintenance window
+// @Success 200 {object} models.MaintenanceWindow
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Maintenance window ID"
+// @Param body body models.MaintenanceWindow true "Updated maintenance window"
+// @Router /api/maintenance/{id} [put]
+// @Security OAuth2Application[uptime.edit]
+func updateMaintenanceWindow(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ms := &services.Maintenance{DB: db}
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var window models.MaintenanceWindow
+	if err := c.BindJSON(&window); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	window.ID = id
+	if err := ms.Update(&window); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	c.JSON(http.StatusOK, window)
+}
+
+// @Summary Delete maintenance window
+// @Success 204 {object} nil
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Maintenance window ID"
+// @Router /api/maintenance/{id} [delete]
+// @Security OAuth2Application[uptime.edit]
+func deleteMaintenanceWindow(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ms := &services.Maintenance{DB: db}
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := ms.Delete(id); response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
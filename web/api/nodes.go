@@ -34,6 +34,9 @@ func registerNodes(g *gin.RouterGroup) {
 	g.Handle("GET", "/:id/system", middleware.RequiresPermission(scopes.ScopeNodesView), getSystemInfo)
 	g.Handle("OPTIONS", "/:id/system", response.CreateOptions("GET"))
 
+	g.Handle("GET", "/:id/metrics", middleware.RequiresPermission(scopes.ScopeNodesMetrics), getNodeMetrics)
+	g.Handle("OPTIONS", "/:id/metrics", response.CreateOptions("GET"))
+
 	g.Handle("GET", "/:id/deployment", middleware.RequiresPermission(scopes.ScopeNodesDeploy), deployNode)
 	g.Handle("OPTIONS", "/:id/deployment", response.CreateOptions("GET"))
 }
@@ -318,6 +321,33 @@ func getSystemInfo(c *gin.Context) {
 	c.JSON(http.StatusOK, systemInfo)
 }
 
+// @Summary Gets the metrics of a node
+// @Description Gets the metrics of a node and its servers in the Prometheus format, for scrapers which authenticate against the panel
+// @Success 200 {string} string
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Node Id"
+// @Router /api/nodes/{id}/metrics [get]
+// @Security OAuth2Application[nodes.metrics]
+func getNodeMetrics(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ns := &services.Node{DB: db}
+
+	id, ok := validateId(c)
+	if !ok {
+		return
+	}
+
+	node, err := ns.Get(id)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	proxyHttpRequest(c, "/daemon/metrics", ns, node)
+}
+
 func validateId(c *gin.Context) (uint, bool) {
 	param := c.Param("id")
 
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/middleware"
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/notifications"
+	"github.com/SkyPanel/SkyPanel/v3/response"
+	"github.com/SkyPanel/SkyPanel/v3/scopes"
+	"github.com/SkyPanel/SkyPanel/v3/services"
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cast"
+)
+
+func registerNotificationChannels(g *gin.RouterGroup) {
+	g.Handle("GET", "", middleware.RequiresPermission(scopes.ScopeLogin), listNotificationChannels)
+	g.Handle("POST", "", middleware.RequiresPermission(scopes.ScopeSelfEdit), createNotificationChannel)
+	g.Handle("OPTIONS", "", response.CreateOptions("GET", "POST"))
+
+	g.Handle("GET", "/types", middleware.RequiresPermission(scopes.ScopeLogin), getNotificationTypes)
+	g.Handle("OPTIONS", "/types", response.CreateOptions("GET"))
+
+	g.Handle("GET", "/:id", middleware.RequiresPermission(scopes.ScopeLogin), getNotificationChannel)
+	g.Handle("PUT", "/:id", middleware.RequiresPermission(scopes.ScopeSelfEdit), updateNotificationChannel)
+	g.Handle("DELETE", "/:id", middleware.RequiresPermission(scopes.ScopeSelfEdit), deleteNotificationChannel)
+	g.Handle("OPTIONS", "/:id", response.CreateOptions("GET", "PUT", "DELETE"))
+
+	g.Handle("POST", "/:id/test", middleware.RequiresPermission(scopes.ScopeSelfEdit), testNotificationChannel)
+	g.Handle("OPTIONS", "/:id/test", response.CreateOptions("POST"))
+}
+
+type NotificationTypes struct {
+	Types  []string `json:"types"`
+	Events []string `json:"events"`
+} //@name NotificationTypes
+
+// @Summary List notification channels
+// @Description Lists the notification channels of the current user
+// @Success 200 {array} models.NotificationChannel
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Router /api/notificationChannels [get]
+// @Security OAuth2Application[login]
+func listNotificationChannels(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ns := &services.NotificationChannel{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	channels, err := ns.List(user.ID)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.JSON(http.StatusOK, channels)
+}
+
+// @Summary Create notification channel
+// @Description Creates a channel for the current user, which gets the events of every server the user can see unless serverId is set
+// @Success 200 {object} models.NotificationChannel
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param body body models.NotificationChannel true "New notification channel"
+// @Router /api/notificationChannels [post]
+// @Security OAuth2Application[self.edit]
+func createNotificationChannel(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ns := &services.NotificationChannel{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	var channel models.NotificationChannel
+	if err := c.BindJSON(&channel); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	channel.UserID = user.ID
+	if err := ns.Create(&channel); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+// @Summary Get notification channel
+// @Success 200 {object} models.NotificationChannel
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Notification channel ID"
+// @Router /api/notificationChannels/{id} [get]
+// @Security OAuth2Application[login]
+func getNotificationChannel(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ns := &services.NotificationChannel{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	channel, err := ns.GetForUser(id, user.ID)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+// @Summary Update notification channel
+// @Success 200 {object} models.NotificationChannel
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Notification channel ID"
+// @Param body body models.NotificationChannel true "Updated notification channel"
+// @Router /api/notificationChannels/{id} [put]
+// @Security OAuth2Application[self.edit]
+func updateNotificationChannel(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ns := &services.NotificationChannel{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var channel models.NotificationChannel
+	if err := c.BindJSON(&channel); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	channel.ID = id
+	channel.UserID = user.ID
+	if err := ns.Update(&channel); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	c.JSON(http.StatusOK, channel)
+}
+
+// @Summary Delete notification channel
+// @Success 204 {object} nil
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Notification channel ID"
+// @Router /api/notificationChannels/{id} [delete]
+// @Security OAuth2Application[self.edit]
+func deleteNotificationChannel(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ns := &services.NotificationChannel{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := ns.Delete(id, user.ID); response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Test notification channel
+// @Description Sends a test notification to the channel and returns the error if delivery failed
+// @Success 204 {object} nil
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path uint true "Notification channel ID"
+// @Router /api/notificationChannels/{id}/test [post]
+// @Security OAuth2Application[self.edit]
+func testNotificationChannel(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	ns := &services.NotificationChannel{DB: db}
+	user := c.MustGet("user").(*models.User)
+
+	var err error
+	var id uint
+	if id, err = cast.ToUintE(c.Param("id")); err != nil {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+
+	channel, err := ns.GetForUser(id, user.ID)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	n := &notifications.Notification{
+		Event:    notifications.EventTest,
+		Severity: notifications.SeverityInfo,
+		Title:    "SkyPanel",
+		Message:  "Notificación de prueba del canal " + channel.Name,
+		Time:     time.Now(),
+	}
+	if err = services.SendNotification(channel, n); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get notification types
+// @Description Lists the channel types and the events a channel can subscribe to
+// @Success 200 {object} NotificationTypes
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Router /api/notificationChannels/types [get]
+// @Security OAuth2Application[login]
+func getNotificationTypes(c *gin.Context) {
+	types := make([]string, 0, len(notifications.Types))
+	for k := range notifications.Types {
+		types = append(types, k)
+	}
+	sort.Strings(types)
+
+	c.JSON(http.StatusOK, NotificationTypes{Types: types, Events: notifications.Events})
+}
@@ -36,6 +36,8 @@ func registerSelf(g *gin.RouterGroup) {
 
 	g.Handle("DELETE", "/oauth2/:clientId", middleware.RequiresPermission(scopes.ScopeSelfClients), deletePersonalOAuth2Client)
 	g.Handle("OPTIONS", "/oauth2/:clientId", response.CreateOptions("DELETE"))
+
+	registerInbox(g.Group("/notifications"))
 }
 
 // @Summary Get your user info
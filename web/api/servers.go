@@ -60,6 +60,9 @@ func registerServers(g *gin.RouterGroup) {
 	g.POST("/:serverId/flags", middleware.RequiresPermission(scopes.ScopeServerEditFlags), middleware.ResolveServerPanel, proxyServerRequest)
 	g.OPTIONS("/:serverId/flags", response.CreateOptions("GET", "POST"))
 
+	g.Handle("PUT", "/:serverId/monitoring", middleware.RequiresPermission(scopes.ScopeServerEditFlags), middleware.ResolveServerPanel, middleware.HasTransaction, setServerMonitoring)
+	g.Handle("OPTIONS", "/:serverId/monitoring", response.CreateOptions("PUT"))
+
 	g.GET("/:serverId/tasks", middleware.RequiresPermission(scopes.ScopeServerTaskView), middleware.ResolveServerPanel, proxyServerRequest)
 	g.OPTIONS("/:serverId/tasks", response.CreateOptions("GET"))
 
@@ -89,12 +92,26 @@ func registerServers(g *gin.RouterGroup) {
 	g.POST("/:serverId/install", middleware.RequiresPermission(scopes.ScopeServerInstall), middleware.ResolveServerPanel, proxyServerRequest)
 	g.OPTIONS("/:serverId/install", response.CreateOptions("POST"))
 
+	g.POST("/:serverId/install/cancel", middleware.RequiresPermission(scopes.ScopeServerInstall), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/install/cancel", response.CreateOptions("POST"))
+
 	g.GET("/:serverId/file/*filename", middleware.RequiresPermission(scopes.ScopeServerFileView), middleware.ResolveServerPanel, proxyServerRequest)
 	g.PUT("/:serverId/file/*filename", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
 	g.DELETE("/:serverId/file/*filename", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
 	g.POST("/:serverId/file/*filename", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
 	g.OPTIONS("/:serverId/file/*filename", response.CreateOptions("GET", "PUT", "DELETE", "POST"))
 
+	g.GET("/:serverId/config/*filename", middleware.RequiresPermission(scopes.ScopeServerFileView), middleware.ResolveServerPanel, proxyServerRequest)
+	g.PATCH("/:serverId/config/*filename", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/config/*filename", response.CreateOptions("GET", "PATCH"))
+
+	g.POST("/:serverId/deploy", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/deploy", response.CreateOptions("POST"))
+	g.GET("/:serverId/deploy/key", middleware.RequiresPermission(scopes.ScopeServerViewDefinition), middleware.ResolveServerPanel, proxyServerRequest)
+	g.POST("/:serverId/deploy/key", middleware.RequiresPermission(scopes.ScopeServerEditDefinition), middleware.ResolveServerPanel, proxyServerRequest)
+	g.DELETE("/:serverId/deploy/key", middleware.RequiresPermission(scopes.ScopeServerEditDefinition), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/deploy/key", response.CreateOptions("GET", "POST", "DELETE"))
+
 	g.GET("/:serverId/console", middleware.RequiresPermission(scopes.ScopeServerConsole), middleware.ResolveServerPanel, proxyServerRequest)
 	g.POST("/:serverId/console", middleware.RequiresPermission(scopes.ScopeServerSendCommand), middleware.ResolveServerPanel, proxyServerRequest)
 	g.OPTIONS("/:serverId/console", response.CreateOptions("GET", "POST"))
@@ -102,6 +119,22 @@ func registerServers(g *gin.RouterGroup) {
 	g.GET("/:serverId/stats", middleware.RequiresPermission(scopes.ScopeServerStats), middleware.ResolveServerPanel, proxyServerRequest)
 	g.OPTIONS("/:serverId/stats", response.CreateOptions("GET"))
 
+	g.GET("/:serverId/stats/history", middleware.RequiresPermission(scopes.ScopeServerStats), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/stats/history", response.CreateOptions("GET"))
+
+	g.GET("/:serverId/crashes", middleware.RequiresPermission(scopes.ScopeServerCrashView), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/crashes", response.CreateOptions("GET"))
+
+	g.POST("/:serverId/crashes/reset", middleware.RequiresPermission(scopes.ScopeServerStart), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/crashes/reset", response.CreateOptions("POST"))
+
+	g.GET("/:serverId/crashes/:crashId", middleware.RequiresPermission(scopes.ScopeServerCrashView), middleware.ResolveServerPanel, proxyServerRequest)
+	g.DELETE("/:serverId/crashes/:crashId", middleware.RequiresPermission(scopes.ScopeServerCrashDelete), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/crashes/:crashId", response.CreateOptions("GET", "DELETE"))
+
+	g.GET("/:serverId/crashes/:crashId/files/:name", middleware.RequiresPermission(scopes.ScopeServerCrashView), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/crashes/:crashId/files/:name", response.CreateOptions("GET"))
+
 	g.HEAD("/:serverId/query", middleware.RequiresPermission(scopes.ScopeServerStats), middleware.ResolveServerPanel, proxyServerRequest)
 	g.GET("/:serverId/query", middleware.RequiresPermission(scopes.ScopeServerStats), middleware.ResolveServerPanel, proxyServerRequest)
 	g.OPTIONS("/:serverId/query", response.CreateOptions("POST"))
@@ -136,6 +169,27 @@ func registerServers(g *gin.RouterGroup) {
 	g.POST("/:serverId/plugins/:pluginId", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
 	g.OPTIONS("/:serverId/plugins/:pluginId", response.CreateOptions("POST"))
 
+	g.GET("/:serverId/addons", middleware.RequiresPermission(scopes.ScopeServerFileView), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/addons", response.CreateOptions("GET"))
+	g.GET("/:serverId/addons/updates", middleware.RequiresPermission(scopes.ScopeServerFileView), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/addons/updates", response.CreateOptions("GET"))
+	g.POST("/:serverId/addons/update", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/addons/update", response.CreateOptions("POST"))
+	g.POST("/:serverId/addons/rollback", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/addons/rollback", response.CreateOptions("POST"))
+	g.PUT("/:serverId/addons/source", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
+	g.DELETE("/:serverId/addons/source", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/addons/source", response.CreateOptions("PUT", "DELETE"))
+
+	g.GET("/:serverId/modrinth", middleware.RequiresPermission(scopes.ScopeServerFileView), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/modrinth", response.CreateOptions("GET"))
+	g.GET("/:serverId/modrinth/search", middleware.RequiresPermission(scopes.ScopeServerFileView), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/modrinth/search", response.CreateOptions("GET"))
+	g.POST("/:serverId/modrinth/:projectId", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
+	g.PUT("/:serverId/modrinth/:projectId", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
+	g.DELETE("/:serverId/modrinth/:projectId", middleware.RequiresPermission(scopes.ScopeServerFileEdit), middleware.ResolveServerPanel, proxyServerRequest)
+	g.OPTIONS("/:serverId/modrinth/:projectId", response.CreateOptions("POST", "PUT", "DELETE"))
+
 	p := g.Group("/:serverId/socket")
 	{
 		p.GET("", middleware.RequiresPermission(scopes.ScopeServerView), cors.New(cors.Config{
@@ -366,6 +420,7 @@ func createServer(c *gin.Context) {
 		Port:       cast.ToUint16(port),
 		Type:       postBody.Type.Type,
 		Icon:       postBody.Icon,
+		Monitored:  postBody.Monitored,
 	}
 
 	users := make([]*models.User, len(postBody.Users))
@@ -468,6 +523,10 @@ func createServer(c *gin.Context) {
 		}
 	}
 
+	if server.Monitored {
+		middleware.AfterCommit(c, services.QueueGatusServerSync)
+	}
+
 	c.JSON(http.StatusOK, &models.CreateServerResponse{Id: serverId})
 }
 
@@ -550,6 +609,10 @@ func editServer(c *gin.Context) {
 	if response.HandleError(c, db.Commit().Error, http.StatusInternalServerError) {
 		return
 	}
+
+	if server.Monitored {
+		services.QueueGatusServerSync()
+	}
 	c.Status(http.StatusNoContent)
 }
 
@@ -650,6 +713,10 @@ func deleteServer(c *gin.Context) {
 		return
 	}
 
+	if server.Monitored {
+		services.QueueGatusServerSync()
+	}
+
 	es := services.GetEmailService()
 	for _, u := range users {
 		err = es.SendEmail(u.Email, "deletedServer", map[string]interface{}{
@@ -942,6 +1009,38 @@ func renameServer(c *gin.Context) {
 		return
 	}
 
+	if server.Monitored {
+		middleware.AfterCommit(c, services.QueueGatusServerSync)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Set external monitoring
+// @Description Enables or disables the Gatus endpoint generated for the server
+// @Success 204 {object} nil
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param body body models.ServerMonitoring true "Monitoring state"
+// @Router /api/servers/{id}/monitoring [put]
+// @Security OAuth2Application[server.flags.edit]
+func setServerMonitoring(c *gin.Context) {
+	server := getServerFromGin(c)
+	db := middleware.GetDatabase(c)
+	ss := &services.Server{DB: db}
+
+	var body models.ServerMonitoring
+	if err := c.ShouldBindJSON(&body); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	server.Monitored = body.Enabled
+	err := ss.Update(server)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	middleware.AfterCommit(c, services.QueueGatusServerSync)
 	c.Status(http.StatusNoContent)
 }
 
@@ -1012,6 +1111,10 @@ func editServerDataAdmin(c *gin.Context) {
 		if response.HandleError(c, err, http.StatusInternalServerError) {
 			return
 		}
+
+		if server.Monitored {
+			middleware.AfterCommit(c, services.QueueGatusServerSync)
+		}
 	}
 
 	proxyServerRequest(c)
@@ -443,6 +443,8 @@ var editableStringEntries = []config.StringEntry{
 	config.DiscordWebhook,
 	config.DiscordWebhookSystem,
 	config.DiscordWebhookNode,
+	config.UptimeReportSchedule,
+	config.UptimeReportEmails,
 	config.LicenseKey,
 	config.LicenseStatus,
 	config.LicenseServerId,
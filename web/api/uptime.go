@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -14,13 +15,18 @@ import (
 	"github.com/SkyPanel/SkyPanel/v3/scopes"
 	"github.com/SkyPanel/SkyPanel/v3/services"
 	"github.com/SkyPanel/SkyPanel/v3/utils"
+	"github.com/spf13/cast"
 )
 
 func registerUptime(g *gin.RouterGroup) {
 	g.Handle("GET", "", middleware.RequiresPermission(scopes.ScopeAdmin), getAllUptime)
+	g.Handle("GET", "/report", middleware.RequiresPermission(scopes.ScopeUptimeView), getUptimeSummary)
 	g.Handle("GET", "/:serverId", middleware.RequiresPermission(scopes.ScopeServerView), middleware.ResolveServerPanel, getServerUptime)
+	g.Handle("GET", "/:serverId/report", middleware.RequiresPermission(scopes.ScopeServerView), middleware.ResolveServerPanel, getServerUptimeReport)
 	g.Handle("OPTIONS", "", response.CreateOptions("GET"))
+	g.Handle("OPTIONS", "/report", response.CreateOptions("GET"))
 	g.Handle("OPTIONS", "/:serverId", response.CreateOptions("GET"))
+	g.Handle("OPTIONS", "/:serverId/report", response.CreateOptions("GET"))
 }
 
 // @Summary Get all servers uptime
@@ -274,5 +280,104 @@ func getServerUptime(c *gin.Context) {
 			"until": time.Now(),
 		},
 		"history": history,
+		"monitoring": services.GetGatusServerStatus(server, 20),
 	})
 }
+
+// @Summary Get uptime SLA report
+// @Description Gets the SLA report of every server, or of the servers of one node, for a week or a month.
+// @Description Planned maintenance windows are not counted as uptime nor downtime.
+// @Success 200 {object} models.UptimeSummary
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param nodeId query uint false "Only servers of this node, 0 is the local node"
+// @Param period query string false "week or month (default: month)"
+// @Param date query string false "Any day of the period, as YYYY-MM-DD (default: today)"
+// @Param format query string false "json or csv (default: json)"
+// @Router /api/uptime/report [get]
+// @Security OAuth2Application[uptime.view]
+func getUptimeSummary(c *gin.Context) {
+	db := middleware.GetDatabase(c)
+	us := &services.Uptime{DB: db}
+
+	period, from, to, err := getReportPeriod(c)
+	if response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	var nodeId *uint
+	if v := c.Query("nodeId"); v != "" {
+		id, err := cast.ToUintE(v)
+		if response.HandleError(c, err, http.StatusBadRequest) {
+			return
+		}
+		nodeId = &id
+	}
+
+	summary, err := us.GetSummary(nodeId, period, from, to)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeUptimeReport(c, fmt.Sprintf("uptime-%s.csv", from.Format(time.DateOnly)), summary.Servers, summary)
+}
+
+// @Summary Get server uptime SLA report
+// @Description Gets the SLA report of a server for a week or a month, with every downtime incident and its cause
+// @Success 200 {object} models.UptimeReport
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 403 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param period query string false "week or month (default: month)"
+// @Param date query string false "Any day of the period, as YYYY-MM-DD (default: today)"
+// @Param format query string false "json or csv (default: json)"
+// @Router /api/uptime/{id}/report [get]
+// @Security OAuth2Application[server.view]
+func getServerUptimeReport(c *gin.Context) {
+	server := getServerFromGin(c)
+	db := middleware.GetDatabase(c)
+	us := &services.Uptime{DB: db}
+
+	_, from, to, err := getReportPeriod(c)
+	if response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	report, err := us.GetReport(server, from, to)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	writeUptimeReport(c, fmt.Sprintf("uptime-%s-%s.csv", server.Identifier, from.Format(time.DateOnly)), []*models.UptimeReport{report}, report)
+}
+
+// getReportPeriod lee el periodo del informe de la petición, por defecto el mes actual
+func getReportPeriod(c *gin.Context) (period string, from, to time.Time, err error) {
+	period = c.DefaultQuery("period", services.ReportPeriodMonth)
+	date := time.Now()
+	if v := c.Query("date"); v != "" {
+		if date, err = time.ParseInLocation(time.DateOnly, v, time.Local); err != nil {
+			return
+		}
+	}
+	from, to, err = services.ReportPeriod(period, date)
+	return
+}
+
+// writeUptimeReport responde con el informe en JSON o, con format=csv, como un fichero CSV
+func writeUptimeReport(c *gin.Context, fileName string, reports []*models.UptimeReport, result interface{}) {
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Status(http.StatusOK)
+	if err := services.WriteUptimeCsv(c.Writer, reports); err != nil {
+		_ = c.Error(err)
+	}
+}
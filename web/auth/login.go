@@ -4,11 +4,15 @@ import (
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
 	"github.com/SkyPanel/SkyPanel/v3/middleware"
 	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/notifications"
 	"github.com/SkyPanel/SkyPanel/v3/response"
 	"github.com/SkyPanel/SkyPanel/v3/scopes"
 	"github.com/SkyPanel/SkyPanel/v3/services"
+	"gorm.io/gorm"
+	"fmt"
 	"net/http"
 	"time"
 )
@@ -101,6 +105,8 @@ func createSession(c *gin.Context, user *models.User) {
 		return
 	}
 
+	notifyIfNewLoginIp(db, user, c.ClientIP())
+
 	data := &LoginResponse{}
 	data.Scopes = perms.Scopes
 
@@ -117,6 +123,27 @@ func createSession(c *gin.Context, user *models.User) {
 	c.JSON(http.StatusOK, data)
 }
 
+func notifyIfNewLoginIp(db *gorm.DB, user *models.User, ip string) {
+	us := &services.User{DB: db}
+	isNew, err := us.RememberLoginIp(user.ID, ip)
+	if err != nil {
+		logging.Error.Printf("Error saving login address of user %d: %s", user.ID, err)
+		return
+	}
+	if !isNew {
+		return
+	}
+
+	services.Notify(&notifications.Notification{
+		Event:    notifications.EventLoginNewIp,
+		Severity: notifications.SeverityWarning,
+		Title:    "🔐 Inicio de sesión desde una nueva IP",
+		Message:  fmt.Sprintf("Se inició sesión en la cuenta %s desde %s.", user.Username, ip),
+		Fields:   []notifications.Field{{Name: "IP", Value: ip}},
+		UserId:   user.ID,
+	})
+}
+
 type LoginRequestData struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -0,0 +1,172 @@
+package daemon
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/addons"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/response"
+	"github.com/gin-gonic/gin"
+)
+
+type AddonUpdateResult struct {
+	addons.Addon
+	Updated bool `json:"updated"`
+} //@name AddonUpdateResult
+
+// @Summary Get addons
+// @Description Gets the jars in the plugins and mods folders, with the metadata inside them and where they were downloaded from
+// @Success 200 {array} addons.Addon
+// @Param id path string true "Server ID"
+// @Router /daemon/server/{id}/addons [get]
+func getAddons(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	result, err := addons.List(server.GetFileServer(), server.Id())
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Check addon updates
+// @Description Looks for newer versions of the addons on Spigot, Modrinth or CurseForge, jars from an unknown source are looked up on Modrinth by hash
+// @Success 200 {array} addons.AvailableUpdate
+// @Param id path string true "Server ID"
+// @Param loader query string false "Loader of the server, like fabric or paper (default: the one in each jar)"
+// @Param gameVersion query string false "Only versions for this Minecraft version"
+// @Router /daemon/server/{id}/addons/updates [get]
+func checkAddonUpdates(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	updates, err := addons.CheckUpdates(server.GetFileServer(), server.Id(), c.Query("loader"), c.Query("gameVersion"))
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	c.JSON(http.StatusOK, updates)
+}
+
+// @Summary Update addon
+// @Description Replaces an addon with the newest version from its source, keeping the old jar so the update can be rolled back
+// @Success 200 {object} AddonUpdateResult
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param file query string true "Path of the jar, like plugins/example.jar"
+// @Param loader query string false "Loader of the server, like fabric or paper (default: the one in the jar)"
+// @Param gameVersion query string false "Only versions for this Minecraft version"
+// @Router /daemon/server/{id}/addons/update [post]
+func updateAddon(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	file := c.Query("file")
+	updated, changed, err := addons.Update(server.GetFileServer(), server.Id(), file, c.Query("loader"), c.Query("gameVersion"))
+	if response.HandleError(c, err, addonErrorStatus(err)) {
+		return
+	}
+
+	if env := server.GetEnvironment(); env != nil && changed {
+		env.DisplayToConsole(true, "%s updated to %s\n", file, updated.File)
+	}
+	c.JSON(http.StatusOK, AddonUpdateResult{Addon: updated, Updated: changed})
+}
+
+// @Summary Roll back addon
+// @Description Puts back the jar that the last update of an addon replaced
+// @Success 200 {object} addons.Addon
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param file query string true "Path of the jar, like plugins/example.jar"
+// @Router /daemon/server/{id}/addons/rollback [post]
+func rollbackAddon(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	file := c.Query("file")
+	restored, err := addons.Rollback(server.GetFileServer(), server.Id(), file)
+	if response.HandleError(c, err, addonErrorStatus(err)) {
+		return
+	}
+
+	if env := server.GetEnvironment(); env != nil {
+		env.DisplayToConsole(true, "%s rolled back to %s\n", file, restored.File)
+	}
+	c.JSON(http.StatusOK, restored)
+}
+
+// @Summary Set addon source
+// @Description Records where a jar came from, so it can be updated
+// @Success 200 {object} addons.Addon
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param file query string true "Path of the jar, like mods/example.jar"
+// @Param source body addons.Source true "Source, only type, projectId and versionId are used"
+// @Router /daemon/server/{id}/addons/source [put]
+func setAddonSource(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	var source addons.Source
+	if err := c.BindJSON(&source); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	switch source.Type {
+	case addons.SourceSpiget, addons.SourceModrinth, addons.SourceCurseForge:
+	default:
+		response.HandleError(c, errors.New("type must be spiget, modrinth or curseforge"), http.StatusBadRequest)
+		return
+	}
+	if source.ProjectId == "" {
+		response.HandleError(c, errors.New("projectId is required"), http.StatusBadRequest)
+		return
+	}
+
+	addon, err := addons.Track(server.GetFileServer(), server.Id(), c.Query("file"), addons.Source{
+		Type:      source.Type,
+		ProjectId: source.ProjectId,
+		VersionId: source.VersionId,
+		Version:   source.Version,
+	})
+	if response.HandleError(c, err, addonErrorStatus(err)) {
+		return
+	}
+	c.JSON(http.StatusOK, addon)
+}
+
+// @Summary Remove addon source
+// @Description Forgets where a jar came from, along with its rollback
+// @Success 204 {object} nil
+// @Param id path string true "Server ID"
+// @Param file query string true "Path of the jar, like mods/example.jar"
+// @Router /daemon/server/{id}/addons/source [delete]
+func removeAddonSource(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	err := addons.RemoveSource(server.Id(), c.Query("file"))
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// trackAddon records the source of a jar that was just installed, a failure only means it cannot be updated later
+func trackAddon(serverId, file string, source addons.Source) {
+	if err := addons.SetSource(serverId, file, source); err != nil {
+		logging.Error.Printf("Error recording source of %s: %s", file, err)
+	}
+}
+
+func addonErrorStatus(err error) int {
+	switch SkyPanel.FromError(err).GetCode() {
+	case "ErrUnsafePath", "ErrAddonNotTracked", "ErrAddonNoRollback":
+		return http.StatusBadRequest
+	case "ErrFileNotFound", "ErrModrinthNoVersion", "ErrCurseForgeFile":
+		return http.StatusNotFound
+	case "ErrHashMismatch", "ErrInvalidAddon", "ErrModrinthStatus", "ErrCurseForgeStatus", "ErrSpigetStatus", "ErrSpigetExternal", "ErrCurseForgeDistribution":
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,91 @@
+package daemon
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/configfile"
+	"github.com/SkyPanel/SkyPanel/v3/response"
+	"github.com/gin-gonic/gin"
+)
+
+type ConfigFile struct {
+	Format string                 `json:"format"`
+	Values map[string]interface{} `json:"values"`
+} //@name ConfigFile
+
+// @Summary Get config values
+// @Description Parses a properties, YAML, JSON, TOML or INI file and returns its values, a file which does not exist has no values
+// @Success 200 {object} ConfigFile
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param filepath path string true "File path"
+// @Param format query string false "Format of the file (default: worked out from the extension)"
+// @Router /api/servers/{id}/config/{filepath} [get]
+// @Security OAuth2Application[server.files.view]
+func getConfig(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	file := strings.TrimPrefix(c.Param("filename"), "/")
+	doc, format, err := configfile.Read(server.GetFileServer(), file, c.Query("format"))
+	if response.HandleError(c, err, configErrorStatus(err)) {
+		return
+	}
+
+	values, err := doc.Values()
+	if response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	c.JSON(http.StatusOK, ConfigFile{Format: format, Values: values})
+}
+
+// @Summary Edit config values
+// @Description Sets, merges and deletes keys in a config file, keeping its comments and order where the format allows
+// @Description Keys are dot separated paths, a dot which is part of a key is written as \.
+// @Success 200 {object} ConfigFile
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param filepath path string true "File path"
+// @Param format query string false "Format of the file (default: worked out from the extension)"
+// @Param edit body configfile.Edit true "Changes to make"
+// @Router /api/servers/{id}/config/{filepath} [patch]
+// @Security OAuth2Application[server.files.edit]
+func editConfig(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	var edit configfile.Edit
+	if err := c.BindJSON(&edit); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+
+	fs := server.GetFileServer()
+	file := strings.TrimPrefix(c.Param("filename"), "/")
+	doc, format, err := configfile.Read(fs, file, c.Query("format"))
+	if response.HandleError(c, err, configErrorStatus(err)) {
+		return
+	}
+	if err = edit.Apply(doc); response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	if err = configfile.Write(fs, file, doc); response.HandleError(c, err, configErrorStatus(err)) {
+		return
+	}
+
+	values, err := doc.Values()
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	c.JSON(http.StatusOK, ConfigFile{Format: format, Values: values})
+}
+
+// configErrorStatus tells problems with the file contents apart from problems reading or writing it
+func configErrorStatus(err error) int {
+	var pathErr *os.PathError
+	if SkyPanel.FromError(err).GetCode() == "ErrUnknownConfigFormat" || !errors.As(err, &pathErr) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,160 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/gitdeploy"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/response"
+	"github.com/SkyPanel/SkyPanel/v3/servers"
+	"github.com/gin-gonic/gin"
+)
+
+// largest webhook body read, push events with many commits are well under this
+const maxWebhookSize = 1024 * 1024
+
+type DeployKey struct {
+	PublicKey string `json:"publicKey"`
+} //@name DeployKey
+
+// @Summary Deploy from git
+// @Description Pulls the git repo set in the deploy section of the server into its folder
+// @Success 200 {object} gitdeploy.Result
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param restart query bool false "Restart the server afterwards if it is running and files changed"
+// @Router /api/servers/{id}/deploy [post]
+// @Security OAuth2Application[server.files.edit]
+func deployServer(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	result, err := server.RunDeploy(c.Request.Context())
+	if SkyPanel.FromError(err).GetCode() == "ErrDeployNotConfigured" {
+		response.HandleError(c, err, http.StatusBadRequest)
+		return
+	}
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	if _, restart := c.GetQuery("restart"); restart {
+		restartAfterDeploy(server, result)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// @Summary Deploy webhook
+// @Description Receives push events from GitHub, GitLab, Gitea or Forgejo and deploys the server in the background
+// @Description The webhook has to be signed with the secret set in the deploy section of the server, it is not authenticated otherwise
+// @Success 202 {object} nil
+// @Success 204 {object} nil "Event is not a push to the deployed branch"
+// @Failure 401 {object} SkyPanel.ErrorResponse
+// @Failure 404 {object} nil
+// @Param id path string true "Server ID"
+// @Router /daemon/server/{id}/deploy/webhook [post]
+func deployWebhook(c *gin.Context) {
+	server := getServerFromGin(c)
+	deploy := server.Server.Deploy
+	if deploy == nil || deploy.Repo == "" || deploy.Secret == "" {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookSize))
+	if response.HandleError(c, err, http.StatusBadRequest) {
+		return
+	}
+	if !gitdeploy.VerifyWebhook(deploy.Secret, c.Request.Header, body) {
+		response.HandleError(c, SkyPanel.ErrInvalidWebhookSignature, http.StatusUnauthorized)
+		return
+	}
+	if !gitdeploy.ShouldDeploy(*deploy, c.Request.Header, body) {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	go func() {
+		result, err := server.RunDeploy(context.Background())
+		if err != nil {
+			return
+		}
+		if deploy.Restart {
+			restartAfterDeploy(server, result)
+		}
+	}()
+	c.Status(http.StatusAccepted)
+}
+
+// restartAfterDeploy restarts a running server when the deploy changed something
+func restartAfterDeploy(server *servers.Server, result gitdeploy.Result) {
+	if len(result.Changed) == 0 && len(result.Removed) == 0 {
+		return
+	}
+	if running, _ := server.IsRunning(); !running {
+		return
+	}
+	go func() {
+		if err := doRestart(server); err != nil {
+			logging.Error.Printf("Error restarting server %s after deploy: %s", server.Id(), err)
+		}
+	}()
+}
+
+// @Summary Get deploy key
+// @Description Gets the public key the server uses to pull git repos over ssh, add it to the repo as a read-only deploy key
+// @Success 200 {object} DeployKey
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Router /api/servers/{id}/deploy/key [get]
+// @Security OAuth2Application[server.definition.view]
+func getDeployKey(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	key, err := gitdeploy.PublicKey(server.Id())
+	if SkyPanel.FromError(err).GetCode() == "ErrDeployKeyMissing" {
+		response.HandleError(c, err, http.StatusNotFound)
+		return
+	}
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	c.JSON(http.StatusOK, DeployKey{PublicKey: key})
+}
+
+// @Summary Generate deploy key
+// @Description Creates a new deploy key for the server, replacing the old one
+// @Success 200 {object} DeployKey
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Router /api/servers/{id}/deploy/key [post]
+// @Security OAuth2Application[server.definition.edit]
+func generateDeployKey(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	key, err := gitdeploy.GenerateKey(server.Id())
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	c.JSON(http.StatusOK, DeployKey{PublicKey: key})
+}
+
+// @Summary Delete deploy key
+// @Description Removes the deploy key of the server
+// @Success 204 {object} nil
+// @Failure 500 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Router /api/servers/{id}/deploy/key [delete]
+// @Security OAuth2Application[server.definition.edit]
+func deleteDeployKey(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	err := gitdeploy.DeleteKey(server.Id())
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
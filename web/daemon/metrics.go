@@ -0,0 +1,36 @@
+package daemon
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/SkyPanel/SkyPanel/v3/config"
+	"github.com/SkyPanel/SkyPanel/v3/metrics"
+	"github.com/SkyPanel/SkyPanel/v3/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// validateMetricsToken lets scrapers in with the static token from the config, anything else needs a token from the panel
+func validateMetricsToken(c *gin.Context) {
+	if !config.MetricsEnabled.Value() {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	token := config.MetricsToken.Value()
+	if token != "" && subtle.ConstantTimeCompare([]byte(middleware.GetToken(c)), []byte(token)) == 1 {
+		return
+	}
+	middleware.ValidateJWT(c)
+}
+
+// @Summary Get metrics
+// @Description Gets the metrics of the node and its servers in the Prometheus format. Accepts the static token set in daemon.metrics.token.
+// @Success 200 {string} string
+// @Failure 401 {object} nil
+// @Failure 404 {object} nil
+// @Router /daemon/metrics [get]
+// @Security OAuth2Application[none]
+func getMetrics(c *gin.Context) {
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
+}
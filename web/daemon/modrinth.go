@@ -0,0 +1,192 @@
+package daemon
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/addons"
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/operations/modrinth"
+	"github.com/SkyPanel/SkyPanel/v3/response"
+	"github.com/gin-gonic/gin"
+)
+
+type ModrinthSearchResult struct {
+	ProjectId     string   `json:"projectId"`
+	Slug          string   `json:"slug"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	Author        string   `json:"author"`
+	IconURL       string   `json:"iconUrl,omitempty"`
+	Downloads     int      `json:"downloads"`
+	ProjectType   string   `json:"projectType"`
+	Categories    []string `json:"categories"`
+	LatestVersion string   `json:"latestVersion,omitempty"`
+} //@name ModrinthSearchResult
+
+type ModrinthUpdateResult struct {
+	modrinth.InstalledFile
+	Updated bool `json:"updated"`
+} //@name ModrinthUpdateResult
+
+// @Summary Get installed Modrinth projects
+// @Description Gets the jars in the mods and plugins folders that come from Modrinth
+// @Success 200 {array} modrinth.InstalledFile
+// @Param id path string true "Server ID"
+// @Router /daemon/server/{id}/modrinth [get]
+func getModrinthProjects(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	installed, err := modrinth.ListInstalled(server.GetFileServer())
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+	c.JSON(http.StatusOK, installed)
+}
+
+// @Summary Search Modrinth
+// @Description Searches for mods or plugins on Modrinth
+// @Success 200 {array} ModrinthSearchResult
+// @Param id path string true "Server ID"
+// @Param q query string true "Search query"
+// @Param type query string false "mod or plugin (default: mod)"
+// @Param loader query string false "Only projects for this loader, like fabric or paper"
+// @Param gameVersion query string false "Only projects for this Minecraft version"
+// @Router /daemon/server/{id}/modrinth/search [get]
+func searchModrinth(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		response.HandleError(c, errors.New("search query is required"), http.StatusBadRequest)
+		return
+	}
+
+	projectType := c.DefaultQuery("type", "mod")
+	if projectType != "mod" && projectType != "plugin" {
+		response.HandleError(c, errors.New("type must be mod or plugin"), http.StatusBadRequest)
+		return
+	}
+
+	found, err := modrinth.Search(query, projectType, c.Query("loader"), c.Query("gameVersion"), 20)
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+		return
+	}
+
+	results := make([]ModrinthSearchResult, 0, len(found.Hits))
+	for _, v := range found.Hits {
+		results = append(results, ModrinthSearchResult{
+			ProjectId:     v.ProjectId,
+			Slug:          v.Slug,
+			Name:          v.Title,
+			Description:   v.Description,
+			Author:        v.Author,
+			IconURL:       v.IconUrl,
+			Downloads:     v.Downloads,
+			ProjectType:   v.ProjectType,
+			Categories:    v.Categories,
+			LatestVersion: v.LatestVersion,
+		})
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// @Summary Install Modrinth project
+// @Description Downloads a mod or plugin from Modrinth, checking its hashes, into the mods or plugins folder
+// @Success 200 {object} modrinth.InstalledFile
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param projectId path string true "Modrinth project ID or slug"
+// @Param versionId query string false "Version to install (default: latest for the loader and game version)"
+// @Param loader query string false "Loader of the server, like fabric or paper"
+// @Param gameVersion query string false "Minecraft version of the server"
+// @Router /daemon/server/{id}/modrinth/{projectId} [post]
+func installModrinthProject(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	installed, err := modrinth.Install(server.GetFileServer(), c.Param("projectId"), c.Query("versionId"), c.Query("loader"), c.Query("gameVersion"))
+	if response.HandleError(c, err, modrinthErrorStatus(err)) {
+		return
+	}
+	trackAddon(server.Id(), installed.File, modrinthSource(installed))
+
+	if env := server.GetEnvironment(); env != nil {
+		env.DisplayToConsole(true, "%s installed successfully\n", installed.File)
+	}
+	c.JSON(http.StatusOK, installed)
+}
+
+// @Summary Update Modrinth project
+// @Description Replaces the installed file of a project with its latest version for the loader and game version
+// @Success 200 {object} ModrinthUpdateResult
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param projectId path string true "Modrinth project ID or slug"
+// @Param loader query string false "Loader of the server, like fabric or paper"
+// @Param gameVersion query string false "Minecraft version of the server"
+// @Router /daemon/server/{id}/modrinth/{projectId} [put]
+func updateModrinthProject(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	installed, updated, err := modrinth.Update(server.GetFileServer(), c.Param("projectId"), c.Query("loader"), c.Query("gameVersion"))
+	if response.HandleError(c, err, modrinthErrorStatus(err)) {
+		return
+	}
+
+	if updated {
+		trackAddon(server.Id(), installed.File, modrinthSource(installed))
+	}
+
+	if env := server.GetEnvironment(); env != nil && updated {
+		env.DisplayToConsole(true, "%s updated to %s\n", installed.File, installed.VersionNumber)
+	}
+	c.JSON(http.StatusOK, ModrinthUpdateResult{InstalledFile: installed, Updated: updated})
+}
+
+// @Summary Remove Modrinth project
+// @Description Deletes the installed files of a project
+// @Success 204 {object} nil
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param projectId path string true "Modrinth project ID or slug"
+// @Router /daemon/server/{id}/modrinth/{projectId} [delete]
+func removeModrinthProject(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	removed, err := modrinth.Remove(server.GetFileServer(), c.Param("projectId"))
+	if response.HandleError(c, err, modrinthErrorStatus(err)) {
+		return
+	}
+
+	for _, v := range removed {
+		if err = addons.RemoveSource(server.Id(), v.File); err != nil {
+			logging.Error.Printf("Error forgetting source of %s: %s", v.File, err)
+		}
+	}
+
+	if env := server.GetEnvironment(); env != nil {
+		for _, v := range removed {
+			env.DisplayToConsole(true, "%s deleted successfully\n", v.File)
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func modrinthSource(installed modrinth.InstalledFile) addons.Source {
+	return addons.Source{
+		Type:      addons.SourceModrinth,
+		ProjectId: installed.ProjectId,
+		VersionId: installed.VersionId,
+		Version:   installed.VersionNumber,
+	}
+}
+
+func modrinthErrorStatus(err error) int {
+	switch SkyPanel.FromError(err).GetCode() {
+	case "ErrModrinthNotInstalled", "ErrModrinthNoVersion":
+		return http.StatusNotFound
+	case "ErrHashMismatch", "ErrModrinthStatus":
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
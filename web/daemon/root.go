@@ -31,6 +31,9 @@ func RegisterDaemonRoutes(e *gin.RouterGroup) {
 	e.GET("system", getSystemInfo)
 	e.Handle("OPTIONS", "system", response.CreateOptions("GET"))
 
+	e.GET("metrics", validateMetricsToken, getMetrics)
+	e.Handle("OPTIONS", "metrics", response.CreateOptions("GET"))
+
 	RegisterServerRoutes(e)
 }
 
@@ -9,10 +9,16 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/SkyPanel/SkyPanel/v3"
+	"github.com/SkyPanel/SkyPanel/v3/addons"
+	"github.com/SkyPanel/SkyPanel/v3/files"
+	"github.com/SkyPanel/SkyPanel/v3/history"
 	"github.com/SkyPanel/SkyPanel/v3/logging"
 	"github.com/SkyPanel/SkyPanel/v3/middleware"
 	"github.com/SkyPanel/SkyPanel/v3/query"
@@ -80,12 +86,27 @@ func RegisterServerRoutes(e *gin.RouterGroup) {
 		l.POST("/:serverId/install", middleware.ResolveServerNode, installServer)
 		l.OPTIONS("/:serverId/install", response.CreateOptions("POST"))
 
+		l.POST("/:serverId/install/cancel", middleware.ResolveServerNode, cancelInstall)
+		l.OPTIONS("/:serverId/install/cancel", response.CreateOptions("POST"))
+
 		l.GET("/:serverId/file/*filename", middleware.ResolveServerNode, getFile)
 		l.PUT("/:serverId/file/*filename", middleware.ResolveServerNode, putFile)
 		l.DELETE("/:serverId/file/*filename", middleware.ResolveServerNode, deleteFile)
 		l.POST("/:serverId/file/*filename", middleware.ResolveServerNode, response.NotImplemented)
 		l.OPTIONS("/:serverId/file/*filename", response.CreateOptions("GET", "PUT", "DELETE", "POST"))
 
+		l.GET("/:serverId/config/*filename", middleware.ResolveServerNode, getConfig)
+		l.PATCH("/:serverId/config/*filename", middleware.ResolveServerNode, editConfig)
+		l.OPTIONS("/:serverId/config/*filename", response.CreateOptions("GET", "PATCH"))
+
+		l.POST("/:serverId/deploy", middleware.ResolveServerNode, deployServer)
+		l.OPTIONS("/:serverId/deploy", response.CreateOptions("POST"))
+
+		l.GET("/:serverId/deploy/key", middleware.ResolveServerNode, getDeployKey)
+		l.POST("/:serverId/deploy/key", middleware.ResolveServerNode, generateDeployKey)
+		l.DELETE("/:serverId/deploy/key", middleware.ResolveServerNode, deleteDeployKey)
+		l.OPTIONS("/:serverId/deploy/key", response.CreateOptions("GET", "POST", "DELETE"))
+
 		l.GET("/:serverId/console", middleware.ResolveServerNode, getLogs)
 		l.POST("/:serverId/console", middleware.ResolveServerNode, postConsole)
 		l.OPTIONS("/:serverId/console", response.CreateOptions("GET", "POST"))
@@ -97,6 +118,22 @@ func RegisterServerRoutes(e *gin.RouterGroup) {
 		l.GET("/:serverId/stats", middleware.ResolveServerNode, getStats)
 		l.OPTIONS("/:serverId/stats", response.CreateOptions("GET"))
 
+		l.GET("/:serverId/stats/history", middleware.ResolveServerNode, getStatsHistory)
+		l.OPTIONS("/:serverId/stats/history", response.CreateOptions("GET"))
+
+		l.GET("/:serverId/crashes", middleware.ResolveServerNode, getCrashReports)
+		l.OPTIONS("/:serverId/crashes", response.CreateOptions("GET"))
+
+		l.POST("/:serverId/crashes/reset", middleware.ResolveServerNode, resetCrashes)
+		l.OPTIONS("/:serverId/crashes/reset", response.CreateOptions("POST"))
+
+		l.GET("/:serverId/crashes/:crashId", middleware.ResolveServerNode, getCrashReport)
+		l.DELETE("/:serverId/crashes/:crashId", middleware.ResolveServerNode, deleteCrashReport)
+		l.OPTIONS("/:serverId/crashes/:crashId", response.CreateOptions("GET", "DELETE"))
+
+		l.GET("/:serverId/crashes/:crashId/files/:name", middleware.ResolveServerNode, downloadCrashReportFile)
+		l.OPTIONS("/:serverId/crashes/:crashId/files/:name", response.CreateOptions("GET"))
+
 		l.GET("/:serverId/status", middleware.ResolveServerNode, getStatus)
 		l.OPTIONS("/:serverId/status", response.CreateOptions("GET"))
 
@@ -119,6 +156,27 @@ func RegisterServerRoutes(e *gin.RouterGroup) {
 		l.POST("/:serverId/plugins/:pluginId", middleware.ResolveServerNode, installPlugin)
 		l.OPTIONS("/:serverId/plugins/:pluginId", response.CreateOptions("POST"))
 
+		l.GET("/:serverId/addons", middleware.ResolveServerNode, getAddons)
+		l.OPTIONS("/:serverId/addons", response.CreateOptions("GET"))
+		l.GET("/:serverId/addons/updates", middleware.ResolveServerNode, checkAddonUpdates)
+		l.OPTIONS("/:serverId/addons/updates", response.CreateOptions("GET"))
+		l.POST("/:serverId/addons/update", middleware.ResolveServerNode, updateAddon)
+		l.OPTIONS("/:serverId/addons/update", response.CreateOptions("POST"))
+		l.POST("/:serverId/addons/rollback", middleware.ResolveServerNode, rollbackAddon)
+		l.OPTIONS("/:serverId/addons/rollback", response.CreateOptions("POST"))
+		l.PUT("/:serverId/addons/source", middleware.ResolveServerNode, setAddonSource)
+		l.DELETE("/:serverId/addons/source", middleware.ResolveServerNode, removeAddonSource)
+		l.OPTIONS("/:serverId/addons/source", response.CreateOptions("PUT", "DELETE"))
+
+		l.GET("/:serverId/modrinth", middleware.ResolveServerNode, getModrinthProjects)
+		l.OPTIONS("/:serverId/modrinth", response.CreateOptions("GET"))
+		l.GET("/:serverId/modrinth/search", middleware.ResolveServerNode, searchModrinth)
+		l.OPTIONS("/:serverId/modrinth/search", response.CreateOptions("GET"))
+		l.POST("/:serverId/modrinth/:projectId", middleware.ResolveServerNode, installModrinthProject)
+		l.PUT("/:serverId/modrinth/:projectId", middleware.ResolveServerNode, updateModrinthProject)
+		l.DELETE("/:serverId/modrinth/:projectId", middleware.ResolveServerNode, removeModrinthProject)
+		l.OPTIONS("/:serverId/modrinth/:projectId", response.CreateOptions("POST", "PUT", "DELETE"))
+
 		p := l.Group("/:serverId/socket")
 		{
 			p.GET("", middleware.ResolveServerNode, cors.New(cors.Config{
@@ -132,6 +190,10 @@ func RegisterServerRoutes(e *gin.RouterGroup) {
 			p.OPTIONS("", response.CreateOptions("GET", "CONNECT"))
 		}
 	}
+
+	//git hosts cannot send a token, the webhook checks the signature made with the secret of the server instead
+	e.POST("/server/:serverId/deploy/webhook", middleware.ResolveServerNode, deployWebhook)
+	e.OPTIONS("/server/:serverId/deploy/webhook", response.CreateOptions("POST"))
 }
 
 func getServerFromGin(c *gin.Context) *servers.Server {
@@ -314,15 +376,39 @@ func deleteServer(c *gin.Context) {
 }
 
 // @Summary Install server
-// @Description Install server
+// @Description Install server, with dryRun the resolved install steps are returned instead of being run
+// @Success 200 {object} []servers.PlannedOperation
 // @Success 202 {object} nil
 // @Success 204 {object} nil
 // @Param id path string true "Server ID"
+// @Failure 400 {object} SkyPanel.ErrorResponse
+// @Param dryRun query bool false "Only resolve the install steps"
 // @Router /api/servers/{id}/install [post]
 // @Security OAuth2Application[server.install]
 func installServer(c *gin.Context) {
 	server := getServerFromGin(c)
 
+	//a bare ?dryRun counts as true, anything else has to be a bool so a typo does not install
+	dryRun := false
+	if value, exists := c.GetQuery("dryRun"); exists {
+		dryRun = true
+		if value != "" {
+			var err error
+			if dryRun, err = strconv.ParseBool(value); response.HandleError(c, err, http.StatusBadRequest) {
+				return
+			}
+		}
+	}
+
+	if dryRun {
+		plan, err := server.PlanInstall()
+		if response.HandleError(c, err, http.StatusInternalServerError) {
+		} else {
+			c.JSON(http.StatusOK, plan)
+		}
+		return
+	}
+
 	_, wait := c.GetQuery("wait")
 
 	if wait {
@@ -340,6 +426,23 @@ func installServer(c *gin.Context) {
 	}
 }
 
+// @Summary Cancel install
+// @Description Stops the running install, the operation being run is killed if it does not stop by itself
+// @Success 204 {object} nil
+// @Failure 409 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Router /api/servers/{id}/install/cancel [post]
+// @Security OAuth2Application[server.install]
+func cancelInstall(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	err := server.CancelInstall()
+	if response.HandleError(c, err, http.StatusConflict) {
+	} else {
+		c.Status(http.StatusNoContent)
+	}
+}
+
 // Not documented in swagger as overridden on frontend
 func editServerData(c *gin.Context) {
 	server := getServerFromGin(c)
@@ -695,13 +798,35 @@ func putFile(c *gin.Context) {
 		sourceFile = c.Request.Body
 	}
 
+	quota := server.GetDiskQuota()
+
+	//the old contents are replaced, so they no longer count towards the quota
+	var existingSize int64
+	if fi, err := server.GetFileServer().Stat(targetPath); err == nil && !fi.IsDir() {
+		existingSize = fi.Size()
+	}
+	if c.Request.ContentLength > 0 && quota.Check(c.Request.ContentLength-existingSize) != nil {
+		response.HandleError(c, SkyPanel.ErrDiskQuotaExceeded, http.StatusInsufficientStorage)
+		return
+	}
+
 	file, err := server.GetFileServer().OpenFile(targetPath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0644)
 	defer utils.Close(file)
 	if response.HandleError(c, err, http.StatusInternalServerError) {
 		return
 	}
 
-	_, err = io.Copy(file, sourceFile)
+	var target io.Writer = file
+	if quota != nil {
+		quota.Add(-existingSize)
+		target = quota.Writer(file)
+	}
+
+	_, err = io.Copy(target, sourceFile)
+	if errors.Is(err, files.ErrQuotaExceeded) {
+		response.HandleError(c, SkyPanel.ErrDiskQuotaExceeded, http.StatusInsufficientStorage)
+		return
+	}
 	if response.HandleError(c, err, http.StatusInternalServerError) {
 		return
 	}
@@ -775,13 +900,152 @@ func postConsole(c *gin.Context) {
 func getStats(c *gin.Context) {
 	server := getServerFromGin(c)
 
-	results, err := server.GetEnvironment().GetStats()
+	results, err := server.GetStats()
 	if response.HandleError(c, err, http.StatusInternalServerError) {
 	} else {
 		c.JSON(http.StatusOK, results)
 	}
 }
 
+// @Summary Get stats history
+// @Description Gets the stats of the server over time, averaged over each step. Samples are kept for 24 hours, per minute for 7 days and per hour for 90 days.
+// @Success 200 {object} history.History
+// @Param id path string true "Server ID"
+// @Param from query int64 false "Epoch time in seconds to start from, defaults to an hour ago"
+// @Param to query int64 false "Epoch time in seconds to end at, defaults to now"
+// @Param step query int64 false "Seconds per point, picked from the range if not given"
+// @Router /api/servers/{id}/stats/history [get]
+// @Security OAuth2Application[server.stats]
+func getStatsHistory(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	var from, to time.Time
+	var step int64
+	for key, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := c.Query(key); value != "" {
+			seconds, err := cast.ToInt64E(value)
+			if err != nil {
+				response.HandleError(c, SkyPanel.ErrInvalidUnixTime, http.StatusBadRequest)
+				return
+			}
+			*target = time.Unix(seconds, 0)
+		}
+	}
+	if value := c.Query("step"); value != "" {
+		var err error
+		step, err = cast.ToInt64E(value)
+		if err != nil || step < 0 {
+			response.HandleError(c, SkyPanel.ErrFieldTooSmall("step", 0), http.StatusBadRequest)
+			return
+		}
+	}
+
+	results, err := history.Query(server.Id(), from, to, time.Duration(step)*time.Second)
+	if errors.Is(err, history.ErrNotEnabled) {
+		response.HandleError(c, SkyPanel.ErrServiceNotAvailable, http.StatusServiceUnavailable)
+	} else if response.HandleError(c, err, http.StatusInternalServerError) {
+	} else {
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// @Summary Get crash reports
+// @Description Gets the crash reports of the server, newest first. The console lines are left out, get a single report for them.
+// @Success 200 {object} []servers.CrashReport
+// @Param id path string true "Server ID"
+// @Router /api/servers/{id}/crashes [get]
+// @Security OAuth2Application[server.crashes.view]
+func getCrashReports(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	results, err := server.GetCrashReports()
+	if response.HandleError(c, err, http.StatusInternalServerError) {
+	} else {
+		c.JSON(http.StatusOK, results)
+	}
+}
+
+// @Summary Reset crashes
+// @Description Forgets the recent crashes of the server, so a crash-looping server is restarted automatically again. Reports are kept.
+// @Success 204 {object} nil
+// @Param id path string true "Server ID"
+// @Router /api/servers/{id}/crashes/reset [post]
+// @Security OAuth2Application[server.start]
+func resetCrashes(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	server.ResetCrashes()
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Get crash report
+// @Description Gets a crash report with the exit code, the last console lines, the last stats and the diagnostic files that were kept
+// @Success 200 {object} servers.CrashReport
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param crashId path string true "Crash report ID"
+// @Router /api/servers/{id}/crashes/{crashId} [get]
+// @Security OAuth2Application[server.crashes.view]
+func getCrashReport(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	result, err := server.GetCrashReport(c.Param("crashId"))
+	if errors.Is(err, SkyPanel.ErrCrashReportNotFound) {
+		response.HandleError(c, err, http.StatusNotFound)
+	} else if response.HandleError(c, err, http.StatusInternalServerError) {
+	} else {
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// @Summary Delete crash report
+// @Success 204 {object} nil
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param crashId path string true "Crash report ID"
+// @Router /api/servers/{id}/crashes/{crashId} [delete]
+// @Security OAuth2Application[server.crashes.delete]
+func deleteCrashReport(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	err := server.DeleteCrashReport(c.Param("crashId"))
+	if errors.Is(err, SkyPanel.ErrCrashReportNotFound) {
+		response.HandleError(c, err, http.StatusNotFound)
+	} else if response.HandleError(c, err, http.StatusInternalServerError) {
+	} else {
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// @Summary Download crash report file
+// @Description Downloads a diagnostic file kept with a crash report
+// @Success 200 {file} file
+// @Failure 404 {object} SkyPanel.ErrorResponse
+// @Param id path string true "Server ID"
+// @Param crashId path string true "Crash report ID"
+// @Param name path string true "File name"
+// @Router /api/servers/{id}/crashes/{crashId}/files/{name} [get]
+// @Security OAuth2Application[server.crashes.view]
+func downloadCrashReportFile(c *gin.Context) {
+	server := getServerFromGin(c)
+
+	data, err := server.GetCrashReportFile(c.Param("crashId"), c.Param("name"))
+	defer func() {
+		if data != nil {
+			utils.Close(data.Contents)
+		}
+	}()
+	if errors.Is(err, SkyPanel.ErrCrashReportNotFound) || errors.Is(err, SkyPanel.ErrFileNotFound) {
+		response.HandleError(c, err, http.StatusNotFound)
+	} else if response.HandleError(c, err, http.StatusInternalServerError) {
+	} else {
+		extraHeaders := map[string]string{
+			"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, data.Name),
+		}
+		c.DataFromReader(http.StatusOK, data.ContentLength, "application/octet-stream", data.Contents, extraHeaders)
+	}
+}
+
 // @Summary Get logs
 // @Description Get the console logs for the server
 // @Success 200 {object} SkyPanel.ServerLogs
@@ -820,7 +1084,7 @@ func getStatus(c *gin.Context) {
 	installing := server.GetEnvironment().IsInstalling()
 
 	if installing {
-		c.JSON(http.StatusOK, &SkyPanel.ServerRunning{Installing: installing})
+		c.JSON(http.StatusOK, &SkyPanel.ServerRunning{Installing: installing, Disk: server.GetDiskUsage(), Progress: server.GetEnvironment().GetProgress()})
 		return
 	}
 
@@ -828,7 +1092,13 @@ func getStatus(c *gin.Context) {
 
 	if response.HandleError(c, err, http.StatusInternalServerError) {
 	} else {
-		c.JSON(http.StatusOK, &SkyPanel.ServerRunning{Running: running})
+		c.JSON(http.StatusOK, &SkyPanel.ServerRunning{
+			Running:      running,
+			CrashLooping: server.IsCrashLooping(),
+			NextRestart:  server.NextRestart(),
+			DownCause:    server.DownCause(),
+			Disk:         server.GetDiskUsage(),
+		})
 	}
 }
 
@@ -875,7 +1145,9 @@ func extract(c *gin.Context) {
 	destination := c.Query("destination")
 
 	err := server.Extract(targetPath, destination)
-	if response.HandleError(c, err, http.StatusInternalServerError) {
+	if errors.Is(err, SkyPanel.ErrDiskQuotaExceeded) {
+		response.HandleError(c, err, http.StatusInsufficientStorage)
+	} else if response.HandleError(c, err, http.StatusInternalServerError) {
 	} else {
 		c.Status(http.StatusNoContent)
 	}
@@ -944,7 +1216,10 @@ func restoreBackup(c *gin.Context) {
 
 	err = server.StartRestore(fileName)
 
-	if response.HandleError(c, err, http.StatusInternalServerError) {
+	if errors.Is(err, SkyPanel.ErrDiskQuotaExceeded) {
+		response.HandleError(c, err, http.StatusInsufficientStorage)
+		return
+	} else if response.HandleError(c, err, http.StatusInternalServerError) {
 		return
 	}
 	c.Status(http.StatusAccepted)
@@ -1037,17 +1312,17 @@ func setFlags(c *gin.Context) {
 func canQueryServer(c *gin.Context) {
 	server := getServerFromGin(c)
 
-	switch server.Query.Type {
-	case "minecraft":
+	if query.Supported(server.Query.Type) {
 		c.Status(http.StatusAccepted)
-	default:
+	} else {
 		c.Status(http.StatusNoContent)
 	}
 }
 
 // @Summary Queries the server for game-specific stats
 // @Description Queries the server using the server's protocol to gather information such as players.
-// @Success 200 {object} interface{}
+// @Description The result is keyed by the protocol of the server.
+// @Success 200 {object} map[string]query.Result
 // @Success 204 {object} nil
 // @Param id path string true "Server ID"
 // @Router /api/servers/{id}/query [get]
@@ -1060,29 +1335,8 @@ func queryServer(c *gin.Context) {
 		return
 	}
 
-	result := make(map[string]interface{})
-
-	switch server.Query.Type {
-	case "minecraft":
-		ip := cast.ToString(server.DataToMap()["ip"])
-		if ip == "" {
-			c.Status(http.StatusNoContent)
-			return
-		}
-
-		port := cast.ToInt(server.DataToMap()["port"])
-		if port <= 0 {
-			c.Status(http.StatusNoContent)
-			return
-		}
-
-		res, err := query.Minecraft(ip, port)
-		if err != nil {
-			c.Status(http.StatusNoContent)
-			return
-		}
-		result["minecraft"] = res
-	default:
+	result, err := server.QueryGame()
+	if err != nil {
 		c.Status(http.StatusNoContent)
 		return
 	}
@@ -1129,9 +1383,11 @@ func getFullFilename(c *gin.Context) string {
 // Plugin management functions
 
 type PluginInfo struct {
-	Name    string `json:"name"`
-	Version string `json:"version"`
-	Size    int64  `json:"size"`
+	Name     string           `json:"name"`
+	Version  string           `json:"version"`
+	Size     int64            `json:"size"`
+	Metadata *addons.Metadata `json:"metadata,omitempty"`
+	Source   *addons.Source   `json:"source,omitempty"`
 }
 
 type PluginSearchResult struct {
@@ -1153,51 +1409,29 @@ type PluginSearchResult struct {
 func getPlugins(c *gin.Context) {
 	server := getServerFromGin(c)
 
-	pluginsDir := "plugins"
-	_, err := server.GetFileServer().Stat(pluginsDir)
-	if err != nil {
-		// Si no existe la carpeta plugins, crear una lista vacía
-		c.JSON(http.StatusOK, []PluginInfo{})
-		return
-	}
-
-	entries, err := server.GetFileServer().ReadDir(pluginsDir)
+	installed, err := addons.List(server.GetFileServer(), server.Id())
 	if response.HandleError(c, err, http.StatusInternalServerError) {
 		return
 	}
 
 	plugins := make([]PluginInfo, 0)
-	for _, entry := range entries {
-		if entry.IsDir() {
+	for _, v := range installed {
+		if path.Dir(v.File) != "plugins" {
 			continue
 		}
 
-		name := entry.Name()
-		// Solo archivos .jar
-		if !strings.HasSuffix(strings.ToLower(name), ".jar") {
-			continue
-		}
-
-		fullPath := filepath.Join(pluginsDir, name)
-		info, err := server.GetFileServer().Stat(fullPath)
-		if err != nil {
-			continue
-		}
-
-		// Extraer versión del nombre si es posible
+		// La versión sale del plugin.yml del jar, no del nombre del archivo
 		version := ""
-		nameWithoutExt := strings.TrimSuffix(name, ".jar")
-		if strings.Contains(nameWithoutExt, "-") {
-			parts := strings.Split(nameWithoutExt, "-")
-			if len(parts) > 1 {
-				version = parts[len(parts)-1]
-			}
+		if v.Metadata != nil {
+			version = v.Metadata.Version
 		}
 
 		plugins = append(plugins, PluginInfo{
-			Name:    name, // Devolver el nombre completo del archivo (con .jar) para eliminación precisa
-			Version: version,
-			Size:    info.Size(),
+			Name:     path.Base(v.File), // Devolver el nombre completo del archivo (con .jar) para eliminación precisa
+			Version:  version,
+			Size:     v.Size,
+			Metadata: v.Metadata,
+			Source:   v.Source,
 		})
 	}
 
@@ -1378,9 +1612,7 @@ func installPlugin(c *gin.Context) {
 	}
 
 	// Guardar el plugin
-	safeName := strings.ReplaceAll(pluginInfo.Name, " ", "_")
-	safeName = strings.ReplaceAll(safeName, "/", "_")
-	pluginFileName := fmt.Sprintf("%s-%s.jar", safeName, pluginInfo.Version.Name)
+	pluginFileName := addons.SpigetFileName(pluginInfo.Name, pluginInfo.Version.Name)
 	pluginPath := filepath.Join(pluginsDir, pluginFileName)
 
 	file, err := server.GetFileServer().OpenFile(pluginPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
@@ -1394,6 +1626,14 @@ func installPlugin(c *gin.Context) {
 		return
 	}
 
+	// Guardar de dónde viene para poder buscar actualizaciones
+	trackAddon(server.Id(), path.Join(pluginsDir, pluginFileName), addons.Source{
+		Type:      addons.SourceSpiget,
+		ProjectId: pluginID,
+		VersionId: strconv.Itoa(pluginInfo.Version.ID),
+		Version:   pluginInfo.Version.Name,
+	})
+
 	if env := server.GetEnvironment(); env != nil {
 		env.DisplayToConsole(true, fmt.Sprintf("Plugin %s installed successfully\n", pluginInfo.Name))
 	}
@@ -1444,6 +1684,9 @@ func deletePlugin(c *gin.Context) {
 	}
 
 	logging.Debug.Printf("deletePlugin: successfully removed plugin '%s'", pluginPath)
+	if err = addons.RemoveSource(server.Id(), path.Join("plugins", pluginName)); err != nil {
+		logging.Error.Printf("deletePlugin: error forgetting source of '%s': %v", pluginPath, err)
+	}
 	if env := server.GetEnvironment(); env != nil {
 		env.DisplayToConsole(true, fmt.Sprintf("Plugin %s deleted successfully\n", pluginName))
 	}
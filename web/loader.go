@@ -19,6 +19,7 @@ import (
 	"github.com/SkyPanel/SkyPanel/v3/web/daemon"
 	"github.com/SkyPanel/SkyPanel/v3/web/oauth2"
 	_ "github.com/SkyPanel/SkyPanel/v3/web/swagger"
+	"github.com/SkyPanel/SkyPanel/v3/web/tunnel"
 	_ "github.com/alecthomas/template"
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
@@ -28,7 +29,7 @@ import (
 	_ "github.com/swaggo/swag"
 )
 
-var noHtmlRedirectOn404 = []string{"/api/", "/oauth2/", "/daemon/"}
+var noHtmlRedirectOn404 = []string{"/api/", "/oauth2/", "/daemon/", "/tunnel"}
 var clientFiles fs.ReadFileFS
 
 // RegisterRoutes Registers all routes
@@ -117,6 +118,7 @@ func RegisterRoutes(e *gin.Engine) {
 		api.RegisterRoutes(e.Group("/api"))
 		oauth2.RegisterRoutes(e.Group("/oauth2"))
 		auth.RegisterRoutes(e.Group("/auth"))
+		tunnel.RegisterRoutes(e.Group("/tunnel"))
 
 		// Rutas para hacer proxy a Gatus en el puerto 8081
 		// Siempre intentar hacer proxy primero, si falla, mostrar error
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/notifications"
+	"github.com/SkyPanel/SkyPanel/v3/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInboxApi(t *testing.T) {
+	session, err := createSessionAdmin()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("DefaultPreferences", func(t *testing.T) {
+		response := CallAPI("GET", "/api/self/notifications/preferences", nil, session)
+		if !assert.Equal(t, http.StatusOK, response.Code) {
+			return
+		}
+
+		preferences := models.NotificationPreferences{}
+		err = json.NewDecoder(response.Body).Decode(&preferences)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, []string{services.DeliveryInbox}, preferences[notifications.EventServerCrash])
+	})
+
+	t.Run("UpdatePreferences", func(t *testing.T) {
+		response := CallAPI("PUT", "/api/self/notifications/preferences", map[string][]string{
+			notifications.EventServerCrash:  {services.DeliveryInbox, services.DeliveryEmail},
+			notifications.EventBackupFailed: {},
+		}, session)
+		if !assert.Equal(t, http.StatusNoContent, response.Code) {
+			return
+		}
+
+		response = CallAPI("GET", "/api/self/notifications/preferences", nil, session)
+		preferences := models.NotificationPreferences{}
+		err = json.NewDecoder(response.Body).Decode(&preferences)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, []string{services.DeliveryInbox, services.DeliveryEmail}, preferences[notifications.EventServerCrash])
+		assert.Empty(t, preferences[notifications.EventBackupFailed])
+		assert.Equal(t, []string{services.DeliveryInbox}, preferences[notifications.EventServerOffline])
+	})
+
+	t.Run("InvalidPreferences", func(t *testing.T) {
+		response := CallAPI("PUT", "/api/self/notifications/preferences", map[string][]string{
+			notifications.EventServerOnline: {services.DeliveryInbox},
+		}, session)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+
+		response = CallAPI("PUT", "/api/self/notifications/preferences", map[string][]string{
+			notifications.EventServerCrash: {"pigeon"},
+		}, session)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("EmptyInbox", func(t *testing.T) {
+		response := CallAPI("GET", "/api/self/notifications", nil, session)
+		if !assert.Equal(t, http.StatusOK, response.Code) {
+			return
+		}
+
+		result := &models.UserNotificationSearchResponse{}
+		err = json.NewDecoder(response.Body).Decode(result)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Empty(t, result.Notifications)
+		assert.Equal(t, int64(0), result.Unread)
+	})
+
+	t.Run("MarkMissingRead", func(t *testing.T) {
+		response := CallAPI("POST", "/api/self/notifications/999/read", nil, session)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
@@ -183,6 +183,28 @@ func TestServers(t *testing.T) {
 				}
 			})
 
+			t.Run("SetMonitoring", func(t *testing.T) {
+				response := CallAPI("PUT", "/api/servers/"+ServerId+"/monitoring", models.ServerMonitoring{Enabled: true}, session)
+				if !assert.Equal(t, http.StatusNoContent, response.Code) {
+					return
+				}
+
+				var server *models.Server
+				err := db.Model(&server).Where(&models.Server{Identifier: ServerId}).Find(&server).Error
+				if !assert.NoError(t, err) {
+					return
+				}
+				if !assert.True(t, server.Monitored) {
+					return
+				}
+
+				response = CallAPI("GET", "/api/uptime/"+ServerId, nil, session)
+				if !assert.Equal(t, http.StatusOK, response.Code) {
+					return
+				}
+				assert.Contains(t, response.Body.String(), `"monitoring":{"enabled":true`)
+			})
+
 			t.Run("AdminDataUpdate", func(t *testing.T) {
 				response := CallAPIRaw("PUT", "/api/servers/"+ServerId+"/data", NewVariableChanges, session)
 				if !assert.Equal(t, http.StatusNoContent, response.Code) {
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/spf13/cast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUptimeReportApi(t *testing.T) {
+	session, err := createSessionAdmin()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var window models.MaintenanceWindow
+	t.Run("CreateMaintenance", func(t *testing.T) {
+		start := time.Now().Add(time.Hour).Truncate(time.Second)
+		response := CallAPI("POST", "/api/maintenance", models.MaintenanceWindow{
+			StartTime:   start,
+			EndTime:     start.Add(time.Hour),
+			Description: "upgrade",
+		}, session)
+		if !assert.Equal(t, http.StatusOK, response.Code) {
+			return
+		}
+		err = json.NewDecoder(response.Body).Decode(&window)
+		if assert.NoError(t, err) {
+			assert.NotZero(t, window.ID)
+		}
+	})
+
+	t.Run("InvalidMaintenance", func(t *testing.T) {
+		start := time.Now()
+		response := CallAPI("POST", "/api/maintenance", models.MaintenanceWindow{
+			StartTime: start,
+			EndTime:   start.Add(-time.Hour),
+		}, session)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("ListMaintenance", func(t *testing.T) {
+		response := CallAPI("GET", "/api/maintenance", nil, session)
+		if !assert.Equal(t, http.StatusOK, response.Code) {
+			return
+		}
+		var windows []*models.MaintenanceWindow
+		err = json.NewDecoder(response.Body).Decode(&windows)
+		if assert.NoError(t, err) && assert.Len(t, windows, 1) {
+			assert.Equal(t, "upgrade", windows[0].Description)
+		}
+	})
+
+	t.Run("Report", func(t *testing.T) {
+		response := CallAPI("GET", "/api/uptime/report?period=week", nil, session)
+		if !assert.Equal(t, http.StatusOK, response.Code) {
+			return
+		}
+		summary := &models.UptimeSummary{}
+		err = json.NewDecoder(response.Body).Decode(summary)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "week", summary.Period)
+			assert.Equal(t, time.Monday, summary.From.Weekday())
+		}
+
+		response = CallAPI("GET", "/api/uptime/report?period=year", nil, session)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("ReportCsv", func(t *testing.T) {
+		response := CallAPI("GET", "/api/uptime/report?format=csv", nil, session)
+		if !assert.Equal(t, http.StatusOK, response.Code) {
+			return
+		}
+		assert.Equal(t, "text/csv", response.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(response.Body.String(), "serverId,serverName,node,"))
+	})
+
+	t.Run("DeleteMaintenance", func(t *testing.T) {
+		response := CallAPI("DELETE", "/api/maintenance/"+cast.ToString(window.ID), nil, session)
+		assert.Equal(t, http.StatusNoContent, response.Code)
+
+		response = CallAPI("GET", "/api/maintenance/"+cast.ToString(window.ID), nil, session)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
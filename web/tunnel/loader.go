@@ -0,0 +1,63 @@
+package tunnel
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/SkyPanel/SkyPanel/v3/logging"
+	"github.com/SkyPanel/SkyPanel/v3/middleware"
+	"github.com/SkyPanel/SkyPanel/v3/models"
+	"github.com/SkyPanel/SkyPanel/v3/oauth2"
+	"github.com/SkyPanel/SkyPanel/v3/services"
+	"github.com/SkyPanel/SkyPanel/v3/tunnel"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+var wsupgrader = websocket.Upgrader{
+	ReadBufferSize:  32 * 1024,
+	WriteBufferSize: 32 * 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+func RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", middleware.NeedsDatabase, connect)
+}
+
+// @Summary Open reverse tunnel
+// @Description Used by daemons which cannot be reached by the panel. The daemon authenticates with its node secret and keeps the websocket open, the panel then sends its daemon calls through it.
+// @Success 101 {object} nil
+// @Failure 401 {object} oauth2.ErrorResponse
+// @Failure 403 {object} oauth2.ErrorResponse
+// @Router /tunnel [get]
+func connect(c *gin.Context) {
+	auth := strings.TrimSpace(c.GetHeader("Authorization"))
+	if auth == "" || !strings.HasPrefix(auth, "Bearer ") {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, &oauth2.ErrorResponse{Error: "invalid_client"})
+		return
+	}
+
+	session := &services.Session{DB: middleware.GetDatabase(c)}
+	node, err := session.ValidateNode(strings.TrimPrefix(auth, "Bearer "))
+	if err != nil {
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, &oauth2.ErrorResponse{Error: "invalid_client"})
+		return
+	}
+
+	if node.ConnectionMode != models.NodeConnectionReverse {
+		c.AbortWithStatusJSON(http.StatusForbidden, &oauth2.ErrorResponse{Error: "unauthorized_client", ErrorDescription: "node is not configured for reverse connections"})
+		return
+	}
+
+	conn, err := wsupgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logging.Error.Printf("Error upgrading tunnel for node %d: %s", node.ID, err)
+		return
+	}
+
+	services.RegisterTunnel(node.ID, tunnel.NewSession(conn, true))
+}